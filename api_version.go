@@ -0,0 +1,33 @@
+package testcontainers
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/api/types/versions"
+)
+
+// Docker API versions needed by the features of the library. The version is negotiated with the daemon
+// when the provider is created, so an old daemon silently gets an old API: features depending
+// on a newer API are checked against it, to fail with an explicit error.
+const (
+	// MinimumAPIVersion is the oldest Docker API supported, from Docker 1.12
+	MinimumAPIVersion = "1.24"
+
+	apiVersionPrune      = "1.25" // pruning images, Docker 1.13
+	apiVersionAttachable = "1.25" // attachable networks, Docker 1.13
+	apiVersionHostGW     = "1.41" // the host-gateway special host, Docker 20.10
+)
+
+// APIVersion gets the version of the Docker API negotiated with the daemon
+func (p *DockerProvider) APIVersion() string {
+	return p.client.ClientVersion()
+}
+
+// requireAPIVersion checks that the API negotiated with the daemon is recent enough for the feature
+func (p *DockerProvider) requireAPIVersion(version string, feature string) error {
+	if versions.LessThan(p.APIVersion(), version) {
+		return fmt.Errorf("docker daemon too old: API version is %s, need >= %s for %s", p.APIVersion(), version, feature)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package testcontainers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/client"
+)
+
+func TestFeaturesAreGatedOnAPIVersion(t *testing.T) {
+	c, err := client.NewClientWithOpts(client.WithHost("tcp://127.0.0.1:1"), client.WithVersion("1.24"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &DockerProvider{client: c}
+	ctx := context.Background()
+
+	if err := provider.requireAPIVersion(MinimumAPIVersion, "testcontainers"); err != nil {
+		t.Fatalf("expected the minimum version to be supported: %s", err)
+	}
+
+	err = provider.PruneImages(ctx, sessionID.String())
+	if err == nil || !strings.Contains(err.Error(), "need >= 1.25 for pruning images") {
+		t.Fatalf("expected pruning to require a newer daemon, got: %v", err)
+	}
+
+	_, err = provider.CreateNetwork(ctx, NetworkRequest{Name: "attachable", Attachable: true, SkipReaper: true})
+	if err == nil || !strings.Contains(err.Error(), "need >= 1.25 for attachable networks") {
+		t.Fatalf("expected attachable networks to require a newer daemon, got: %v", err)
+	}
+}
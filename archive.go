@@ -0,0 +1,50 @@
+package testcontainers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LogArchiveDir is the directory where the full logs of the containers which fail to get ready, and of the
+// containers of failed tests cleaned up with RunForTest or CleanupContainer, are written, so that CI keeps
+// them as artifacts. It is read from the TC_LOG_ARCHIVE_DIR env variable, logs are not archived if empty.
+// A request can set its own directory with ContainerRequest.LogArchiveDir.
+var LogArchiveDir = os.Getenv("TC_LOG_ARCHIVE_DIR")
+
+// logArchiveDir gets the directory where the logs of the container are archived, empty if they are not
+func logArchiveDir(c Container) string {
+	if dc, ok := c.(*DockerContainer); ok && dc.logArchiveDir != "" {
+		return dc.logArchiveDir
+	}
+
+	return LogArchiveDir
+}
+
+// archiveLogs writes the full logs of the container to <dir>/<name>.log, creating the directory if needed,
+// and returns the path of the file
+func archiveLogs(ctx context.Context, c Container, dir, name string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, archiveFileName(name)+".log")
+	if err := ioutil.WriteFile(path, []byte(containerOutput(ctx, c)), 0644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// archiveFileName replaces the characters of the name which are not portable in file names, e.g. the
+// slashes of the names of subtests
+func archiveFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>| `, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
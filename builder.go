@@ -0,0 +1,212 @@
+package testcontainers
+
+import (
+	"github.com/docker/docker/api/types/container"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Clone copies the request deeply, so that the copy can be changed without changing the request, e.g. a base
+// definition shared by several tests. Only the wait strategy, the log consumers, the hooks and the function of
+// the retry policy are shared, they are not data of the request.
+func (c *ContainerRequest) Clone() ContainerRequest {
+	clone := *c
+	clone.BuildArgs = cloneBuildArgs(c.BuildArgs)
+	clone.Env = cloneStringMap(c.Env)
+	clone.ExposedPorts = cloneStrings(c.ExposedPorts)
+	clone.Command = cloneStrings(c.Command)
+	clone.Labels = cloneStringMap(c.Labels)
+	clone.BindMounts = cloneStringMap(c.BindMounts)
+	clone.VolumeMounts = cloneStringMap(c.VolumeMounts)
+	clone.CapAdd = cloneStrings(c.CapAdd)
+	clone.Entrypoint = cloneStrings(c.Entrypoint)
+	clone.Networks = cloneStrings(c.Networks)
+	clone.NetworkIPs = cloneStringMap(c.NetworkIPs)
+	if c.Files != nil {
+		clone.Files = append([]ContainerFile{}, c.Files...)
+	}
+	if c.NetworkAliases != nil {
+		clone.NetworkAliases = make(map[string][]string, len(c.NetworkAliases))
+		for k, v := range c.NetworkAliases {
+			clone.NetworkAliases[k] = cloneStrings(v)
+		}
+	}
+	if c.HealthCheck != nil {
+		healthCheck := *c.HealthCheck
+		healthCheck.Test = cloneStrings(c.HealthCheck.Test)
+		clone.HealthCheck = &healthCheck
+	}
+	if c.HostAccessPorts != nil {
+		clone.HostAccessPorts = append([]int{}, c.HostAccessPorts...)
+	}
+	if c.RetryPolicy != nil {
+		policy := *c.RetryPolicy
+		clone.RetryPolicy = &policy
+	}
+	if c.LogConsumers != nil {
+		clone.LogConsumers = append([]LogConsumer{}, c.LogConsumers...)
+	}
+	if c.PreTerminateHooks != nil {
+		clone.PreTerminateHooks = append([]ContainerHook{}, c.PreTerminateHooks...)
+	}
+
+	return clone
+}
+
+// cloneStrings copies the slice, nil if it is nil
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	return append([]string{}, s...)
+}
+
+// cloneStringMap copies the map, nil if it is nil
+func cloneStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+
+	return clone
+}
+
+// cloneBuildArgs copies the build args along with the values they point to
+func cloneBuildArgs(args map[string]*string) map[string]*string {
+	if args == nil {
+		return nil
+	}
+	clone := make(map[string]*string, len(args))
+	for k, v := range args {
+		if v != nil {
+			value := *v
+			v = &value
+		}
+		clone[k] = v
+	}
+
+	return clone
+}
+
+// RequestBuilder builds a ContainerRequest one field after the other, e.g.
+//
+//	base := testcontainers.NewRequestBuilder("redis:6").WithExposedPorts("6379/tcp")
+//	req := base.Clone().WithEnv("REDIS_ARGS", "--appendonly yes").Build()
+//
+// The request it builds is a copy, which can be changed without changing the builder, nor the next requests.
+type RequestBuilder struct {
+	req ContainerRequest
+}
+
+// NewRequestBuilder starts building a request for a container of the image
+func NewRequestBuilder(image string) *RequestBuilder {
+	return &RequestBuilder{req: ContainerRequest{Image: image}}
+}
+
+// Builder starts building a request from a copy of the request, e.g. of a base definition
+func (c *ContainerRequest) Builder() *RequestBuilder {
+	return &RequestBuilder{req: c.Clone()}
+}
+
+// Clone copies the builder, so that a base definition can be extended differently by each test
+func (b *RequestBuilder) Clone() *RequestBuilder {
+	return b.req.Builder()
+}
+
+// WithEnv sets an env variable of the container
+func (b *RequestBuilder) WithEnv(key, value string) *RequestBuilder {
+	if b.req.Env == nil {
+		b.req.Env = map[string]string{}
+	}
+	b.req.Env[key] = value
+	return b
+}
+
+// WithExposedPorts exposes ports of the container, e.g. "80/tcp"
+func (b *RequestBuilder) WithExposedPorts(ports ...string) *RequestBuilder {
+	b.req.ExposedPorts = append(b.req.ExposedPorts, ports...)
+	return b
+}
+
+// WithCommand sets the command of the container as a list of arguments
+func (b *RequestBuilder) WithCommand(args ...string) *RequestBuilder {
+	b.req.Command = args
+	return b
+}
+
+// WithEntrypoint sets the entrypoint of the container
+func (b *RequestBuilder) WithEntrypoint(args ...string) *RequestBuilder {
+	b.req.Entrypoint = args
+	return b
+}
+
+// WithLabel sets a label of the container
+func (b *RequestBuilder) WithLabel(key, value string) *RequestBuilder {
+	if b.req.Labels == nil {
+		b.req.Labels = map[string]string{}
+	}
+	b.req.Labels[key] = value
+	return b
+}
+
+// WithBindMount mounts the path of the host at the target path of the container
+func (b *RequestBuilder) WithBindMount(hostPath, target string) *RequestBuilder {
+	if b.req.BindMounts == nil {
+		b.req.BindMounts = map[string]string{}
+	}
+	b.req.BindMounts[hostPath] = target
+	return b
+}
+
+// WithVolumeMount mounts the named volume at the target path of the container
+func (b *RequestBuilder) WithVolumeMount(volume, target string) *RequestBuilder {
+	if b.req.VolumeMounts == nil {
+		b.req.VolumeMounts = map[string]string{}
+	}
+	b.req.VolumeMounts[volume] = target
+	return b
+}
+
+// WithNetwork attaches the container to the network, reachable there under the given aliases
+func (b *RequestBuilder) WithNetwork(name string, aliases ...string) *RequestBuilder {
+	b.req.Networks = append(b.req.Networks, name)
+	if len(aliases) > 0 {
+		if b.req.NetworkAliases == nil {
+			b.req.NetworkAliases = map[string][]string{}
+		}
+		b.req.NetworkAliases[name] = append(b.req.NetworkAliases[name], aliases...)
+	}
+	return b
+}
+
+// WithNetworkMode sets the network mode of the container, e.g. "host"
+func (b *RequestBuilder) WithNetworkMode(mode container.NetworkMode) *RequestBuilder {
+	b.req.NetworkMode = mode
+	return b
+}
+
+// WithName sets the name of the container
+func (b *RequestBuilder) WithName(name string) *RequestBuilder {
+	b.req.Name = name
+	return b
+}
+
+// WithWaitStrategy sets the strategy to wait for before the container is considered started
+func (b *RequestBuilder) WithWaitStrategy(strategy wait.Strategy) *RequestBuilder {
+	b.req.WaitingFor = strategy
+	return b
+}
+
+// WithPreTerminateHooks adds hooks called just before the container is terminated
+func (b *RequestBuilder) WithPreTerminateHooks(hooks ...ContainerHook) *RequestBuilder {
+	b.req.PreTerminateHooks = append(b.req.PreTerminateHooks, hooks...)
+	return b
+}
+
+// Build gets a copy of the request built so far
+func (b *RequestBuilder) Build() ContainerRequest {
+	return b.req.Clone()
+}
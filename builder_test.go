@@ -0,0 +1,62 @@
+package testcontainers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestCloneRequestIsDeep(t *testing.T) {
+	arg := "1"
+	base := ContainerRequest{
+		FromDockerfile: FromDockerfile{BuildArgs: map[string]*string{"VERSION": &arg}},
+		Image:          "redis:6",
+		Env:            map[string]string{"A": "a"},
+		ExposedPorts:   make([]string, 1, 4),
+		NetworkAliases: map[string][]string{"backend": {"cache"}},
+		HealthCheck:    &container.HealthConfig{Test: []string{"CMD", "true"}},
+		RetryPolicy:    &RetryPolicy{MaxAttempts: 3},
+	}
+
+	clone := base.Clone()
+	if !reflect.DeepEqual(clone, base) {
+		t.Fatalf("expected the clone to equal the request, got %+v", clone)
+	}
+	*clone.BuildArgs["VERSION"] = "2"
+	clone.Env["A"] = "changed"
+	clone.ExposedPorts = append(clone.ExposedPorts, "6379/tcp")
+	clone.NetworkAliases["backend"][0] = "changed"
+	clone.HealthCheck.Test[1] = "false"
+	clone.RetryPolicy.MaxAttempts = 1
+
+	if arg != "1" || base.Env["A"] != "a" || base.ExposedPorts[:2][1] != "" ||
+		base.NetworkAliases["backend"][0] != "cache" || base.HealthCheck.Test[1] != "true" ||
+		base.RetryPolicy.MaxAttempts != 3 {
+		t.Errorf("expected the request not to be changed through its clone, got %+v", base)
+	}
+}
+
+func TestRequestBuilder(t *testing.T) {
+	base := NewRequestBuilder("redis:6").WithExposedPorts("6379/tcp").WithEnv("A", "a")
+
+	first := base.Clone().WithEnv("A", "first").WithNetwork("backend", "cache").Build()
+	second := base.Clone().WithExposedPorts("16379/tcp").Build()
+	req := base.Build()
+	req.Env["A"] = "changed"
+
+	if first.Env["A"] != "first" || first.NetworkAliases["backend"][0] != "cache" || len(first.ExposedPorts) != 1 {
+		t.Errorf("unexpected first request %+v", first)
+	}
+	if second.Env["A"] != "a" || len(second.ExposedPorts) != 2 || len(second.Networks) > 0 {
+		t.Errorf("unexpected second request %+v", second)
+	}
+	if rebuilt := base.Build(); rebuilt.Env["A"] != "a" || len(rebuilt.ExposedPorts) != 1 {
+		t.Errorf("expected the builder not to be changed through the requests it built, got %+v", rebuilt)
+	}
+
+	extended := req.Builder().WithLabel("team", "storage").Build()
+	if extended.Image != "redis:6" || extended.Labels["team"] != "storage" || req.Labels != nil {
+		t.Errorf("expected the request to be extended by a copy, got %+v and %+v", extended, req)
+	}
+}
@@ -0,0 +1,131 @@
+package testcontainers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/pkg/errors"
+)
+
+// ChaosImage is the image of the sidecar used to alter the network of a container, it must provide `tc`
+const ChaosImage = "gaiadocker/iproute2"
+
+// NetworkChaos describes the degradations to inject into the network interface of a container.
+// They apply to the packets sent by the container, zero values leave the corresponding property untouched.
+type NetworkChaos struct {
+	Interface string        // the network interface to alter, "eth0" if empty
+	Latency   time.Duration // delay added to every packet
+	Jitter    time.Duration // random variation of the delay, requires Latency
+	Loss      float64       // percentage of packets dropped, between 0 and 100
+	Rate      string        // bandwidth limit, in tc notation, e.g. "1mbit" or "500kbit"
+}
+
+func (nc NetworkChaos) iface() string {
+	if nc.Interface == "" {
+		return "eth0"
+	}
+	return nc.Interface
+}
+
+// netemArgs returns the arguments of the netem qdisc describing the chaos
+func (nc NetworkChaos) netemArgs() ([]string, error) {
+	args := []string{}
+	if nc.Latency > 0 {
+		args = append(args, "delay", formatTcDuration(nc.Latency))
+		if nc.Jitter > 0 {
+			args = append(args, formatTcDuration(nc.Jitter))
+		}
+	} else if nc.Jitter > 0 {
+		return nil, errors.New("jitter requires a latency")
+	}
+	if nc.Loss < 0 || nc.Loss > 100 {
+		return nil, fmt.Errorf("loss must be a percentage between 0 and 100, got %v", nc.Loss)
+	}
+	if nc.Loss > 0 {
+		args = append(args, "loss", strconv.FormatFloat(nc.Loss, 'f', -1, 64)+"%")
+	}
+	if nc.Rate != "" {
+		args = append(args, "rate", nc.Rate)
+	}
+	if len(args) == 0 {
+		return nil, errors.New("no network chaos to inject")
+	}
+
+	return args, nil
+}
+
+func formatTcDuration(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Microsecond), 10) + "us"
+}
+
+// InjectNetworkChaos alters the network of the running target container as described by chaos,
+// replacing any chaos injected before. It runs `tc` in a privileged sidecar sharing the network
+// namespace of the target, so the target image does not need to provide it.
+func InjectNetworkChaos(ctx context.Context, target Container, chaos NetworkChaos) error {
+	args, err := chaos.netemArgs()
+	if err != nil {
+		return err
+	}
+
+	cmd := append([]string{"tc", "qdisc", "replace", "dev", chaos.iface(), "root", "netem"}, args...)
+	return runTc(ctx, target, cmd)
+}
+
+// RemoveNetworkChaos reverts the chaos injected into the given network interface of the target container,
+// "eth0" if iface is empty
+func RemoveNetworkChaos(ctx context.Context, target Container, iface string) error {
+	cmd := []string{"tc", "qdisc", "del", "dev", NetworkChaos{Interface: iface}.iface(), "root"}
+	return runTc(ctx, target, cmd)
+}
+
+// runTc runs the tc command in a sidecar attached to the network namespace of the target,
+// and reports its output if it fails
+func runTc(ctx context.Context, target Container, cmd []string) error {
+	dc, ok := target.(*DockerContainer)
+	if !ok {
+		return errors.New("network chaos is only supported for docker containers")
+	}
+	p := dc.provider
+
+	c, err := p.CreateContainer(ctx, ContainerRequest{
+		Image:       ChaosImage,
+		Entrypoint:  cmd,
+		NetworkMode: container.NetworkMode("container:" + dc.ID),
+		CapAdd:      []string{"NET_ADMIN"},
+		DontRemove:  true,
+		SkipReaper:  true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not create network chaos sidecar")
+	}
+	defer c.Terminate(ctx)
+
+	if err := c.Start(ctx); err != nil {
+		return errors.Wrap(err, "could not start network chaos sidecar")
+	}
+
+	statusC, errC := p.client.ContainerWait(ctx, c.GetContainerID(), container.WaitConditionNotRunning)
+	var status container.ContainerWaitOKBody
+	select {
+	case err := <-errC:
+		return errors.Wrap(err, "could not wait for network chaos sidecar")
+	case status = <-statusC:
+	}
+
+	if status.StatusCode != 0 {
+		output := &bytes.Buffer{}
+		if logs, err := c.Logs(ctx); err == nil {
+			stdcopy.StdCopy(output, output, logs)
+			logs.Close()
+		}
+		return fmt.Errorf("could not alter the network of container '%s', %v exited with code %d: %s",
+			dc.ID, cmd, status.StatusCode, output.String())
+	}
+
+	return nil
+}
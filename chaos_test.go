@@ -0,0 +1,60 @@
+package testcontainers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNetworkChaosNetemArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		chaos   NetworkChaos
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "latency with jitter",
+			chaos: NetworkChaos{Latency: 100 * time.Millisecond, Jitter: 10 * time.Millisecond},
+			want:  []string{"delay", "100000us", "10000us"},
+		},
+		{
+			name:  "loss and rate",
+			chaos: NetworkChaos{Loss: 2.5, Rate: "1mbit"},
+			want:  []string{"loss", "2.5%", "rate", "1mbit"},
+		},
+		{
+			name:    "jitter without latency",
+			chaos:   NetworkChaos{Jitter: time.Millisecond},
+			wantErr: true,
+		},
+		{
+			name:    "loss out of range",
+			chaos:   NetworkChaos{Loss: 120},
+			wantErr: true,
+		},
+		{
+			name:    "nothing to inject",
+			chaos:   NetworkChaos{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.chaos.netemArgs()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got args %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
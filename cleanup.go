@@ -0,0 +1,212 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// cleanupTimeout bounds how long the in-process cleanup of the resources which are not reaped takes
+const cleanupTimeout = time.Minute
+
+// resourceKind is the kind of a resource tracked by the in-process cleanup, in the order they are removed in:
+// containers first, as they use the others
+type resourceKind int
+
+const (
+	containerResource resourceKind = iota
+	networkResource
+	volumeResource
+	imageResource
+)
+
+func (k resourceKind) String() string {
+	return [...]string{"container", "network", "volume", "image"}[k]
+}
+
+// trackedResource is a resource which is not reaped, removed by the in-process cleanup if it is not removed before
+type trackedResource struct {
+	kind     resourceKind
+	id       string
+	provider *DockerProvider
+}
+
+// remove removes the resource, as its Terminate or Remove would
+func (r trackedResource) remove(ctx context.Context) error {
+	c := r.provider.client
+	switch r.kind {
+	case containerResource:
+		return c.ContainerRemove(ctx, r.id, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+	case networkResource:
+		return c.NetworkRemove(ctx, r.id)
+	case volumeResource:
+		return c.VolumeRemove(ctx, r.id, true)
+	default:
+		_, err := c.ImageRemove(ctx, r.id, types.ImageRemoveOptions{Force: true, PruneChildren: true})
+		return err
+	}
+}
+
+// cleanupTracker tracks the resources which are not reaped, because SkipReaper is set or the reaper is disabled
+// or cannot be started, so that they do not leak when the process exits without removing them
+type cleanupTracker struct {
+	mutex     sync.Mutex
+	resources map[string]trackedResource
+}
+
+var fallbackCleanup = &cleanupTracker{resources: map[string]trackedResource{}}
+
+// trackForCleanup tracks the resource for the in-process cleanup, on the exit of RunTests or on a signal
+// handled by CleanupOnSignals
+func trackForCleanup(p *DockerProvider, kind resourceKind, id string) {
+	fallbackCleanup.mutex.Lock()
+	fallbackCleanup.resources[id] = trackedResource{kind: kind, id: id, provider: p}
+	fallbackCleanup.mutex.Unlock()
+}
+
+// untrackForCleanup stops tracking the resource, once it is removed or if it is meant to outlive the process
+func untrackForCleanup(id string) {
+	fallbackCleanup.mutex.Lock()
+	delete(fallbackCleanup.resources, id)
+	fallbackCleanup.mutex.Unlock()
+}
+
+// isTrackedForCleanup tells whether the resource is tracked for the in-process cleanup
+func isTrackedForCleanup(id string) bool {
+	fallbackCleanup.mutex.Lock()
+	defer fallbackCleanup.mutex.Unlock()
+	_, ok := fallbackCleanup.resources[id]
+
+	return ok
+}
+
+// CleanupOnSignals cleans up the resources which are not reaped when the process is interrupted or terminated,
+// then delivers the signal again, so that the process terminates as it would have without the handler. It is
+// meant for programs which do not handle SIGINT and SIGTERM themselves, those which do call CleanupResources in
+// their own handler. The returned stop func removes the handler, e.g. once the program cleaned up on its own.
+// RunTests handles the signals itself.
+func CleanupOnSignals() (stop func()) {
+	return cleanupOnSignals(func(sig os.Signal) {
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(sig)
+		}
+		if err != nil {
+			Logger.Printf("Could not deliver %s again once the resources were cleaned up: %s", sig, err)
+		}
+	})
+}
+
+// cleanupOnSignals cleans up the resources on SIGINT and SIGTERM, then calls then with the signal, once the
+// handler is removed
+func cleanupOnSignals(then func(os.Signal)) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			if err := CleanupResources(context.Background()); err != nil {
+				Logger.Printf("%s", err)
+			}
+			then(sig)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// CleanupResources removes the containers, networks, volumes and images created by the process which are not
+// reaped, because SkipReaper is set or the reaper is disabled or cannot be started, and are not removed yet.
+// Reused containers and networks are kept. RunTests and the handler of CleanupOnSignals call it, it is only called
+// directly by programs which are not tests, on their exit.
+func CleanupResources(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, cleanupTimeout)
+	defer cancel()
+
+	fallbackCleanup.mutex.Lock()
+	byKind := map[resourceKind][]trackedResource{}
+	for _, r := range fallbackCleanup.resources {
+		byKind[r.kind] = append(byKind[r.kind], r)
+	}
+	fallbackCleanup.resources = map[string]trackedResource{}
+	fallbackCleanup.mutex.Unlock()
+
+	var mutex sync.Mutex
+	failures := []string{}
+	for kind := containerResource; kind <= imageResource; kind++ {
+		resources := byKind[kind]
+		runConcurrently(len(resources), defaultParallelWorkers, func(i int) {
+			r := resources[i]
+			if err := r.remove(ctx); err != nil && !client.IsErrNotFound(err) {
+				mutex.Lock()
+				failures = append(failures, fmt.Sprintf("%s '%s': %s", r.kind, r.id, err))
+				mutex.Unlock()
+			}
+		})
+	}
+
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return fmt.Errorf("could not clean up %d of the resources: %s", len(failures), strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// RunTests runs the tests and cleans up the resources which are not reaped once they complete, for TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(testcontainers.RunTests(m))
+//	}
+//
+// A panic of TestMain itself, e.g. while starting shared containers, cleans them up as well. A panicking test
+// exits the process before TestMain returns: only the cleanups of the tests run then, e.g. of RunForTest.
+// An interrupt or a termination while the tests run cleans up too, then exits with the usual code of the signal.
+func RunTests(m *testing.M) (code int) {
+	stop := cleanupOnSignals(func(sig os.Signal) {
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+		os.Exit(code)
+	})
+	defer stop()
+
+	defer func() {
+		if r := recover(); r != nil {
+			if err := CleanupResources(context.Background()); err != nil {
+				Logger.Printf("%s", err)
+			}
+			panic(r)
+		}
+	}()
+
+	code = m.Run()
+	if err := CleanupResources(context.Background()); err != nil {
+		Logger.Printf("%s", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,113 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCleanupResources(t *testing.T) {
+	var mutex sync.Mutex
+	removed := []string{}
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/volumes/create"):
+			fmt.Fprintln(w, `{"Name":"unreaped"}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/networks/create"):
+			fmt.Fprintln(w, `{"Id":"unreaped-network"}`)
+		case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/containers/gone"):
+			http.Error(w, `{"message":"No such container: gone"}`, http.StatusNotFound)
+		case r.Method == http.MethodDelete:
+			mutex.Lock()
+			removed = append(removed, r.URL.Path[strings.Index(r.URL.Path, "/")+1:])
+			mutex.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	v, err := provider.CreateVolume(ctx, VolumeRequest{SkipReaper: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.CreateNetwork(ctx, NetworkRequest{Name: "unreaped", SkipReaper: true}); err != nil {
+		t.Fatal(err)
+	}
+	trackForCleanup(provider, containerResource, "leaked")
+	trackForCleanup(provider, containerResource, "gone")
+	if err := v.Remove(ctx); err != nil {
+		t.Fatal(err)
+	}
+	removed = removed[:0]
+
+	if err := CleanupResources(ctx); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(removed)
+	if strings.Join(removed, ",") != "v1.40/containers/leaked,v1.40/networks/unreaped-network" {
+		t.Errorf("expected the resources which are not removed yet to be removed, got %v", removed)
+	}
+
+	removed = removed[:0]
+	if err := CleanupResources(ctx); err != nil || len(removed) > 0 {
+		t.Errorf("expected the resources to be cleaned up once, got %v, %v", removed, err)
+	}
+}
+
+func TestCleanupResourcesReportsFailures(t *testing.T) {
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"removal already in progress"}`, http.StatusConflict)
+	})
+	trackForCleanup(provider, containerResource, "stuck")
+
+	err := CleanupResources(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "container 'stuck'") {
+		t.Fatalf("expected the failure to be reported, got %v", err)
+	}
+}
+
+func TestCleanupOnSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals cannot be sent to the process on Windows")
+	}
+	removed := make(chan string, 1)
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		removed <- r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		w.WriteHeader(http.StatusNoContent)
+	})
+	trackForCleanup(provider, containerResource, "interrupted")
+
+	stopped := cleanupOnSignals(func(os.Signal) { t.Error("expected no signal once the handler is removed") })
+	stopped()
+	stopped()
+	if !isTrackedForCleanup("interrupted") {
+		t.Fatal("expected no clean up before a signal")
+	}
+
+	delivered := make(chan os.Signal, 1)
+	stop := cleanupOnSignals(func(sig os.Signal) { delivered <- sig })
+	defer stop()
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case sig := <-delivered:
+		if sig != syscall.SIGTERM || <-removed != "interrupted" {
+			t.Errorf("expected the container to be removed before SIGTERM is delivered again, got %s", sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the signal to be handled")
+	}
+}
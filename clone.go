@@ -0,0 +1,143 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Clone commits the container to a temporary image and starts a copy of it named newName, generated if empty,
+// e.g. to fan out instances of a container which is slow to initialize. The copy has the config of the container,
+// its env, ports, mapped on other ports of the host, networks and wait strategy. The image is reaped with the
+// session of the container. If the copy fails to start, it is returned with the error, to be terminated,
+// while a copy which cannot be set up is removed, with its image.
+func (c *DockerContainer) Clone(ctx context.Context, newName string) (Container, error) {
+	var inspect types.ContainerJSON
+	err := c.provider.throttle(ctx, func() error {
+		var err error
+		inspect, err = c.provider.client.ContainerInspect(ctx, c.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not inspect container '%s' to clone: %s", c.ID, err)
+	}
+
+	// the copy is not the container reused by the requests with the hash of the original
+	labels := withLabel(inspect.Config.Labels, TestcontainerLabelHash, "")
+
+	// the container is paused while it is committed, so that the image is consistent
+	var image types.IDResponse
+	err = c.provider.throttle(ctx, func() error {
+		var err error
+		image, err = c.provider.client.ContainerCommit(ctx, c.ID, types.ContainerCommitOptions{
+			Reference: fmt.Sprintf("%s:%s", uuid.NewV4(), "latest"),
+			Pause:     true,
+			Config:    &container.Config{Labels: labels},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not commit container '%s' to clone: %s", c.ID, err)
+	}
+
+	config := *inspect.Config
+	config.Image = image.ID
+	config.Hostname = ""
+	config.Labels = labels
+	hostConfig := *inspect.HostConfig
+	hostConfig.PortBindings = randomPortBindings(inspect.HostConfig.PortBindings)
+
+	// the container is created on its primary network, then connected to the other ones
+	primary := string(hostConfig.NetworkMode)
+	if hostConfig.NetworkMode.IsDefault() {
+		primary = "bridge"
+	}
+	endpoints := map[string]*network.EndpointSettings{}
+	if settings, ok := inspect.NetworkSettings.Networks[primary]; ok {
+		endpoints[primary] = cloneEndpoint(inspect.ID, settings)
+	}
+
+	var created container.ContainerCreateCreatedBody
+	err = c.provider.throttle(ctx, func() error {
+		var err error
+		created, err = c.provider.client.ContainerCreate(ctx, &config, &hostConfig,
+			&network.NetworkingConfig{EndpointsConfig: endpoints}, newName)
+		return err
+	})
+	if err != nil {
+		c.provider.RemoveImage(ctx, image.ID, true)
+		return nil, fmt.Errorf("could not create clone of container '%s': %s", c.ID, err)
+	}
+	// the copy and its image are cleaned up in process as the original is, where it is not reaped
+	if isTrackedForCleanup(c.ID) {
+		trackForCleanup(c.provider, imageResource, image.ID)
+		trackForCleanup(c.provider, containerResource, created.ID)
+	}
+	for name, settings := range inspect.NetworkSettings.Networks {
+		if name == primary {
+			continue
+		}
+		err := c.provider.throttle(ctx, func() error {
+			return c.provider.client.NetworkConnect(ctx, name, created.ID, cloneEndpoint(inspect.ID, settings))
+		})
+		if err != nil {
+			// the clone is not returned without some of its networks, it is removed with its image
+			c.provider.removeContainer(created.ID)
+			c.provider.RemoveImage(ctx, image.ID, true)
+			return nil, fmt.Errorf("could not connect clone of container '%s' to network '%s': %s", c.ID, name, err)
+		}
+	}
+
+	clone := &DockerContainer{
+		ID:            created.ID,
+		WaitingFor:    c.WaitingFor,
+		sessionID:     c.sessionID,
+		provider:      c.provider,
+		skipReaper:    c.skipReaper,
+		retryPolicy:   c.retryPolicy,
+		logArchiveDir: c.logArchiveDir,
+		logConsumers:  c.logConsumers,
+		preTerminate:  c.preTerminate,
+	}
+	if err := clone.Start(ctx); err != nil {
+		return clone, err
+	}
+
+	return clone, nil
+}
+
+// randomPortBindings copies the port bindings, the ports of the host being chosen by the daemon
+// rather than the ones of the original container
+func randomPortBindings(bindings nat.PortMap) nat.PortMap {
+	if bindings == nil {
+		return nil
+	}
+
+	random := make(nat.PortMap, len(bindings))
+	for port, hostBindings := range bindings {
+		for _, binding := range hostBindings {
+			random[port] = append(random[port], nat.PortBinding{HostIP: binding.HostIP})
+		}
+	}
+
+	return random
+}
+
+// cloneEndpoint copies the aliases of the endpoint of the container of the given ID, but the ones the daemon
+// adds, the static addresses being left for the daemon to assign
+func cloneEndpoint(id string, settings *network.EndpointSettings) *network.EndpointSettings {
+	var aliases []string
+	for _, alias := range settings.Aliases {
+		if len(id) >= 12 && alias == id[:12] {
+			continue
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return &network.EndpointSettings{Aliases: aliases}
+}
@@ -0,0 +1,175 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestCloneContainer(t *testing.T) {
+	seed := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID: "5eed00000000abcdef",
+			HostConfig: &container.HostConfig{
+				NetworkMode:  "backend",
+				PortBindings: nat.PortMap{"5432/tcp": {{HostIP: "127.0.0.1", HostPort: "15432"}}},
+			},
+			State: &types.ContainerState{Running: true},
+		},
+		Config: &container.Config{
+			Hostname: "5eed00000000",
+			Image:    "postgres",
+			Env:      []string{"POSTGRES_PASSWORD=secret"},
+			Labels:   map[string]string{TestcontainerLabelSessionID: "session", TestcontainerLabelHash: "abc"},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"backend":  {Aliases: []string{"db", "5eed00000000"}},
+				"frontend": {Aliases: []string{"db"}},
+			},
+		},
+	}
+
+	var mutex sync.Mutex
+	var commitQuery string
+	var created struct {
+		container.Config
+		HostConfig       container.HostConfig
+		NetworkingConfig network.NetworkingConfig
+	}
+	var connected []string
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		path := r.URL.Path[strings.Index(r.URL.Path[1:], "/")+1:]
+		switch {
+		case path == "/containers/5eed00000000abcdef/json":
+			json.NewEncoder(w).Encode(seed)
+		case path == "/commit":
+			commitQuery = r.URL.RawQuery
+			fmt.Fprint(w, `{"Id":"sha256:c10e"}`)
+		case path == "/containers/create":
+			if r.URL.Query().Get("name") != "replica" {
+				t.Errorf("expected the clone to be named, got %s", r.URL.RawQuery)
+			}
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprint(w, `{"Id":"c10e00000000"}`)
+		case path == "/networks/frontend/connect":
+			var body types.NetworkConnect
+			json.NewDecoder(r.Body).Decode(&body)
+			connected = append(connected, body.Container+" "+strings.Join(body.EndpointConfig.Aliases, ","))
+		case path == "/containers/c10e00000000/start":
+			w.WriteHeader(http.StatusNoContent)
+		case path == "/containers/c10e00000000/json":
+			fmt.Fprint(w, `{"Id":"c10e00000000","State":{"Running":true},"Config":{},"NetworkSettings":{}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	seedContainer := &DockerContainer{ID: "5eed00000000abcdef", provider: provider, sessionID: "session", skipReaper: true}
+	clone, err := seedContainer.Clone(context.Background(), "replica")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clone.GetContainerID() != "c10e00000000" || clone.SessionID() != "session" {
+		t.Errorf("unexpected clone %s of session %s", clone.GetContainerID(), clone.SessionID())
+	}
+	if strings.Contains(commitQuery, "pause=0") {
+		t.Errorf("expected the container to be paused while committed, got %s", commitQuery)
+	}
+	if created.Image != "sha256:c10e" || created.Hostname != "" || created.Env[0] != "POSTGRES_PASSWORD=secret" {
+		t.Errorf("expected the config of the seed on the committed image, got %+v", created.Config)
+	}
+	if created.Labels[TestcontainerLabelHash] != "" || created.Labels[TestcontainerLabelSessionID] != "session" {
+		t.Errorf("expected the labels of the seed but its hash, got %v", created.Labels)
+	}
+	binding := created.HostConfig.PortBindings["5432/tcp"][0]
+	if binding.HostIP != "127.0.0.1" || binding.HostPort != "" {
+		t.Errorf("expected the port to be mapped on another port, got %+v", binding)
+	}
+	backend := created.NetworkingConfig.EndpointsConfig["backend"]
+	if backend == nil || strings.Join(backend.Aliases, ",") != "db" {
+		t.Errorf("expected the clone to be created on the primary network with its aliases, got %+v", created.NetworkingConfig)
+	}
+	if strings.Join(connected, ";") != "c10e00000000 db" {
+		t.Errorf("expected the clone to be connected to the other networks, got %v", connected)
+	}
+}
+
+func TestCloneIsRemovedWhenNetworksFail(t *testing.T) {
+	seed := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         "5eed00000000abcdef",
+			HostConfig: &container.HostConfig{NetworkMode: "backend"},
+			State:      &types.ContainerState{Running: true},
+		},
+		Config: &container.Config{Image: "postgres"},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{"backend": {}, "frontend": {}},
+		},
+	}
+
+	var mutex sync.Mutex
+	var removed []string
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		path := r.URL.Path[strings.Index(r.URL.Path[1:], "/")+1:]
+		switch {
+		case path == "/containers/5eed00000000abcdef/json":
+			json.NewEncoder(w).Encode(seed)
+		case path == "/commit":
+			fmt.Fprint(w, `{"Id":"sha256:c10e"}`)
+		case path == "/containers/create":
+			fmt.Fprint(w, `{"Id":"c10e00000000"}`)
+		case path == "/networks/frontend/connect":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"network frontend not found"}`)
+		case r.Method == http.MethodDelete:
+			removed = append(removed, path)
+			if strings.HasPrefix(path, "/images/") {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	seedContainer := &DockerContainer{ID: "5eed00000000abcdef", provider: provider, sessionID: "session", skipReaper: true}
+	if _, err := seedContainer.Clone(context.Background(), "replica"); err == nil {
+		t.Fatal("expected the clone to fail without its networks")
+	}
+	if strings.Join(removed, ",") != "/containers/c10e00000000,/images/sha256:c10e" {
+		t.Errorf("expected the clone and its image to be removed, got %v", removed)
+	}
+}
+
+func TestCloneContainerMock(t *testing.T) {
+	ctx := context.Background()
+	seed := &ContainerMock{ID: "seed", Request: ContainerRequest{Image: "postgres", Name: "seed"}}
+	seed.CopyToContainer(ctx, []byte("data"), "/var/lib/data", 0644)
+
+	clone, err := seed.Clone(ctx, "replica")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err := clone.ReadFile(ctx, "/var/lib/data"); err != nil || string(content) != "data" {
+		t.Errorf("expected the files of the seed, got %q, %v", content, err)
+	}
+	if running, _ := clone.IsRunning(ctx); !running || clone.GetContainerID() == seed.ID {
+		t.Errorf("expected a running copy of the seed, got %s", clone.GetContainerID())
+	}
+}
@@ -0,0 +1,63 @@
+// Command tc removes the containers, networks, volumes and images left behind by testcontainers,
+// e.g. by crashed test runs on a CI agent:
+//
+//	tc prune [-containers] [-networks] [-volumes] [-images] [-older-than 1h] [-session id] [-dry-run]
+//
+// All the kinds of resources are pruned unless some are selected.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "prune" {
+		fmt.Fprintln(os.Stderr, "usage: tc prune [flags], see tc prune -h")
+		os.Exit(2)
+	}
+
+	opts := testcontainers.PruneOptions{}
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	fs.BoolVar(&opts.Containers, "containers", false, "prune containers")
+	fs.BoolVar(&opts.Networks, "networks", false, "prune networks")
+	fs.BoolVar(&opts.Volumes, "volumes", false, "prune volumes")
+	fs.BoolVar(&opts.Images, "images", false, "prune built images")
+	fs.DurationVar(&opts.OlderThan, "older-than", 0, "only prune the resources created longer ago, e.g. 1h")
+	fs.StringVar(&opts.SessionID, "session", "", "only prune the resources of the session")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "only list the resources which would be pruned")
+	fs.Parse(os.Args[2:])
+
+	provider, err := testcontainers.NewDockerProvider()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not connect to the Docker daemon: %s\n", err)
+		os.Exit(1)
+	}
+
+	report, err := provider.Prune(context.Background(), opts)
+	verb := "removed"
+	if opts.DryRun {
+		verb = "would remove"
+	}
+	for _, resources := range []struct {
+		kind  string
+		names []string
+	}{
+		{"container", report.Containers},
+		{"network", report.Networks},
+		{"volume", report.Volumes},
+		{"image", report.Images},
+	} {
+		for _, name := range resources.names {
+			fmt.Printf("%s %s %s\n", verb, resources.kind, name)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
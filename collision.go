@@ -0,0 +1,128 @@
+package testcontainers
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+// allocatedPortPattern matches the host port the daemon failed to bind, e.g. in "Bind for 0.0.0.0:8080 failed:
+// port is already allocated", or "listen tcp 0.0.0.0:8080: bind: address already in use" for rootless daemons
+var allocatedPortPattern = regexp.MustCompile(`:(\d+)(?: failed: port is already allocated|: bind: address already in use)`)
+
+// IsPortAllocated tells whether the container failed to be created or started because one of the ports of
+// the host it binds is already allocated, e.g. a fixed port used by another container or by a container
+// removed so recently that its port is not released yet
+func IsPortAllocated(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := daemonError(err).Error()
+
+	return strings.Contains(msg, "port is already allocated") || strings.Contains(msg, "address already in use")
+}
+
+// daemonError gets the error of the daemon rather than the StartError wrapping it, as the logs of a container
+// failing to start may report the failures of the application itself
+func daemonError(err error) error {
+	for e := err; e != nil; {
+		if startErr, ok := e.(*StartError); ok {
+			return startErr.Err
+		}
+		cause, ok := e.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		e = cause.Cause()
+	}
+
+	return err
+}
+
+// retryPortCollisions recreates the container of the request, whose start failed with err, with the
+// next free ports of the host in place of the ones already allocated, up to HostPortRetries times, and returns the
+// last container
+func retryPortCollisions(ctx context.Context, provider GenericProvider, c Container, req GenericContainerRequest, err error) (Container, error) {
+	for attempt := 0; attempt < req.HostPortRetries && IsPortAllocated(err); attempt++ {
+		allocated := ""
+		if match := allocatedPortPattern.FindStringSubmatch(daemonError(err).Error()); match != nil {
+			allocated = match[1]
+		}
+		ports, changed := nextHostPorts(req.ExposedPorts, allocated)
+		if !changed {
+			break
+		}
+		Logger.Printf("Host ports %v of the container of image '%s' are already allocated, retrying with %v",
+			req.ExposedPorts, req.Image, ports)
+		if c != nil {
+			if err := c.Terminate(ctx); err != nil {
+				return c, errors.Wrap(err, "could not terminate the container whose host ports are allocated")
+			}
+		}
+
+		req.ContainerRequest = req.ContainerRequest.Clone()
+		req.ExposedPorts = ports
+		c, err = provider.CreateContainer(ctx, req.ContainerRequest)
+		if err != nil {
+			c = nil
+			continue
+		}
+		err = c.Start(ctx)
+	}
+
+	return c, err
+}
+
+// nextHostPorts replaces the allocated host port in the port specs, e.g. "8080:80/tcp", or all the fixed host
+// ports if the allocated one is unknown, with the next free ports of the host. Ranges of ports are kept.
+func nextHostPorts(specs []string, allocated string) ([]string, bool) {
+	result := make([]string, 0, len(specs))
+	changed := false
+	for _, spec := range specs {
+		mappings, err := nat.ParsePortSpec(spec)
+		if err != nil || len(mappings) != 1 {
+			result = append(result, spec)
+			continue
+		}
+		m := mappings[0]
+		hostPort, err := strconv.Atoi(m.Binding.HostPort)
+		if err != nil || hostPort == 0 || (allocated != "" && m.Binding.HostPort != allocated) {
+			result = append(result, spec)
+			continue
+		}
+
+		next := nextFreeHostPort(hostPort)
+		if next == 0 {
+			result = append(result, spec)
+			continue
+		}
+		binding := strconv.Itoa(next) + ":" + string(m.Port)
+		if m.Binding.HostIP != "" {
+			binding = m.Binding.HostIP + ":" + binding
+		}
+		result = append(result, binding)
+		changed = true
+	}
+
+	return result, changed
+}
+
+// nextFreeHostPort gets the first port after the given one which is free on this host, 0 if there is none.
+// The ports of a remote daemon host cannot be checked, they are then retried one after the other.
+func nextFreeHostPort(port int) int {
+	for p := port + 1; p <= 65535; p++ {
+		l, err := net.Listen("tcp", ":"+strconv.Itoa(p))
+		if err != nil {
+			continue
+		}
+		l.Close()
+		return p
+	}
+
+	return 0
+}
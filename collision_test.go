@@ -0,0 +1,85 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestIsPortAllocated(t *testing.T) {
+	allocated := errors.New("driver failed programming external connectivity on endpoint web: " +
+		"Bind for 0.0.0.0:8080 failed: port is already allocated")
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{allocated, true},
+		{pkgerrors.Wrap(&StartError{ContainerID: "web", Err: allocated}, "failed to start container"), true},
+		{errors.New("rootlessport listen tcp 0.0.0.0:8080: bind: address already in use"), true},
+		{&StartError{ContainerID: "web", Err: errors.New("exited"), Logs: "listen: address already in use"}, false},
+		{errors.New("no such image"), false},
+		{nil, false},
+	}
+
+	for _, test := range tests {
+		if actual := IsPortAllocated(test.err); actual != test.expected {
+			t.Errorf("expected %t for %v, got %t", test.expected, test.err, actual)
+		}
+	}
+}
+
+func TestNextHostPorts(t *testing.T) {
+	specs := []string{"8080:80/tcp", "127.0.0.1:9090:90/tcp", "5432/tcp"}
+
+	ports, changed := nextHostPorts(specs, "8080")
+	if !changed || !strings.HasSuffix(ports[0], ":80/tcp") || ports[0] == specs[0] || ports[1] != specs[1] || ports[2] != specs[2] {
+		t.Errorf("expected the allocated port only to be replaced, got %v", ports)
+	}
+	ports, changed = nextHostPorts(specs, "")
+	if !changed || ports[0] == specs[0] || !strings.HasPrefix(ports[1], "127.0.0.1:") || ports[1] == specs[1] {
+		t.Errorf("expected all the fixed ports to be replaced, got %v", ports)
+	}
+	if _, changed := nextHostPorts([]string{"5432/tcp"}, ""); changed {
+		t.Error("expected ports without fixed host ports not to be replaced")
+	}
+}
+
+func TestGenericContainerRetriesPortCollisions(t *testing.T) {
+	provider := NewProviderMock()
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		if strings.HasPrefix(req.ExposedPorts[0], "8080:") {
+			c.StartErr = errors.New("Bind for 0.0.0.0:8080 failed: port is already allocated")
+		}
+		return nil
+	}
+	if err := RegisterProvider("collision-mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("collision-mock")
+	ctx := context.Background()
+
+	_, err := Run(ctx, "nginx", WithProvider("collision-mock"), WithExposedPorts("8080:80/tcp"))
+	if !IsPortAllocated(err) {
+		t.Fatalf("expected the collision to be reported without retries, got %v", err)
+	}
+
+	c, err := Run(ctx, "nginx", WithProvider("collision-mock"), WithExposedPorts("8080:80/tcp"), WithHostPortRetries(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	containers := provider.Containers()
+	if len(containers) != 3 || containers[2] != c {
+		t.Fatalf("expected the container to be recreated once, got %d containers", len(containers))
+	}
+	if calls := strings.Join(containers[1].Calls(), ","); !strings.HasSuffix(calls, "Terminate") {
+		t.Errorf("expected the container whose port is allocated to be terminated, got %s", calls)
+	}
+	port, err := strconv.Atoi(strings.Split(containers[2].Request.ExposedPorts[0], ":")[0])
+	if err != nil || port <= 8080 {
+		t.Errorf("expected the next free port, got %v", containers[2].Request.ExposedPorts)
+	}
+}
@@ -0,0 +1,351 @@
+package testcontainers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"gopkg.in/yaml.v2"
+)
+
+// DockerCompose runs a stack of services described by compose files
+type DockerCompose interface {
+	Up(context.Context) error   // create and start the services of the stack
+	Down(context.Context) error // stop and remove the services of the stack, with their networks and volumes
+	Services() []string         // names of the services of the stack, once it is up
+
+	ServiceContainer(context.Context, string) (Container, error) // get the container of a service, once the stack is up
+}
+
+// LocalDockerCompose runs a compose stack with the docker-compose binary installed locally
+type LocalDockerCompose struct {
+	Executable       string            // the compose command, "docker-compose" or else "docker compose" by default
+	ComposeFilePaths []string          // the compose files of the stack
+	Identifier       string            // the name of the compose project, isolating the stack from others
+	Env              map[string]string // env variables of the compose command, on top of the env of the process
+	EnvFiles         []string          // env files defining the variables of the compose files, as with --env-file
+	Profiles         []string          // profiles of the services to enable, as with --profile
+	SkipReaper       bool              // do not register the stack with the reaper of the session
+
+	services []string
+	waits    map[string]composeServiceWait
+}
+
+// composeServiceWait is the readiness check of a service, declared with WithExposedService
+type composeServiceWait struct {
+	port     nat.Port
+	strategy wait.Strategy
+}
+
+// addComposeServiceWait declares the readiness check of a service in waits, created if nil
+func addComposeServiceWait(waits map[string]composeServiceWait, service string, port int, strategy wait.Strategy) map[string]composeServiceWait {
+	if waits == nil {
+		waits = map[string]composeServiceWait{}
+	}
+	waits[service] = composeServiceWait{
+		port:     nat.Port(fmt.Sprintf("%d/tcp", port)),
+		strategy: strategy,
+	}
+	return waits
+}
+
+// NewLocalDockerCompose creates a stack of the given compose files, with a random identifier if it is empty
+func NewLocalDockerCompose(filePaths []string, identifier string) *LocalDockerCompose {
+	if identifier == "" {
+		identifier = uuid.NewV4().String()
+	}
+
+	return &LocalDockerCompose{
+		ComposeFilePaths: filePaths,
+		Identifier:       strings.ToLower(identifier),
+		Env:              map[string]string{},
+	}
+}
+
+// WithEnv adds env variables to the compose command, to be interpolated in the compose files
+func (dc *LocalDockerCompose) WithEnv(env map[string]string) *LocalDockerCompose {
+	for k, v := range env {
+		dc.Env[k] = v
+	}
+	return dc
+}
+
+// WithEnvFile adds env files defining variables to interpolate in the compose files
+func (dc *LocalDockerCompose) WithEnvFile(paths ...string) *LocalDockerCompose {
+	dc.EnvFiles = append(dc.EnvFiles, paths...)
+	return dc
+}
+
+// WithProfiles enables the services of the given profiles, on top of the services without profiles
+func (dc *LocalDockerCompose) WithProfiles(profiles ...string) *LocalDockerCompose {
+	dc.Profiles = append(dc.Profiles, profiles...)
+	return dc
+}
+
+// WithExposedService declares a port the service publishes, and the strategy to wait for before
+// the service is ready, e.g. wait.ForLog, if not nil. Up checks the port is published and waits.
+func (dc *LocalDockerCompose) WithExposedService(service string, port int, strategy wait.Strategy) *LocalDockerCompose {
+	dc.waits = addComposeServiceWait(dc.waits, service, port, strategy)
+	return dc
+}
+
+// Up creates and starts the services of the stack in the background, lists them,
+// then waits for the services declared with WithExposedService to be ready
+func (dc *LocalDockerCompose) Up(ctx context.Context) error {
+	up := []string{"up", "-d"}
+	if !dc.SkipReaper && !RuntimeEnvironment().ReaperDisabled {
+		labels, err := dc.writeSessionLabels(ctx)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(labels)
+		up = append([]string{"-f", labels}, up...)
+
+		if err := dc.registerWithReaper(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dc.run(ctx, up...); err != nil {
+		return err
+	}
+
+	out, err := dc.run(ctx, "config", "--services")
+	if err != nil {
+		return err
+	}
+	dc.services = strings.Fields(out)
+
+	if len(dc.waits) == 0 {
+		return nil
+	}
+	provider, err := NewDockerProvider()
+	if err != nil {
+		return errors.Wrap(err, "failed to create Docker provider")
+	}
+	for service, w := range dc.waits {
+		c, err := provider.composeServiceContainer(ctx, dc.Identifier, service)
+		if err != nil {
+			return err
+		}
+		if _, err := c.MappedPort(ctx, w.port); err != nil {
+			return fmt.Errorf("service '%s' does not publish port %s: %s", service, w.port, err)
+		}
+		if w.strategy == nil {
+			continue
+		}
+		if err := w.strategy.WaitUntilReady(ctx, c); err != nil {
+			return errors.Wrapf(err, "service '%s' is not ready", service)
+		}
+	}
+
+	return nil
+}
+
+// composeSessionOverride is the part of the compose file format the labels of the session are added to
+type composeSessionOverride struct {
+	Version  string                                    `yaml:"version,omitempty"`
+	Services map[string]interface{}                    `yaml:"services"`
+	Networks map[string]struct{ External interface{} } `yaml:"networks"`
+	Volumes  map[string]struct{ External interface{} } `yaml:"volumes"`
+}
+
+// writeSessionLabels writes a compose file overriding the stack to label its containers, networks and volumes with
+// the session, so that the reaper only removes the stack of this session rather than every project of the same name.
+// The networks and volumes which are external are left out, they are not created by the stack. The file is
+// written in a temporary directory, to be removed once the stack is up.
+func (dc *LocalDockerCompose) writeSessionLabels(ctx context.Context) (string, error) {
+	out, err := dc.run(ctx, "config")
+	if err != nil {
+		return "", err
+	}
+	var project composeSessionOverride
+	if err := yaml.Unmarshal([]byte(out), &project); err != nil {
+		return "", errors.Wrap(err, "could not read the configuration of the stack")
+	}
+
+	labels := map[string]string{TestcontainerLabelSessionID: sessionID.String()}
+	override := map[string]interface{}{}
+	if project.Version != "" {
+		// docker-compose v1 reads the files without a version with the legacy format
+		override["version"] = project.Version
+	}
+	sections := map[string][]string{}
+	for name := range project.Services {
+		sections["services"] = append(sections["services"], name)
+	}
+	for name, n := range project.Networks {
+		if n.External == nil || n.External == false {
+			sections["networks"] = append(sections["networks"], name)
+		}
+	}
+	for name, v := range project.Volumes {
+		if v.External == nil || v.External == false {
+			sections["volumes"] = append(sections["volumes"], name)
+		}
+	}
+	for section, names := range sections {
+		entries := map[string]interface{}{}
+		for _, name := range names {
+			entries[name] = map[string]interface{}{"labels": labels}
+		}
+		override[section] = entries
+	}
+
+	content, err := yaml.Marshal(override)
+	if err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile("", "testcontainers-compose-*.yml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(content); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// registerWithReaper registers the labels of the project and of the session, which writeSessionLabels sets on the
+// containers, networks and volumes of the stack, with the reaper of the session, so that the stack is reaped
+// with the session if the tests are interrupted, while the projects of the same name of other sessions are not
+func (dc *LocalDockerCompose) registerWithReaper(ctx context.Context) error {
+	provider, err := NewDockerProvider()
+	if err != nil {
+		return errors.Wrap(err, "failed to create Docker provider")
+	}
+	r, err := NewReaper(ctx, sessionID.String(), provider)
+	if err != nil {
+		return errors.Wrap(err, "creating reaper failed")
+	}
+	if _, err := r.Connect(); err != nil {
+		return errors.Wrap(err, "connecting to reaper failed")
+	}
+	labels := map[string]string{
+		ComposeProjectLabel:         dc.Identifier,
+		TestcontainerLabelSessionID: sessionID.String(),
+	}
+	if err := r.RegisterLabels(labels); err != nil {
+		return errors.Wrapf(err, "registering stack '%s' with the reaper failed", dc.Identifier)
+	}
+
+	return nil
+}
+
+// Down stops and removes the containers of the stack, with its networks and volumes
+func (dc *LocalDockerCompose) Down(ctx context.Context) error {
+	_, err := dc.run(ctx, "down", "--volumes", "--remove-orphans")
+	return err
+}
+
+// Services gets the names of the services of the stack, once it is up
+func (dc *LocalDockerCompose) Services() []string {
+	return dc.services
+}
+
+// ServiceContainer gets the container of a service of the stack, once it is up
+func (dc *LocalDockerCompose) ServiceContainer(ctx context.Context, service string) (Container, error) {
+	provider, err := NewDockerProvider()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Docker provider")
+	}
+
+	return provider.composeServiceContainer(ctx, dc.Identifier, service)
+}
+
+// command gets the compose executable and its arguments for the given compose command
+func (dc *LocalDockerCompose) command(args ...string) ([]string, error) {
+	command := strings.Fields(dc.Executable)
+	if len(command) == 0 {
+		command = []string{"docker-compose"}
+		if _, err := exec.LookPath("docker-compose"); err != nil {
+			command = []string{"docker", "compose"}
+		}
+	}
+
+	for _, path := range dc.ComposeFilePaths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid compose file '%s'", path)
+		}
+		command = append(command, "-f", abs)
+	}
+	for _, path := range dc.EnvFiles {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid env file '%s'", path)
+		}
+		command = append(command, "--env-file", abs)
+	}
+	for _, profile := range dc.Profiles {
+		command = append(command, "--profile", profile)
+	}
+	command = append(command, "-p", dc.Identifier)
+
+	return append(command, args...), nil
+}
+
+// run runs a compose command and returns its output, or an error with its error output if it fails
+func (dc *LocalDockerCompose) run(ctx context.Context, args ...string) (string, error) {
+	command, err := dc.command(args...)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = os.Environ()
+	for k, v := range dc.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	if len(dc.ComposeFilePaths) > 0 {
+		cmd.Dir = filepath.Dir(dc.ComposeFilePaths[0])
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("'%s' failed: %s: %s", strings.Join(command, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// composeServiceContainer gets the container of a service of a compose stack, found by the labels set by compose
+func (p *DockerProvider) composeServiceContainer(ctx context.Context, project string, service string) (*DockerContainer, error) {
+	f := filters.NewArgs(
+		filters.Arg("label", ComposeProjectLabel+"="+project),
+		filters.Arg("label", ComposeServiceLabel+"="+service),
+	)
+	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: f})
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no container for service '%s' of stack '%s'", service, project)
+	}
+
+	c := &DockerContainer{
+		ID:        containers[0].ID,
+		sessionID: sessionID.String(),
+		provider:  p,
+	}
+
+	return c, nil
+}
+
+var _ DockerCompose = (*LocalDockerCompose)(nil)
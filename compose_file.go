@@ -0,0 +1,682 @@
+package testcontainers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Labels set by compose on the resources of a stack, set as well by ComposeStack
+const (
+	ComposeProjectLabel = "com.docker.compose.project"
+	ComposeServiceLabel = "com.docker.compose.service"
+)
+
+// conditions of depends_on in the compose file format
+const (
+	composeConditionStarted   = "service_started"
+	composeConditionHealthy   = "service_healthy"
+	composeConditionCompleted = "service_completed_successfully"
+)
+
+// composeFile is the part of the compose file format supported by ComposeStack
+type composeFile struct {
+	Services map[string]*composeService `yaml:"services"`
+	Networks map[string]*composeNetwork `yaml:"networks"`
+	Volumes  map[string]*composeVolume  `yaml:"volumes"`
+}
+
+type composeService struct {
+	Image         string              `yaml:"image"`
+	Build         *composeBuild       `yaml:"build"`
+	ContainerName string              `yaml:"container_name"`
+	Command       stringOrList        `yaml:"command"`
+	Entrypoint    stringOrList        `yaml:"entrypoint"`
+	Environment   mapOrList           `yaml:"environment"`
+	Labels        mapOrList           `yaml:"labels"`
+	Ports         []string            `yaml:"ports"`
+	Expose        []string            `yaml:"expose"`
+	Volumes       []string            `yaml:"volumes"`
+	Networks      serviceNetworks     `yaml:"networks"`
+	NetworkMode   string              `yaml:"network_mode"`
+	DependsOn     dependsOn           `yaml:"depends_on"`
+	Privileged    bool                `yaml:"privileged"`
+	CapAdd        []string            `yaml:"cap_add"`
+	Profiles      []string            `yaml:"profiles"`
+	Healthcheck   *composeHealthcheck `yaml:"healthcheck"`
+}
+
+// composeHealthcheck is the healthcheck of a service, its test is either a shell command or a list
+// starting with CMD, CMD-SHELL or NONE
+type composeHealthcheck struct {
+	Test        interface{} `yaml:"test"`
+	Interval    string      `yaml:"interval"`
+	Timeout     string      `yaml:"timeout"`
+	StartPeriod string      `yaml:"start_period"`
+	Retries     int         `yaml:"retries"`
+	Disable     bool        `yaml:"disable"`
+}
+
+// healthConfig converts the healthcheck to the configuration of a container
+func (h *composeHealthcheck) healthConfig() (*container.HealthConfig, error) {
+	if h.Disable {
+		return &container.HealthConfig{Test: []string{"NONE"}}, nil
+	}
+
+	config := &container.HealthConfig{Retries: h.Retries}
+	switch test := h.Test.(type) {
+	case nil:
+	case string:
+		config.Test = []string{"CMD-SHELL", test}
+	case []interface{}:
+		for _, arg := range test {
+			config.Test = append(config.Test, fmt.Sprint(arg))
+		}
+	default:
+		return nil, fmt.Errorf("invalid healthcheck test %v", test)
+	}
+
+	durations := []struct {
+		value  string
+		target *time.Duration
+	}{
+		{h.Interval, &config.Interval},
+		{h.Timeout, &config.Timeout},
+		{h.StartPeriod, &config.StartPeriod},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid healthcheck duration")
+		}
+		*d.target = duration
+	}
+
+	return config, nil
+}
+
+// composeBuild is either the path of the build context, or a map with the context and the Dockerfile
+type composeBuild struct {
+	Context    string             `yaml:"context"`
+	Dockerfile string             `yaml:"dockerfile"`
+	Args       map[string]*string `yaml:"args"`
+}
+
+func (b *composeBuild) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var context string
+	if err := unmarshal(&context); err == nil {
+		b.Context = context
+		return nil
+	}
+
+	type plain composeBuild
+	return unmarshal((*plain)(b))
+}
+
+type composeNetwork struct {
+	Name     string    `yaml:"name"`
+	Driver   string    `yaml:"driver"`
+	Internal bool      `yaml:"internal"`
+	External bool      `yaml:"external"`
+	Labels   mapOrList `yaml:"labels"`
+}
+
+type composeVolume struct {
+	Name       string            `yaml:"name"`
+	Driver     string            `yaml:"driver"`
+	DriverOpts map[string]string `yaml:"driver_opts"`
+	External   bool              `yaml:"external"`
+	Labels     mapOrList         `yaml:"labels"`
+}
+
+// stringOrList is a command, either as a string split into arguments as by a shell, or as a list of arguments
+type stringOrList []string
+
+func (s *stringOrList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err == nil {
+		words, err := splitShellWords(str)
+		if err != nil {
+			return err
+		}
+		*s = words
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// splitShellWords splits a command into its arguments as a POSIX shell does, without expanding anything:
+// arguments are separated by blanks, which are kept within single or double quotes or when escaped
+// with a backslash
+func splitShellWords(command string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+				continue
+			}
+			word.WriteByte(c)
+		case quote == '"':
+			switch {
+			case c == '"':
+				quote = 0
+			case c == '\\' && i+1 < len(command) && strings.IndexByte("$`\"\\\n", command[i+1]) >= 0:
+				i++
+				word.WriteByte(command[i])
+			default:
+				word.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == '\\':
+			if i+1 < len(command) {
+				i++
+				word.WriteByte(command[i])
+			}
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in command '%s'", command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// mapOrList is a map of env variables or labels, either as a map or as a list of KEY=VALUE entries.
+// Entries without a value are nil, e.g. env variables to be taken from the env of the process.
+type mapOrList map[string]*string
+
+func (m *mapOrList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*m = mapOrList{}
+		for _, entry := range list {
+			parts := strings.SplitN(entry, "=", 2)
+			if len(parts) == 1 {
+				(*m)[parts[0]] = nil
+				continue
+			}
+			value := parts[1]
+			(*m)[parts[0]] = &value
+		}
+		return nil
+	}
+
+	var values map[string]*string
+	if err := unmarshal(&values); err != nil {
+		return err
+	}
+	*m = values
+	return nil
+}
+
+// serviceNetwork is the configuration of a service on one of its networks
+type serviceNetwork struct {
+	Aliases     []string `yaml:"aliases"`
+	IPv4Address string   `yaml:"ipv4_address"`
+	IPv6Address string   `yaml:"ipv6_address"`
+}
+
+// serviceNetworks are the networks of a service, either as a list of names or as a map of configurations
+type serviceNetworks map[string]*serviceNetwork
+
+func (n *serviceNetworks) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*n = serviceNetworks{}
+		for _, name := range list {
+			(*n)[name] = nil
+		}
+		return nil
+	}
+
+	var networks map[string]*serviceNetwork
+	if err := unmarshal(&networks); err != nil {
+		return err
+	}
+	*n = networks
+	return nil
+}
+
+// dependsOn maps the services a service depends on to the condition to wait for,
+// either as a list of services, or as a map of the conditions
+type dependsOn map[string]string
+
+func (d *dependsOn) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*d = dependsOn{}
+		for _, name := range list {
+			(*d)[name] = composeConditionStarted
+		}
+		return nil
+	}
+
+	var conditions map[string]struct {
+		Condition string `yaml:"condition"`
+	}
+	if err := unmarshal(&conditions); err != nil {
+		return err
+	}
+	*d = dependsOn{}
+	for name, c := range conditions {
+		condition := c.Condition
+		if condition == "" {
+			condition = composeConditionStarted
+		}
+		(*d)[name] = condition
+	}
+	return nil
+}
+
+// loadComposeFiles parses the compose files, interpolating the variables found by lookup. Later files
+// override earlier ones: the services are merged as by `docker compose -f a.yml -f b.yml`, the networks
+// and volumes of later files replace the ones with the same name.
+func loadComposeFiles(paths []string, lookup func(string) (string, bool)) (*composeFile, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no compose file")
+	}
+
+	project := &composeFile{
+		Services: map[string]*composeService{},
+		Networks: map[string]*composeNetwork{},
+		Volumes:  map[string]*composeVolume{},
+	}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read compose file '%s'", path)
+		}
+
+		interpolated, err := interpolateComposeVariables(string(content), lookup)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not interpolate compose file '%s'", path)
+		}
+
+		file := &composeFile{}
+		if err := yaml.Unmarshal([]byte(interpolated), file); err != nil {
+			return nil, errors.Wrapf(err, "could not parse compose file '%s'", path)
+		}
+
+		for name, s := range file.Services {
+			if s == nil {
+				s = &composeService{}
+			}
+			if base, ok := project.Services[name]; ok {
+				s = mergeComposeService(base, s)
+			}
+			project.Services[name] = s
+		}
+		for name, n := range file.Networks {
+			if n == nil {
+				n = &composeNetwork{}
+			}
+			project.Networks[name] = n
+		}
+		for name, v := range file.Volumes {
+			if v == nil {
+				v = &composeVolume{}
+			}
+			project.Volumes[name] = v
+		}
+	}
+
+	return project, nil
+}
+
+// mergeComposeService merges the override of a service into its base definition: the values set by the
+// override replace the ones of the base, its ports, volumes and capabilities are added to the ones of the base,
+// and its maps are merged with the ones of the base
+func mergeComposeService(base *composeService, override *composeService) *composeService {
+	merged := *base
+
+	if override.Image != "" {
+		merged.Image = override.Image
+	}
+	if override.Build != nil {
+		merged.Build = override.Build
+	}
+	if override.ContainerName != "" {
+		merged.ContainerName = override.ContainerName
+	}
+	if override.Command != nil {
+		merged.Command = override.Command
+	}
+	if override.Entrypoint != nil {
+		merged.Entrypoint = override.Entrypoint
+	}
+	if override.NetworkMode != "" {
+		merged.NetworkMode = override.NetworkMode
+	}
+	if override.Privileged {
+		merged.Privileged = true
+	}
+	if override.Profiles != nil {
+		merged.Profiles = override.Profiles
+	}
+	if override.Healthcheck != nil {
+		merged.Healthcheck = override.Healthcheck
+	}
+
+	merged.Ports = appendMissing(base.Ports, override.Ports)
+	merged.Expose = appendMissing(base.Expose, override.Expose)
+	merged.CapAdd = appendMissing(base.CapAdd, override.CapAdd)
+
+	// volumes are identified by their target
+	merged.Volumes = nil
+	targets := map[string]int{}
+	for _, volume := range append(append([]string{}, base.Volumes...), override.Volumes...) {
+		target := volume
+		if parts := strings.Split(volume, ":"); len(parts) > 1 {
+			target = parts[1]
+		}
+		if i, ok := targets[target]; ok {
+			merged.Volumes[i] = volume
+			continue
+		}
+		targets[target] = len(merged.Volumes)
+		merged.Volumes = append(merged.Volumes, volume)
+	}
+
+	merged.Environment = mapOrList{}
+	merged.Labels = mapOrList{}
+	for _, m := range []mapOrList{base.Environment, override.Environment} {
+		for k, v := range m {
+			merged.Environment[k] = v
+		}
+	}
+	for _, m := range []mapOrList{base.Labels, override.Labels} {
+		for k, v := range m {
+			merged.Labels[k] = v
+		}
+	}
+
+	merged.Networks = serviceNetworks{}
+	for _, m := range []serviceNetworks{base.Networks, override.Networks} {
+		for k, v := range m {
+			merged.Networks[k] = v
+		}
+	}
+	merged.DependsOn = dependsOn{}
+	for _, m := range []dependsOn{base.DependsOn, override.DependsOn} {
+		for k, v := range m {
+			merged.DependsOn[k] = v
+		}
+	}
+
+	return &merged
+}
+
+// appendMissing appends the values of values which are not in list yet
+func appendMissing(list []string, values []string) []string {
+	result := append([]string{}, list...)
+	for _, v := range values {
+		found := false
+		for _, existing := range result {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// interpolateComposeVariables replaces the variables of a compose file with their values, found by lookup.
+// It supports $VAR, ${VAR}, the ${VAR:-default} and ${VAR-default} defaults, the ${VAR:?error} and
+// ${VAR?error} required variables, and $$ to escape a dollar sign. Defaults and errors may contain
+// variables themselves, e.g. ${VAR:-${OTHER}}.
+func interpolateComposeVariables(content string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(content); i++ {
+		if content[i] != '$' || i == len(content)-1 {
+			b.WriteByte(content[i])
+			continue
+		}
+
+		next := content[i+1]
+		switch {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := closingBrace(content, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable at '%s'", content[i:])
+			}
+			value, err := expandComposeVariable(content[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		case isComposeVariableChar(next, true):
+			end := i + 1
+			for end < len(content) && isComposeVariableChar(content[end], end == i+1) {
+				end++
+			}
+			value, _ := lookup(content[i+1 : end])
+			b.WriteString(value)
+			i = end - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// closingBrace gets the index of the brace closing the variable starting at start, skipping the
+// variables nested in it, or -1 if it is not closed
+func closingBrace(content string, start int) int {
+	depth := 0
+	for i := start; i < len(content); i++ {
+		switch {
+		case content[i] == '$' && i+1 < len(content) && (content[i+1] == '$' || content[i+1] == '{'):
+			if content[i+1] == '{' {
+				depth++
+			}
+			i++
+		case content[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// expandComposeVariable expands the expression between the braces of ${...}: the name of the variable,
+// optionally followed by an operator and its argument, which is interpolated when it is used
+func expandComposeVariable(expression string, lookup func(string) (string, bool)) (string, error) {
+	n := 0
+	for n < len(expression) && isComposeVariableChar(expression[n], n == 0) {
+		n++
+	}
+	if n == 0 {
+		return "", fmt.Errorf("invalid variable '${%s}'", expression)
+	}
+
+	name, rest := expression[:n], expression[n:]
+	value, ok := lookup(name)
+	if rest == "" {
+		return value, nil
+	}
+
+	for _, operator := range []string{":-", ":?", "-", "?"} {
+		if !strings.HasPrefix(rest, operator) {
+			continue
+		}
+		unset := !ok || (operator[0] == ':' && value == "")
+		if !unset {
+			return value, nil
+		}
+
+		arg, err := interpolateComposeVariables(rest[len(operator):], lookup)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasSuffix(operator, "-") {
+			return arg, nil
+		}
+		return "", fmt.Errorf("required variable %s is missing a value: %s", name, arg)
+	}
+
+	return "", fmt.Errorf("invalid variable '${%s}'", expression)
+}
+
+func isComposeVariableChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+// readEnvFile reads the KEY=VALUE variables of an env file, ignoring empty lines and comments
+func readEnvFile(path string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid line %d of env file '%s': %s", i+1, path, line)
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[key] = value
+	}
+
+	return env, nil
+}
+
+// applyProfiles removes the services which are not enabled by the given profiles. Services without
+// profiles are always enabled, the others when one of their profiles is.
+func (f *composeFile) applyProfiles(profiles []string) error {
+	enabled := map[string]bool{}
+	for _, p := range profiles {
+		enabled[p] = true
+	}
+
+	for name, s := range f.Services {
+		if len(s.Profiles) == 0 {
+			continue
+		}
+		active := false
+		for _, p := range s.Profiles {
+			active = active || enabled[p] || p == "*"
+		}
+		if !active && !enabled["*"] {
+			delete(f.Services, name)
+		}
+	}
+
+	for name, s := range f.Services {
+		for dependency := range s.DependsOn {
+			if _, ok := f.Services[dependency]; !ok {
+				return fmt.Errorf("service '%s' depends on '%s', which is undefined or disabled by its profiles", name, dependency)
+			}
+		}
+	}
+
+	return nil
+}
+
+// serviceOrder sorts the services so that each service comes after the services it depends on
+func (f *composeFile) serviceOrder() ([]string, error) {
+	names := make([]string, 0, len(f.Services))
+	for name := range f.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	order := make([]string, 0, len(names))
+	visited := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(name string, from string) error
+	visit = func(name string, from string) error {
+		s, ok := f.Services[name]
+		if !ok {
+			return fmt.Errorf("service '%s' depends on undefined service '%s'", from, name)
+		}
+		if visited[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("circular dependency between services '%s' and '%s'", from, name)
+		}
+		visiting[name] = true
+
+		dependencies := make([]string, 0, len(s.DependsOn))
+		for dependency := range s.DependsOn {
+			dependencies = append(dependencies, dependency)
+		}
+		sort.Strings(dependencies)
+		for _, dependency := range dependencies {
+			if err := visit(dependency, name); err != nil {
+				return err
+			}
+		}
+
+		visiting[name] = false
+		visited[name] = true
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
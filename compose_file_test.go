@@ -0,0 +1,402 @@
+package testcontainers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeComposeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadComposeFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeComposeFile(t, dir, "docker-compose.yml", `
+services:
+  app:
+    build: ./app
+    command: serve --port 8080
+    environment:
+      - MODE=test
+      - HOME
+    depends_on:
+      db:
+        condition: service_healthy
+    networks:
+      backend:
+        aliases: [api]
+  db:
+    image: postgres
+    environment:
+      POSTGRES_PASSWORD: secret
+    networks: [backend]
+networks:
+  backend:
+`)
+
+	project, err := loadComposeFiles([]string{path}, os.LookupEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := project.Services["app"]
+	if app.Build == nil || app.Build.Context != "./app" {
+		t.Fatalf("expected the build context to be parsed, got %+v", app.Build)
+	}
+	if strings.Join(app.Command, " ") != "serve --port 8080" {
+		t.Fatalf("unexpected command %v", app.Command)
+	}
+	if *app.Environment["MODE"] != "test" || app.Environment["HOME"] != nil {
+		t.Fatalf("unexpected environment %v", app.Environment)
+	}
+	if app.DependsOn["db"] != composeConditionHealthy {
+		t.Fatalf("unexpected dependencies %v", app.DependsOn)
+	}
+	if aliases := app.Networks["backend"].Aliases; len(aliases) != 1 || aliases[0] != "api" {
+		t.Fatalf("unexpected networks %v", app.Networks)
+	}
+	if _, ok := project.Services["db"].Networks["backend"]; !ok {
+		t.Fatalf("expected the list of networks to be parsed")
+	}
+	if _, ok := project.Networks["backend"]; !ok {
+		t.Fatalf("expected the network to be declared")
+	}
+
+	order, err := project.serviceOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, ",") != "db,app" {
+		t.Fatalf("expected db to start before app, got %v", order)
+	}
+}
+
+func TestComposeServiceOrderDetectsCycles(t *testing.T) {
+	project := &composeFile{Services: map[string]*composeService{
+		"a": {DependsOn: dependsOn{"b": composeConditionStarted}},
+		"b": {DependsOn: dependsOn{"a": composeConditionStarted}},
+	}}
+	if _, err := project.serviceOrder(); err == nil {
+		t.Fatal("expected an error for circular dependencies")
+	}
+
+	project = &composeFile{Services: map[string]*composeService{
+		"a": {DependsOn: dependsOn{"missing": composeConditionStarted}},
+	}}
+	if _, err := project.serviceOrder(); err == nil {
+		t.Fatal("expected an error for an undefined dependency")
+	}
+}
+
+func TestInterpolateComposeVariables(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		values := map[string]string{"TAG": "1.2", "EMPTY": "", "FALLBACK": "2.0"}
+		value, ok := values[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"image: app:$TAG", "image: app:1.2"},
+		{"image: app:${TAG}-alpine", "image: app:1.2-alpine"},
+		{"image: app:${MISSING:-latest}", "image: app:latest"},
+		{"image: app:${EMPTY:-latest}", "image: app:latest"},
+		{"image: app:${EMPTY-latest}", "image: app:"},
+		{"command: echo $$HOME", "command: echo $HOME"},
+		{"image: app:${MISSING:-${FALLBACK}}", "image: app:2.0"},
+		{"image: app:${MISSING:-${EMPTY:-latest}}-alpine", "image: app:latest-alpine"},
+		{"image: app:${TAG:-${MISSING:?unused}}", "image: app:1.2"},
+		{"image: app:${MISSING:-$$TAG}", "image: app:$TAG"},
+		{"image: app:${MISSING:-some-default}", "image: app:some-default"},
+		{"price: 5$", "price: 5$"},
+	}
+	for _, tt := range tests {
+		interpolated, err := interpolateComposeVariables(tt.content, lookup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if interpolated != tt.expected {
+			t.Errorf("expected '%s' for '%s', got '%s'", tt.expected, tt.content, interpolated)
+		}
+	}
+
+	if _, err := interpolateComposeVariables("image: ${MISSING:?a tag is needed}", lookup); err == nil {
+		t.Fatal("expected an error for a missing required variable")
+	}
+	_, err := interpolateComposeVariables("image: ${MISSING:?must-be-set}", lookup)
+	if err == nil || !strings.HasSuffix(err.Error(), "MISSING is missing a value: must-be-set") {
+		t.Fatalf("expected the whole message of a required variable, got %v", err)
+	}
+	_, err = interpolateComposeVariables("image: ${EMPTY?must-be-set}", lookup)
+	if err != nil {
+		t.Fatalf("expected a set variable to satisfy ?, got %v", err)
+	}
+	if _, err := interpolateComposeVariables("image: ${-latest}", lookup); err == nil {
+		t.Fatal("expected an error for a variable without name")
+	}
+}
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{"serve --port 8080", []string{"serve", "--port", "8080"}},
+		{`sh -c "echo hi && sleep 60"`, []string{"sh", "-c", "echo hi && sleep 60"}},
+		{`echo 'single $quoted' "double \"quoted\""`, []string{"echo", "single $quoted", `double "quoted"`}},
+		{`echo escaped\ space ''`, []string{"echo", "escaped space", ""}},
+		{"  ", []string{}},
+	}
+	for _, tt := range tests {
+		words, err := splitShellWords(tt.command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(words, "|") != strings.Join(tt.expected, "|") || len(words) != len(tt.expected) {
+			t.Errorf("expected %q for '%s', got %q", tt.expected, tt.command, words)
+		}
+	}
+
+	if _, err := splitShellWords(`echo "unterminated`); err == nil {
+		t.Fatal("expected an error for an unterminated quote")
+	}
+}
+
+func TestComposeServiceCommandIsPassedAsArguments(t *testing.T) {
+	stack := NewComposeStack(nil, "project")
+	service := &composeService{
+		Image:   "alpine",
+		Command: stringOrList{"sh", "-c", "echo hi && sleep 60"},
+	}
+
+	req, err := stack.containerRequest("app", service, map[string]string{"default": "project_default"}, nil, os.LookupEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Command) != 3 || req.Command[2] != "echo hi && sleep 60" {
+		t.Fatalf("expected the arguments of the command to be kept, got %q", req.Command)
+	}
+}
+
+func TestLoadComposeFilesWithOverrideAndEnvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := writeComposeFile(t, dir, "docker-compose.yml", `
+services:
+  app:
+    image: app:${TAG}
+    ports: ["8080"]
+    environment:
+      MODE: prod
+      DEBUG: "false"
+    volumes:
+      - data:/data
+volumes:
+  data:
+`)
+	override := writeComposeFile(t, dir, "docker-compose.test.yml", `
+services:
+  app:
+    ports: ["9090"]
+    environment:
+      MODE: test
+    volumes:
+      - ./fixtures:/data
+`)
+	envFile := writeComposeFile(t, dir, "test.env", "# test env\nexport TAG=\"2.0\"\n\nOTHER='x'\n")
+
+	stack := NewComposeStack([]string{base, override}, "test").WithEnvFile(envFile)
+	lookup, err := stack.variables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := loadComposeFiles(stack.ComposeFilePaths, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := project.Services["app"]
+	if app.Image != "app:2.0" {
+		t.Fatalf("expected the tag of the env file, got '%s'", app.Image)
+	}
+	if strings.Join(app.Ports, ",") != "8080,9090" {
+		t.Fatalf("expected the ports to be merged, got %v", app.Ports)
+	}
+	if *app.Environment["MODE"] != "test" || *app.Environment["DEBUG"] != "false" {
+		t.Fatalf("expected the environments to be merged, got %v", app.Environment)
+	}
+	if len(app.Volumes) != 1 || app.Volumes[0] != "./fixtures:/data" {
+		t.Fatalf("expected the volume to be overridden, got %v", app.Volumes)
+	}
+
+	stack.WithEnv(map[string]string{"TAG": "3.0"})
+	lookup, err = stack.variables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, _ := lookup("TAG"); tag != "3.0" {
+		t.Fatalf("expected the variables of the stack to override the env files, got '%s'", tag)
+	}
+}
+
+func TestComposeProfiles(t *testing.T) {
+	newProject := func() *composeFile {
+		return &composeFile{Services: map[string]*composeService{
+			"app":   {},
+			"admin": {Profiles: []string{"admin"}},
+			"debug": {Profiles: []string{"debug"}, DependsOn: dependsOn{"admin": composeConditionStarted}},
+		}}
+	}
+
+	project := newProject()
+	if err := project.applyProfiles(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(project.Services) != 1 || project.Services["app"] == nil {
+		t.Fatalf("expected only the services without profiles, got %v", project.Services)
+	}
+
+	project = newProject()
+	if err := project.applyProfiles([]string{"admin"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(project.Services) != 2 || project.Services["admin"] == nil {
+		t.Fatalf("expected the admin service to be enabled, got %v", project.Services)
+	}
+
+	project = newProject()
+	if err := project.applyProfiles([]string{"debug"}); err == nil {
+		t.Fatal("expected an error for a dependency disabled by its profiles")
+	}
+}
+
+func TestComposeHealthcheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeComposeFile(t, dir, "docker-compose.yml", `
+services:
+  db:
+    image: postgres
+    healthcheck:
+      test: ["CMD", "pg_isready", "-U", "postgres"]
+      interval: 1s
+      timeout: 5s
+      start_period: 1m30s
+      retries: 10
+  cache:
+    image: redis
+    healthcheck:
+      test: redis-cli ping | grep PONG
+  web:
+    image: nginx
+    healthcheck:
+      disable: true
+`)
+	project, err := loadComposeFiles([]string{path}, os.LookupEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stack := NewComposeStack([]string{path}, "project")
+	networks := map[string]string{"default": "project_default"}
+	tests := map[string]string{
+		"db":    "CMD|pg_isready|-U|postgres",
+		"cache": "CMD-SHELL|redis-cli ping | grep PONG",
+		"web":   "NONE",
+	}
+	for service, expected := range tests {
+		req, err := stack.containerRequest(service, project.Services[service], networks, nil, os.LookupEnv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.HealthCheck == nil || strings.Join(req.HealthCheck.Test, "|") != expected {
+			t.Fatalf("expected the healthcheck of %s to be %s, got %+v", service, expected, req.HealthCheck)
+		}
+	}
+
+	req, _ := stack.containerRequest("db", project.Services["db"], networks, nil, os.LookupEnv)
+	health := req.HealthCheck
+	if health.Interval != time.Second || health.Timeout != 5*time.Second || health.StartPeriod != 90*time.Second || health.Retries != 10 {
+		t.Fatalf("unexpected healthcheck %+v", health)
+	}
+}
+
+func TestComposeServiceNetworkAddresses(t *testing.T) {
+	stack := NewComposeStack(nil, "project")
+	networks := map[string]string{"front": "project_front", "back": "project_back"}
+	service := &composeService{
+		Image: "nginx",
+		Networks: serviceNetworks{
+			"front": {IPv4Address: "172.30.0.5"},
+			"back":  {IPv6Address: "fd00::5"},
+		},
+	}
+
+	req, err := stack.containerRequest("web", service, networks, nil, os.LookupEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.NetworkIPs["project_front"] != "172.30.0.5" || req.NetworkIPs["project_back"] != "fd00::5" {
+		t.Errorf("expected the addresses of the service on its networks, got %v", req.NetworkIPs)
+	}
+
+	service.Networks["back"].IPv4Address = "172.31.0.5"
+	if _, err := stack.containerRequest("web", service, networks, nil, os.LookupEnv); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("expected both addresses on a network to be unsupported, got %v", err)
+	}
+}
+
+func TestWaitForComposeConditionFailsFast(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	healthy := &ContainerMock{ID: "healthy", status: "running", HealthTransitions: []string{"starting", "healthy"}}
+	if err := waitForComposeCondition(ctx, healthy, composeConditionHealthy); err != nil {
+		t.Fatal(err)
+	}
+
+	unhealthy := &ContainerMock{ID: "unhealthy", status: "running", HealthTransitions: []string{"starting", "unhealthy"}}
+	err := waitForComposeCondition(ctx, unhealthy, composeConditionHealthy)
+	if err == nil || !strings.Contains(err.Error(), "container is unhealthy") {
+		t.Errorf("expected an unhealthy dependency to fail, got %v", err)
+	}
+
+	exited := &ContainerMock{
+		ID:                "exited",
+		status:            "running",
+		StateTransitions:  []string{"running", "exited"},
+		HealthTransitions: []string{"starting"},
+		ExitCode:          1,
+	}
+	err = waitForComposeCondition(ctx, exited, composeConditionHealthy)
+	if err == nil || !strings.Contains(err.Error(), "exited with code 1") {
+		t.Errorf("expected an exited dependency to fail, got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Error("expected the failures to be reported before the context ends")
+	}
+}
@@ -0,0 +1,501 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// ComposeStack runs a compose stack through a provider, without the docker-compose binary.
+// It supports the services, networks and volumes of the compose file format, with the
+// depends_on ordering and healthchecks; other settings, e.g. resource limits, are ignored.
+// Like the other resources created through the provider, the resources of the stack are
+// labelled with the session and reaped with it.
+type ComposeStack struct {
+	ComposeFilePaths []string        // the compose files of the stack
+	Identifier       string          // the name of the compose project, prefixing the names of its resources
+	Provider         GenericProvider // the provider running the stack, Docker if nil
+
+	// Env are variables interpolated in the compose files, overriding the env of the process, which
+	// overrides the EnvFiles. Without EnvFiles, the .env file next to the first compose file is read if it exists.
+	Env      map[string]string
+	EnvFiles []string
+
+	// Profiles enable the services of these profiles, as well as the services without profiles.
+	// Without Profiles, they are read from the COMPOSE_PROFILES variable, as a comma separated list.
+	Profiles []string
+
+	waits      map[string]composeServiceWait
+	services   []string
+	containers map[string]Container
+	networks   []Network
+	volumes    []Volume
+}
+
+// NewComposeStack creates a stack of the given compose files, with a random identifier if it is empty
+func NewComposeStack(filePaths []string, identifier string) *ComposeStack {
+	if identifier == "" {
+		identifier = uuid.NewV4().String()
+	}
+
+	return &ComposeStack{
+		ComposeFilePaths: filePaths,
+		Identifier:       strings.ToLower(identifier),
+		Env:              map[string]string{},
+	}
+}
+
+// WithEnv adds variables to interpolate in the compose files
+func (s *ComposeStack) WithEnv(env map[string]string) *ComposeStack {
+	for k, v := range env {
+		s.Env[k] = v
+	}
+	return s
+}
+
+// WithEnvFile adds env files defining variables to interpolate in the compose files, as with --env-file
+func (s *ComposeStack) WithEnvFile(paths ...string) *ComposeStack {
+	s.EnvFiles = append(s.EnvFiles, paths...)
+	return s
+}
+
+// WithProfiles enables the services of the given profiles, as with --profile
+func (s *ComposeStack) WithProfiles(profiles ...string) *ComposeStack {
+	s.Profiles = append(s.Profiles, profiles...)
+	return s
+}
+
+// WithExposedService exposes a port of the service, and declares the strategy to wait for before
+// the service is ready, e.g. wait.ForLog, if not nil
+func (s *ComposeStack) WithExposedService(service string, port int, strategy wait.Strategy) *ComposeStack {
+	s.waits = addComposeServiceWait(s.waits, service, port, strategy)
+	return s
+}
+
+// Up creates the networks and volumes of the stack, then creates and starts its services, each one
+// once the services it depends on are started, and ready for the ones declared with WithExposedService.
+// If it fails, the resources created so far are kept, for Down to remove them.
+func (s *ComposeStack) Up(ctx context.Context) error {
+	lookup, err := s.variables()
+	if err != nil {
+		return err
+	}
+	project, err := loadComposeFiles(s.ComposeFilePaths, lookup)
+	if err != nil {
+		return err
+	}
+	profiles := s.Profiles
+	if value, ok := lookup("COMPOSE_PROFILES"); ok && len(profiles) == 0 && value != "" {
+		profiles = strings.Split(value, ",")
+	}
+	if err := project.applyProfiles(profiles); err != nil {
+		return err
+	}
+	order, err := project.serviceOrder()
+	if err != nil {
+		return err
+	}
+
+	if s.Provider == nil {
+		s.Provider, err = ProviderDocker.GetProvider()
+		if err != nil {
+			return err
+		}
+	}
+
+	networks, err := s.createNetworks(ctx, project)
+	if err != nil {
+		return err
+	}
+	volumes, err := s.createVolumes(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	s.containers = map[string]Container{}
+	for _, name := range order {
+		service := project.Services[name]
+		for dependency, condition := range service.DependsOn {
+			if err := waitForComposeCondition(ctx, s.containers[dependency], condition); err != nil {
+				return errors.Wrapf(err, "service '%s' depends on '%s'", name, dependency)
+			}
+		}
+
+		req, err := s.containerRequest(name, service, networks, volumes, lookup)
+		if err != nil {
+			return err
+		}
+		c, err := s.Provider.RunContainer(ctx, req)
+		if c != nil {
+			s.containers[name] = c
+			s.services = append(s.services, name)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not start service '%s'", name)
+		}
+	}
+
+	return nil
+}
+
+// Down removes the containers of the stack, in the reverse order of their start, then its networks and volumes
+func (s *ComposeStack) Down(ctx context.Context) error {
+	failures := []string{}
+	for i := len(s.services) - 1; i >= 0; i-- {
+		if c, ok := s.containers[s.services[i]]; ok {
+			if err := c.Terminate(ctx); err != nil {
+				failures = append(failures, fmt.Sprintf("service %s: %s", s.services[i], err))
+			}
+		}
+	}
+	for _, n := range s.networks {
+		if err := n.Remove(ctx); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	for _, v := range s.volumes {
+		if err := v.Remove(ctx); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	s.services = nil
+	s.containers = nil
+	s.networks = nil
+	s.volumes = nil
+
+	if len(failures) > 0 {
+		return fmt.Errorf("could not remove stack '%s': %s", s.Identifier, strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// Services gets the names of the services of the stack, in their order of start
+func (s *ComposeStack) Services() []string {
+	return s.services
+}
+
+// ServiceContainer gets the container of a service of the stack, once it is up
+func (s *ComposeStack) ServiceContainer(ctx context.Context, service string) (Container, error) {
+	c, ok := s.containers[service]
+	if !ok {
+		return nil, fmt.Errorf("no container for service '%s' of stack '%s'", service, s.Identifier)
+	}
+
+	return c, nil
+}
+
+// variables gets the lookup of the variables of the stack: its Env first, then the env of the process,
+// then its env files
+func (s *ComposeStack) variables() (func(string) (string, bool), error) {
+	envFiles := s.EnvFiles
+	if len(envFiles) == 0 && len(s.ComposeFilePaths) > 0 {
+		defaultEnvFile := filepath.Join(s.projectDir(), ".env")
+		if _, err := os.Stat(defaultEnvFile); err == nil {
+			envFiles = []string{defaultEnvFile}
+		}
+	}
+
+	fileEnv := map[string]string{}
+	for _, path := range envFiles {
+		env, err := readEnvFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read env file")
+		}
+		for k, v := range env {
+			fileEnv[k] = v
+		}
+	}
+
+	return func(name string) (string, bool) {
+		if value, ok := s.Env[name]; ok {
+			return value, true
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		value, ok := fileEnv[name]
+		return value, ok
+	}, nil
+}
+
+// projectDir is the directory relative paths of the compose files are resolved against
+func (s *ComposeStack) projectDir() string {
+	return filepath.Dir(s.ComposeFilePaths[0])
+}
+
+func (s *ComposeStack) labels(extra mapOrList) map[string]string {
+	labels := map[string]string{ComposeProjectLabel: s.Identifier}
+	for k, v := range extra {
+		if v != nil {
+			labels[k] = *v
+		}
+	}
+	return labels
+}
+
+// createNetworks creates the networks of the project, including the default one if a service uses it,
+// and returns their actual names
+func (s *ComposeStack) createNetworks(ctx context.Context, project *composeFile) (map[string]string, error) {
+	if _, ok := project.Networks["default"]; !ok {
+		for _, service := range project.Services {
+			if len(service.Networks) == 0 && service.NetworkMode == "" {
+				project.Networks["default"] = &composeNetwork{}
+				break
+			}
+		}
+	}
+
+	names := map[string]string{}
+	for key, n := range project.Networks {
+		name := n.Name
+		if name == "" {
+			name = s.Identifier + "_" + key
+			if n.External {
+				name = key
+			}
+		}
+		names[key] = name
+		if n.External {
+			continue
+		}
+
+		network, err := s.Provider.CreateNetwork(ctx, NetworkRequest{
+			Name:           name,
+			Driver:         n.Driver,
+			Internal:       n.Internal,
+			Labels:         s.labels(n.Labels),
+			CheckDuplicate: true,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not create network '%s'", key)
+		}
+		s.networks = append(s.networks, network)
+	}
+
+	return names, nil
+}
+
+// createVolumes creates the named volumes of the project and returns their actual names
+func (s *ComposeStack) createVolumes(ctx context.Context, project *composeFile) (map[string]string, error) {
+	names := map[string]string{}
+	for key, v := range project.Volumes {
+		name := v.Name
+		if name == "" {
+			name = s.Identifier + "_" + key
+			if v.External {
+				name = key
+			}
+		}
+		names[key] = name
+		if v.External {
+			continue
+		}
+
+		volume, err := s.Provider.CreateVolume(ctx, VolumeRequest{
+			Name:       name,
+			Driver:     v.Driver,
+			DriverOpts: v.DriverOpts,
+			Labels:     s.labels(v.Labels),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not create volume '%s'", key)
+		}
+		s.volumes = append(s.volumes, volume)
+	}
+
+	return names, nil
+}
+
+// containerRequest translates a service of the compose file to the request of its container
+func (s *ComposeStack) containerRequest(name string, service *composeService, networks map[string]string, volumes map[string]string, lookup func(string) (string, bool)) (ContainerRequest, error) {
+	req := ContainerRequest{
+		Image:        service.Image,
+		Name:         service.ContainerName,
+		Command:      service.Command,
+		Entrypoint:   service.Entrypoint,
+		Env:          map[string]string{},
+		ExposedPorts: append(append([]string{}, service.Ports...), service.Expose...),
+		Labels:       s.labels(service.Labels),
+		BindMounts:   map[string]string{},
+		VolumeMounts: map[string]string{},
+		Privileged:   service.Privileged,
+		CapAdd:       service.CapAdd,
+		DontRemove:   true, // exited services are kept, as with compose, until Down
+	}
+	req.Labels[ComposeServiceLabel] = name
+	if req.Name == "" {
+		req.Name = fmt.Sprintf("%s-%s-1", s.Identifier, name)
+	}
+
+	if w, ok := s.waits[name]; ok {
+		_, bindings, err := nat.ParsePortSpecs(req.ExposedPorts)
+		if err != nil {
+			return req, errors.Wrapf(err, "invalid ports of service '%s'", name)
+		}
+		if _, exposed := bindings[w.port]; !exposed {
+			req.ExposedPorts = append(req.ExposedPorts, string(w.port))
+		}
+		req.WaitingFor = w.strategy
+	}
+
+	if service.Build != nil {
+		buildContext := service.Build.Context
+		if !filepath.IsAbs(buildContext) {
+			buildContext = filepath.Join(s.projectDir(), buildContext)
+		}
+		req.FromDockerfile = FromDockerfile{
+			Context:    buildContext,
+			Dockerfile: service.Build.Dockerfile,
+			BuildArgs:  service.Build.Args,
+		}
+	} else if service.Image == "" {
+		return req, fmt.Errorf("service '%s' has neither an image nor a build", name)
+	}
+
+	if service.Healthcheck != nil {
+		health, err := service.Healthcheck.healthConfig()
+		if err != nil {
+			return req, errors.Wrapf(err, "invalid healthcheck of service '%s'", name)
+		}
+		req.HealthCheck = health
+	}
+
+	for k, v := range service.Environment {
+		if v != nil {
+			req.Env[k] = *v
+		} else if value, ok := lookup(k); ok {
+			req.Env[k] = value
+		}
+	}
+
+	for _, volume := range service.Volumes {
+		parts := strings.Split(volume, ":")
+		if len(parts) < 2 {
+			// anonymous volumes are created by the daemon
+			continue
+		}
+		source, target := parts[0], parts[1]
+		if strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") {
+			if strings.HasPrefix(source, "~") {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return req, err
+				}
+				source = filepath.Join(home, source[1:])
+			}
+			if !filepath.IsAbs(source) {
+				source = filepath.Join(s.projectDir(), source)
+			}
+			abs, err := filepath.Abs(source)
+			if err != nil {
+				return req, err
+			}
+			req.BindMounts[abs] = target
+			continue
+		}
+		volumeName, ok := volumes[source]
+		if !ok {
+			return req, fmt.Errorf("service '%s' uses undefined volume '%s'", name, source)
+		}
+		req.VolumeMounts[volumeName] = target
+	}
+
+	switch {
+	case strings.HasPrefix(service.NetworkMode, "service:"):
+		dependency := strings.TrimPrefix(service.NetworkMode, "service:")
+		c, ok := s.containers[dependency]
+		if !ok {
+			return req, fmt.Errorf("service '%s' uses the network of service '%s', which it does not depend on", name, dependency)
+		}
+		req.NetworkMode = container.NetworkMode("container:" + c.GetContainerID())
+	case service.NetworkMode != "":
+		req.NetworkMode = container.NetworkMode(service.NetworkMode)
+	default:
+		serviceNetworks := service.Networks
+		if len(serviceNetworks) == 0 {
+			serviceNetworks = map[string]*serviceNetwork{"default": nil}
+		}
+		req.NetworkAliases = map[string][]string{}
+		req.NetworkIPs = map[string]string{}
+		for key, config := range serviceNetworks {
+			networkName, ok := networks[key]
+			if !ok {
+				return req, fmt.Errorf("service '%s' uses undefined network '%s'", name, key)
+			}
+			req.Networks = append(req.Networks, networkName)
+			req.NetworkAliases[networkName] = []string{name}
+			if config == nil {
+				continue
+			}
+			req.NetworkAliases[networkName] = append(req.NetworkAliases[networkName], config.Aliases...)
+			switch {
+			case config.IPv4Address != "" && config.IPv6Address != "":
+				return req, fmt.Errorf("service '%s' sets both an ipv4_address and an ipv6_address on network '%s', which is unsupported", name, key)
+			case config.IPv4Address != "":
+				req.NetworkIPs[networkName] = config.IPv4Address
+			case config.IPv6Address != "":
+				req.NetworkIPs[networkName] = config.IPv6Address
+			}
+		}
+	}
+
+	return req, nil
+}
+
+// waitForComposeCondition waits for the container of a service to reach the condition of a depends_on
+func waitForComposeCondition(ctx context.Context, c Container, condition string) error {
+	if condition == composeConditionStarted || c == nil {
+		return nil
+	}
+
+	for {
+		state, err := c.State(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch condition {
+		case composeConditionHealthy:
+			if state.Health == nil {
+				return errors.New("service has no healthcheck, neither in the compose file nor in its image")
+			}
+			switch {
+			case state.Health.Status == "healthy":
+				return nil
+			case state.Health.Status == "unhealthy":
+				// as docker compose, rather than waiting for a dependency which may never recover
+				return errors.New("dependency failed to start: container is unhealthy")
+			case !state.Running && (state.Status == "exited" || state.Status == "dead"):
+				return fmt.Errorf("dependency failed to start: container exited with code %d", state.ExitCode)
+			}
+		case composeConditionCompleted:
+			if !state.Running && state.Status == "exited" {
+				if state.ExitCode != 0 {
+					return fmt.Errorf("service exited with code %d", state.ExitCode)
+				}
+				return nil
+			}
+		default:
+			return fmt.Errorf("unsupported condition '%s'", condition)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+var _ DockerCompose = (*ComposeStack)(nil)
@@ -0,0 +1,212 @@
+package testcontainers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+	"gopkg.in/yaml.v2"
+)
+
+func TestLocalDockerCompose(t *testing.T) {
+	ctx := context.Background()
+	compose := NewLocalDockerCompose([]string{"./testresources/docker-compose.yml"}, "")
+	defer compose.Down(ctx)
+
+	if err := compose.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	services := compose.Services()
+	if len(services) != 1 || services[0] != "nginx" {
+		t.Fatalf("expected the nginx service, got %v", services)
+	}
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nginx.MappedPort(ctx, "80/tcp"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compose.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalDockerComposeCommand(t *testing.T) {
+	compose := NewLocalDockerCompose([]string{"./testresources/docker-compose.yml"}, "MyStack")
+	compose.Executable = "docker compose"
+
+	command, err := compose.command("up", "-d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, _ := filepath.Abs("./testresources/docker-compose.yml")
+	expected := "docker compose -f " + file + " -p mystack up -d"
+	if strings.Join(command, " ") != expected {
+		t.Fatalf("expected '%s', got '%s'", expected, strings.Join(command, " "))
+	}
+}
+
+func TestComposeStack(t *testing.T) {
+	ctx := context.Background()
+	stack := NewComposeStack([]string{"./testresources/docker-compose-stack.yml"}, "")
+	defer stack.Down(ctx)
+
+	if err := stack.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	services := stack.Services()
+	if strings.Join(services, ",") != "cache,web" {
+		t.Fatalf("expected cache to start before web, got %v", services)
+	}
+
+	web, err := stack.ServiceContainer(ctx, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := web.MappedPort(ctx, "80/tcp"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stack.ServiceContainer(ctx, "missing"); err == nil {
+		t.Fatal("expected an error for an undefined service")
+	}
+
+	provider, err := NewDockerProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inspect, err := provider.client.ContainerInspect(ctx, web.GetContainerID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inspect.Config.Labels[TestcontainerLabelSessionID] != sessionID.String() {
+		t.Fatal("expected the services to be labelled with the session, to be reaped with it")
+	}
+
+	if err := stack.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestComposeStackWaitsForExposedServices(t *testing.T) {
+	ctx := context.Background()
+	stack := NewComposeStack([]string{"./testresources/docker-compose-stack.yml"}, "")
+	stack.WithExposedService("web", 80, wait.ForHTTP("/")).
+		WithExposedService("cache", 6379, wait.ForLog("Ready to accept connections"))
+	defer stack.Down(ctx)
+
+	if err := stack.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestComposeStackExposesWaitedPorts(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+	requests := map[string]ContainerRequest{}
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		requests[req.Labels[ComposeServiceLabel]] = req
+		return nil
+	}
+
+	stack := NewComposeStack([]string{"./testresources/docker-compose-stack.yml"}, "mocked")
+	stack.Provider = provider
+	strategy := wait.ForLog("Ready to accept connections")
+	stack.WithExposedService("cache", 6379, strategy)
+
+	if err := stack.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer stack.Down(ctx)
+
+	cache := requests["cache"]
+	if len(cache.ExposedPorts) != 1 || cache.ExposedPorts[0] != "6379/tcp" {
+		t.Fatalf("expected the port of the cache to be exposed, got %v", cache.ExposedPorts)
+	}
+	if cache.WaitingFor != strategy {
+		t.Fatalf("expected the cache to be waited for")
+	}
+	if requests["web"].WaitingFor != nil {
+		t.Fatalf("expected the web service not to be waited for")
+	}
+}
+
+func TestLocalDockerComposeWaitsForExposedServices(t *testing.T) {
+	ctx := context.Background()
+	compose := NewLocalDockerCompose([]string{"./testresources/docker-compose.yml"}, "")
+	compose.WithExposedService("nginx", 80, wait.ForHTTP("/"))
+	defer compose.Down(ctx)
+
+	if err := compose.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalDockerComposeSessionLabels(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake compose executable is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "compose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	executable := filepath.Join(dir, "compose")
+	script := `#!/bin/sh
+cat <<'EOF'
+services:
+  web:
+    image: nginx
+networks:
+  default: {}
+  shared:
+    external: true
+volumes:
+  data: {}
+EOF
+`
+	if err := ioutil.WriteFile(executable, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	compose := NewLocalDockerCompose([]string{"./testresources/docker-compose.yml"}, "MyStack")
+	compose.Executable = executable
+
+	path, err := compose.writeSessionLabels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var override map[string]map[string]struct{ Labels map[string]string }
+	if err := yaml.Unmarshal(content, &override); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, resource := range []string{"services/web", "networks/default", "volumes/data"} {
+		parts := strings.Split(resource, "/")
+		entry, ok := override[parts[0]][parts[1]]
+		if !ok {
+			t.Errorf("expected %s to be labelled, got %s", resource, content)
+			continue
+		}
+		if entry.Labels[TestcontainerLabelSessionID] != sessionID.String() {
+			t.Errorf("expected %s to be labelled with the session, got %v", resource, entry.Labels)
+		}
+	}
+	if _, ok := override["networks"]["shared"]; ok {
+		t.Errorf("expected the external network not to be labelled, got %s", content)
+	}
+}
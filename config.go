@@ -0,0 +1,140 @@
+package testcontainers
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pull policies of the images of the containers
+const (
+	PullPolicyMissing = "missing" // pull images which are not present locally, the default
+	PullPolicyAlways  = "always"  // pull images before each container, to get the latest version of their tag
+)
+
+// preferences of the family of the IP address the host of the containers is resolved to
+const (
+	HostIPAny  = ""     // the host as it is, e.g. "localhost", the default
+	HostIPv4   = "ipv4" // an IPv4 address of the host if it has one
+	HostIPv6   = "ipv6" // an IPv6 address of the host if it has one
+	HostIPAuto = "auto" // the first address of the host on which the mapped ports of the container are reachable
+)
+
+// Config is the configuration of the library shared by all the projects of a user, read from
+// ~/.testcontainers.properties. Each property can be overridden by an env variable named after it,
+// e.g. TESTCONTAINERS_RYUK_DISABLED for ryuk.disabled.
+type Config struct {
+	Host           string // docker.host, the Docker daemon to use when DOCKER_HOST is not set
+	RyukImage      string // ryuk.container.image, the image of the reaper
+	RyukDisabled   bool   // ryuk.disabled, whether to run without the reaper
+	RyukPrivileged bool   // ryuk.container.privileged, whether to run the reaper as privileged
+	PullPolicy     string // pull.policy, "missing" or "always"
+	// SSHTunnel is ssh.tunnel, whether to reach the ports mapped on the host of a remote daemon through ssh,
+	// "true" to go through the daemon host, or the ssh://[user@]host[:port] to go through
+	SSHTunnel string
+	// HostIPPreference is host.ip.preference, the family of the IP address Host resolves the host to, on
+	// dual-stack hosts where the name resolves to a family the mapped ports are not reachable on
+	HostIPPreference string
+}
+
+var (
+	config     Config
+	configOnce sync.Once
+)
+
+// ReadConfig reads the configuration of the library, once per process
+func ReadConfig() Config {
+	configOnce.Do(func() {
+		path := ""
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, ".testcontainers.properties")
+		}
+		config = readConfig(path)
+	})
+
+	return config
+}
+
+// readConfig reads the configuration from the properties file at path, ignored if it does not exist,
+// then from the env
+func readConfig(path string) Config {
+	properties := map[string]string{}
+	if path != "" {
+		if p, err := readProperties(path); err == nil {
+			properties = p
+		} else if !os.IsNotExist(err) {
+			Logger.Printf("Could not read %s, it is ignored: %s", path, err)
+		}
+	}
+
+	property := func(key string) string {
+		env := "TESTCONTAINERS_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+		if value, ok := os.LookupEnv(env); ok {
+			return value
+		}
+		return properties[key]
+	}
+	boolProperty := func(key string) bool {
+		value, _ := strconv.ParseBool(property(key))
+		return value
+	}
+
+	c := Config{
+		Host:           property("docker.host"),
+		RyukImage:      property("ryuk.container.image"),
+		RyukDisabled:   boolProperty("ryuk.disabled"),
+		RyukPrivileged: boolProperty("ryuk.container.privileged"),
+		PullPolicy:     property("pull.policy"),
+		SSHTunnel:      property("ssh.tunnel"),
+
+		HostIPPreference: property("host.ip.preference"),
+	}
+
+	switch c.PullPolicy {
+	case PullPolicyMissing, PullPolicyAlways:
+	case "":
+		c.PullPolicy = PullPolicyMissing
+	default:
+		Logger.Printf("Invalid pull.policy %q, using %q", c.PullPolicy, PullPolicyMissing)
+		c.PullPolicy = PullPolicyMissing
+	}
+
+	switch c.HostIPPreference {
+	case HostIPAny, HostIPv4, HostIPv6, HostIPAuto:
+	default:
+		Logger.Printf("Invalid host.ip.preference %q, using the host as it is", c.HostIPPreference)
+		c.HostIPPreference = HostIPAny
+	}
+
+	return c
+}
+
+// readProperties reads a file of key=value or key:value properties, ignoring empty lines and comments
+func readProperties(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			properties[line] = ""
+			continue
+		}
+		properties[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+
+	return properties, scanner.Err()
+}
@@ -0,0 +1,59 @@
+package testcontainers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".testcontainers.properties")
+	content := `# shared by all the projects
+docker.host=tcp://docker.example.com:2375
+ryuk.container.image = registry.example.com/ryuk:0.2.2
+ryuk.disabled=true
+pull.policy: always
+host.ip.preference=ipv6
+`
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := readConfig(path)
+	expected := Config{
+		Host:         "tcp://docker.example.com:2375",
+		RyukImage:    "registry.example.com/ryuk:0.2.2",
+		PullPolicy:   PullPolicyAlways,
+		RyukDisabled: true,
+
+		HostIPPreference: HostIPv6,
+	}
+	if c != expected {
+		t.Fatalf("expected %+v, got %+v", expected, c)
+	}
+
+	if value, exists := os.LookupEnv("TESTCONTAINERS_RYUK_DISABLED"); exists {
+		defer os.Setenv("TESTCONTAINERS_RYUK_DISABLED", value)
+	} else {
+		defer os.Unsetenv("TESTCONTAINERS_RYUK_DISABLED")
+	}
+	os.Setenv("TESTCONTAINERS_RYUK_DISABLED", "false")
+
+	if c := readConfig(path); c.RyukDisabled {
+		t.Fatal("expected the env to override the properties file")
+	}
+}
+
+func TestReadConfigWithoutFile(t *testing.T) {
+	c := readConfig(filepath.Join(os.TempDir(), "missing.properties"))
+	if c.PullPolicy != PullPolicyMissing {
+		t.Fatalf("expected the default pull policy, got '%s'", c.PullPolicy)
+	}
+}
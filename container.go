@@ -2,9 +2,14 @@ package testcontainers
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
+	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 
 	"github.com/docker/go-connections/nat"
 	"github.com/pkg/errors"
@@ -22,11 +27,20 @@ type DeprecatedContainer interface {
 
 // ContainerProvider allows the creation of containers on an arbitrary system
 type ContainerProvider interface {
-	CreateContainer(context.Context, ContainerRequest) (Container, error)   // create a container without starting it
-	CreateFromExistentContainer(context.Context, string) (Container, error) // use existent container
-	RunContainer(context.Context, ContainerRequest) (Container, error)      // create a container and start it
-	ListContainers(context.Context, bool) ([]Container, error)              // list containers
-	ContainerExists(context.Context, string) (bool, error)                  // check if container with given name exists
+	CreateContainer(context.Context, ContainerRequest) (Container, error)        // create a container without starting it
+	CreateFromExistentContainer(context.Context, string) (Container, error)      // use existent container
+	RunContainer(context.Context, ContainerRequest) (Container, error)           // create a container and start it
+	ListContainers(context.Context, bool) ([]Container, error)                   // list containers
+	ContainerExists(context.Context, string) (bool, error)                       // check if container with given name exists
+	FindContainerByName(context.Context, string) (Container, error)              // get the container with the given name
+	ReuseOrCreateContainer(context.Context, ContainerRequest) (Container, error) // get the container with the name of the request, or create it
+}
+
+// GenericProvider represents an abstraction for container and network providers
+type GenericProvider interface {
+	ContainerProvider
+	NetworkProvider
+	VolumeProvider
 }
 
 // Container allows getting info about and controlling a single container instance
@@ -47,25 +61,158 @@ type Container interface {
 	IsRunning(ctx context.Context) (bool, error)                    // is state of container 'running'
 	State(ctx context.Context) (*types.ContainerState, error)       // state of container
 	Image(context.Context) (string, error)                          // get container image
+	ContainerIP(context.Context) (string, error)                    // get the IP address of the container on its default network
+	ContainerIPs(context.Context) (map[string]string, error)        // get the IP addresses of the container, keyed by network name
 	ResetCache(context.Context)                                     // reset internal testcontainers-go cache
+	ConnectToNetwork(context.Context, string, ...string) error      // attach the container to a network, with optional aliases
+	DisconnectFromNetwork(context.Context, string) error            // detach the container from a network
+	CopyToContainer(context.Context, []byte, string, int64) error   // write content to the file at the path in the container, with the mode
+
+	// CopyDirToContainer copies the directory of the host, with its files and their modes, to the path in the
+	// container, e.g. scripts to "/docker-entrypoint-initdb.d"
+	CopyDirToContainer(ctx context.Context, hostDirPath, containerDirPath string) error
+
+	// StopGracefully sends the signal to the container, its stop signal if empty, then kills it if it is
+	// still running once the grace period elapses
+	StopGracefully(context.Context, string, time.Duration) (StopResult, error)
+	// Kill sends the signal to the process of the container, SIGKILL if empty, e.g. SIGHUP
+	Kill(ctx context.Context, signal string) error
+	// Restart stops the container, then starts it again and returns once it is ready, as when it was started
+	Restart(context.Context) error
+	// Pause freezes the processes of the container, which keeps its ports but stops responding, until Unpause
+	Pause(context.Context) error
+	// Unpause resumes the processes of the container frozen by Pause
+	Unpause(context.Context) error
+	// Clone starts a copy of the container, named after the string or generated if empty, with its current
+	// filesystem, config, ports and networks
+	Clone(context.Context, string) (Container, error)
+
+	// HTTPEndpoint gets the base URL of the HTTP server listening on the port, e.g. "http://localhost:32768/"
+	HTTPEndpoint(context.Context, nat.Port) (*url.URL, error)
+	// HTTPClientFor creates a client of the HTTP server listening on the port, over TLS with the config
+	// if not nil, which resolves the URLs without a host against its base URL, e.g. client.Get("/health")
+	HTTPClientFor(context.Context, nat.Port, *tls.Config) (*http.Client, error)
+
+	// Exec runs the command in the started container, e.g. []string{"redis-cli", "flushall"}, and returns once it
+	// exits with its exit code and its output, stdout and stderr combined
+	Exec(ctx context.Context, cmd []string) (int, io.Reader, error)
+
+	FileExists(context.Context, string) (bool, error) // check whether a file or a directory exists at the path in the container
+	ReadFile(context.Context, string) ([]byte, error) // read the content of the file at the path in the container
+	Glob(context.Context, string) ([]string, error)   // get the paths in the container matching the absolute pattern
+}
+
+// StopResult tells how a container stopped by StopGracefully exited
+type StopResult struct {
+	Killed   bool  // whether the container was killed once the grace period elapsed, rather than exiting on the signal
+	ExitCode int64 // the exit code of the container, e.g. 137 when killed
+}
+
+// ContainerFile is a file or a directory of the host copied into the container before it is started
+type ContainerFile struct {
+	HostFilePath      string // the file or the directory of the host to copy
+	ContainerFilePath string // the absolute path of the copy in the container, the parent directory must exist
+	FileMode          int64  // the mode of the copy of a file, e.g. 0644, the one of the file of the host if 0
+}
+
+// FromDockerfile represents the parameters needed to build an image from a Dockerfile
+// rather than using a pre-built one
+type FromDockerfile struct {
+	Context    string             // the path to the context of the docker build
+	Dockerfile string             // the path from the context to the Dockerfile for the image, defaults to "Dockerfile"
+	BuildArgs  map[string]*string // enable user to pass build args to docker daemon
 }
 
 // ContainerRequest represents the parameters used to get a running container
 type ContainerRequest struct {
-	Image        string
-	Env          map[string]string
-	ExposedPorts []string // allow specifying protocol info
-	Cmd          string
-	Labels       map[string]string
-	BindMounts   map[string]string
-	RegistryCred string
-	WaitingFor   wait.Strategy
-	Name         string // for specifying container name
-	Privileged   bool   // for starting privileged container
-	Entrypoint   []string
-	DontRemove   bool
-
-	SkipReaper bool // indicates whether we skip setting up a reaper for this
+	FromDockerfile
+	Image          string
+	Env            map[string]string
+	ExposedPorts   []string // allow specifying protocol info
+	Cmd            string
+	Command        []string // the command as a list of arguments, used rather than Cmd when set, e.g. for arguments with spaces
+	Labels         map[string]string
+	BindMounts     map[string]string
+	VolumeMounts   map[string]string // named volumes to mount, keyed by volume name
+	RegistryCred   string
+	WaitingFor     wait.Strategy
+	Name           string                // for specifying container name
+	Privileged     bool                  // for starting privileged container
+	CapAdd         []string              // kernel capabilities to add to the container, e.g. NET_ADMIN
+	NetworkMode    container.NetworkMode // e.g. "host" or "container:<id>", ignored when Networks are set
+	Entrypoint     []string
+	DontRemove     bool                // keep the container once it stops rather than auto removing it, it is reaped anyway unless SkipReaper
+	Networks       []string            // names of the networks to attach the container to
+	NetworkIPs     map[string]string   // static IP addresses of the container, keyed by network name
+	NetworkAliases map[string][]string // aliases of the container, keyed by network name
+	SessionNetwork bool                // attach the container to the network shared by the session, with its Name as alias, generated if empty
+
+	// Files are copied into the container once it is created, before it is started. Unlike BindMounts, they
+	// work with a remote daemon, which cannot mount the paths of the host running the tests.
+	Files []ContainerFile
+
+	// HealthCheck is the healthcheck of the container, overriding the one of the image
+	HealthCheck *container.HealthConfig
+
+	// HostAccessPorts are ports of the host, e.g. of a server started by the test, that the container
+	// needs to call back into. When set, the host is reachable from the container as HostInternal.
+	// Servers must listen on all interfaces, not only on localhost, to be reachable. The ports are
+	// checked when the container is created, a port nothing listens on yet is reported in the logs.
+	HostAccessPorts []int
+
+	// RetryPolicy is how the operations which may fail transiently are retried, DefaultRetryPolicy if nil
+	RetryPolicy *RetryPolicy
+
+	// LogConsumers consume the output of the container once it is started, until it stops, see FollowLogs
+	LogConsumers []LogConsumer
+
+	// LogArchiveDir is the directory where the full logs of the container are written if it fails to get
+	// ready or its test fails, LogArchiveDir of the package if empty
+	LogArchiveDir string
+
+	// ExpandEnv replaces the ${VAR} and ${VAR:-default} references in the Image, the values of the Env, the Cmd,
+	// the Command and the paths of the mounts with the env of the host when the container is created by
+	// GenericContainer, so that the same request works on the machines of developers and on CI
+	ExpandEnv bool
+
+	// HostPortRetries is how many times the container is recreated, with the next free ports of the host, when
+	// a fixed port of the host it binds, e.g. 8080 of "8080:80/tcp", is already allocated. Not retried if 0.
+	HostPortRetries int
+
+	// PreTerminateHooks are called in order just before the container is terminated, e.g. to collect its logs
+	// or the files it wrote, see CollectLogs and CollectFiles. Their errors are logged, it is terminated anyway.
+	PreTerminateHooks []ContainerHook
+
+	SkipReaper bool   // indicates whether we skip setting up a reaper for this
+	SessionID  string // the session the container is labelled and reaped with, the session of the process if empty
+}
+
+// ShouldBuildImage returns true if the request asks for an image to be built from a Dockerfile
+func (c *ContainerRequest) ShouldBuildImage() bool {
+	return c.FromDockerfile.Context != ""
+}
+
+// GetContext retrieves the docker build context as a tar archive
+func (c *ContainerRequest) GetContext() (io.Reader, error) {
+	if c.FromDockerfile.Context == "" {
+		return nil, errors.New("you must specify a build context")
+	}
+
+	buildContext, err := tarDir(c.FromDockerfile.Context)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create build context")
+	}
+
+	return buildContext, nil
+}
+
+// GetDockerfile returns the Dockerfile path relative to the build context, defaulting to "Dockerfile"
+func (c *ContainerRequest) GetDockerfile() string {
+	if c.FromDockerfile.Dockerfile == "" {
+		return "Dockerfile"
+	}
+
+	return c.FromDockerfile.Dockerfile
 }
 
 // ProviderType is an enum for the possible providers
@@ -77,13 +224,20 @@ const (
 )
 
 // GetProvider provides the provider implementation for a certain type
-func (t ProviderType) GetProvider() (ContainerProvider, error) {
+func (t ProviderType) GetProvider() (GenericProvider, error) {
 	switch t {
 	case ProviderDocker:
 		provider, err := NewDockerProvider()
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create Docker provider")
 		}
+		// fail early with the details of the setup, rather than with the first request to the daemon
+		if _, err := provider.Health(context.Background()); err != nil {
+			return nil, err
+		}
+		if err := provider.requireAPIVersion(MinimumAPIVersion, "testcontainers"); err != nil {
+			return nil, err
+		}
 		return provider, nil
 	}
 	return nil, errors.New("unknown provider")
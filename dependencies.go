@@ -0,0 +1,155 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// StartWithDependencies starts the containers of the requests, and of the requests they depend on, see
+// GenericContainerRequest.DependsOn. A container is started once the containers it depends on are started
+// and ready, the independent ones concurrently. The containers are attached to the session network, where
+// they reach each other by the Name of their request, unless their request sets a NetworkMode.
+// The containers are returned by request, including the ones of the dependencies which are not among
+// the requests. If a container fails, the ones depending on it are not started, and the error is a
+// ParallelContainersError reporting the failed requests, the containers started being returned to be
+// terminated.
+func StartWithDependencies(ctx context.Context, reqs ...*GenericContainerRequest) (map[*GenericContainerRequest]Container, error) {
+	order, err := dependencyOrder(reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		c    Container
+		err  error
+		done chan struct{}
+	}
+	results := make(map[*GenericContainerRequest]*result, len(order))
+	for _, req := range order {
+		results[req] = &result{done: make(chan struct{})}
+	}
+
+	for _, req := range order {
+		go func(req *GenericContainerRequest) {
+			r := results[req]
+			defer close(r.done)
+
+			for _, dep := range req.dependencies(order) {
+				<-results[dep].done
+				if results[dep].err != nil {
+					r.err = fmt.Errorf("dependency %s failed", dep.describe())
+					return
+				}
+			}
+
+			started := *req
+			started.DependsOn = nil
+			started.DependsOnNames = nil
+			started.Started = true
+			if started.NetworkMode == "" {
+				started.SessionNetwork = true
+			}
+			r.c, r.err = GenericContainer(ctx, started)
+		}(req)
+	}
+
+	containers := make(map[*GenericContainerRequest]Container, len(order))
+	failures := ParallelContainersError{}
+	for _, req := range order {
+		r := results[req]
+		<-r.done
+		if r.c != nil {
+			containers[req] = r.c
+		}
+		if r.err != nil {
+			failures.Errors = append(failures.Errors, ParallelContainersRequestError{Request: *req, Error: r.err})
+		}
+	}
+	if len(failures.Errors) > 0 {
+		return containers, failures
+	}
+
+	return containers, nil
+}
+
+// dependencyOrder gets the requests and the ones they depend on, transitively, each one after its
+// dependencies, and fails if the dependencies are not found or are cyclic
+func dependencyOrder(reqs []*GenericContainerRequest) ([]*GenericContainerRequest, error) {
+	var order []*GenericContainerRequest
+	visited := map[*GenericContainerRequest]bool{}
+	visiting := map[*GenericContainerRequest]bool{}
+	var path []string
+
+	var visit func(req *GenericContainerRequest) error
+	visit = func(req *GenericContainerRequest) error {
+		if visited[req] {
+			return nil
+		}
+		path = append(path, req.describe())
+		if visiting[req] {
+			return fmt.Errorf("cyclic dependencies: %s", strings.Join(path, " -> "))
+		}
+		visiting[req] = true
+
+		for _, name := range req.DependsOnNames {
+			if findRequest(reqs, name) == nil {
+				return fmt.Errorf("%s depends on '%s', which is not the name of a request", req.describe(), name)
+			}
+		}
+		for _, dep := range req.dependencies(reqs) {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		visited[req] = true
+		path = path[:len(path)-1]
+		order = append(order, req)
+		return nil
+	}
+
+	for _, req := range reqs {
+		if req == nil {
+			return nil, errors.New("nil request")
+		}
+		if err := visit(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// dependencies gets the requests the request depends on, by reference or by the name of one of the requests
+func (r *GenericContainerRequest) dependencies(reqs []*GenericContainerRequest) []*GenericContainerRequest {
+	deps := append([]*GenericContainerRequest{}, r.DependsOn...)
+	for _, name := range r.DependsOnNames {
+		if dep := findRequest(reqs, name); dep != nil {
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
+
+func findRequest(reqs []*GenericContainerRequest, name string) *GenericContainerRequest {
+	for _, req := range reqs {
+		if req != nil && req.Name == name {
+			return req
+		}
+	}
+
+	return nil
+}
+
+// describe names the request in errors, by the name of its container or its image
+func (r *GenericContainerRequest) describe() string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	return r.Image
+}
@@ -0,0 +1,91 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStartWithDependencies(t *testing.T) {
+	var mutex sync.Mutex
+	var created []string
+	provider := NewProviderMock()
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		created = append(created, req.Image)
+		if req.Image == "broken" {
+			return errors.New("no such image")
+		}
+		return nil
+	}
+	if err := RegisterProvider("dependencies-mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("dependencies-mock")
+	ctx := context.Background()
+
+	request := func(image, name string) *GenericContainerRequest {
+		return &GenericContainerRequest{
+			ContainerRequest: ContainerRequest{Image: image, Name: name},
+			ProviderName:     "dependencies-mock",
+		}
+	}
+	db := request("postgres", "db")
+	cache := request("redis", "cache")
+	migrations := request("flyway", "")
+	migrations.DependsOn = []*GenericContainerRequest{db}
+	app := request("app", "app")
+	app.DependsOn = []*GenericContainerRequest{migrations}
+	app.DependsOnNames = []string{"cache"}
+
+	containers, err := StartWithDependencies(ctx, app, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 4 || containers[db] == nil || containers[migrations] == nil {
+		t.Fatalf("expected the containers of the requests and of their dependencies, got %v", containers)
+	}
+	index := map[string]int{}
+	for i, image := range created {
+		index[image] = i
+	}
+	if index["postgres"] > index["flyway"] || index["flyway"] > index["app"] || index["redis"] > index["app"] {
+		t.Errorf("expected the containers to be started after their dependencies, got %v", created)
+	}
+	if !provider.Containers()[0].Request.SessionNetwork {
+		t.Error("expected the containers to be attached to the session network")
+	}
+	if _, err := GenericContainer(ctx, *app); err == nil {
+		t.Error("expected GenericContainer to refuse a request with dependencies")
+	}
+
+	broken := request("broken", "broken")
+	dependent := request("app", "")
+	dependent.DependsOn = []*GenericContainerRequest{broken}
+	containers, err = StartWithDependencies(ctx, dependent, request("redis", ""))
+	var failures ParallelContainersError
+	if !errors.As(err, &failures) || len(failures.Errors) != 2 || len(containers) != 1 {
+		t.Fatalf("expected the broken container and its dependent to fail, got %v with %v", err, containers)
+	}
+	if !strings.Contains(failures.Errors[1].Error.Error(), "dependency broken failed") {
+		t.Errorf("expected the dependent to report its dependency, got %s", failures.Errors[1].Error)
+	}
+}
+
+func TestDependencyOrderErrors(t *testing.T) {
+	a := &GenericContainerRequest{ContainerRequest: ContainerRequest{Image: "a", Name: "a"}}
+	b := &GenericContainerRequest{ContainerRequest: ContainerRequest{Image: "b", Name: "b"}}
+	a.DependsOn = []*GenericContainerRequest{b}
+	b.DependsOnNames = []string{"a"}
+	if _, err := dependencyOrder([]*GenericContainerRequest{a, b}); err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("expected the cycle to be reported, got %v", err)
+	}
+
+	c := &GenericContainerRequest{ContainerRequest: ContainerRequest{Image: "c"}, DependsOnNames: []string{"missing"}}
+	if _, err := dependencyOrder([]*GenericContainerRequest{c}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected the unknown name to be reported, got %v", err)
+	}
+}
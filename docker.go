@@ -1,20 +1,32 @@
 package testcontainers
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"path"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/cenkalti/backoff"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 
 	"github.com/pkg/errors"
@@ -26,18 +38,40 @@ import (
 // Implement interfaces
 var _ Container = (*DockerContainer)(nil)
 
+// the network shared by the containers of the session requesting it, created on first use
+var (
+	sessionNetworkNames = make(map[string]string) // by daemon host
+	sessionNetworkMutex sync.Mutex
+)
+
+// SessionNetworkName is the name of the network shared by the containers requesting the SessionNetwork, when
+// the test processes of several packages share it rather than each creating its own. The network is reused,
+// it is created by the first process needing it and left in place. It is read from the TC_SESSION_NETWORK
+// env variable, each session creates its own network if empty.
+var SessionNetworkName = os.Getenv("TC_SESSION_NETWORK")
+
+// HostInternal is the hostname under which the host is reachable from containers requesting HostAccessPorts
+const HostInternal = "host.testcontainers.internal"
+
 // DockerContainer represents a container started using Docker
 type DockerContainer struct {
 	// Container ID from Docker
 	ID         string
 	WaitingFor wait.Strategy
 
-	// Cache to retrieve container infromation without re-fetching them from dockerd
-	raw               *types.ContainerJSON
-	provider          *DockerProvider
-	sessionID         uuid.UUID
-	terminationSignal chan bool
-	skipReaper        bool
+	// Cache to retrieve container infromation without re-fetching them from dockerd, for InspectCacheTTL.
+	// It is guarded by cacheMutex, as a container may be used by concurrent subtests.
+	cacheMutex    sync.Mutex
+	raw           *types.ContainerJSON
+	rawTime       time.Time
+	ports         nat.PortMap // the ports mapped once the container was started and ready
+	provider      *DockerProvider
+	sessionID     string
+	skipReaper    bool
+	retryPolicy   *RetryPolicy // the retry policy of the request, the default one if nil
+	logArchiveDir string       // where the logs are archived if the container fails, LogArchiveDir if empty
+	logConsumers  []LogConsumer
+	preTerminate  []ContainerHook // the hooks called before the container is terminated
 }
 
 func (c *DockerContainer) GetContainerID() string {
@@ -80,11 +114,13 @@ func (c *DockerContainer) PortEndpoint(ctx context.Context, port nat.Port, proto
 		protoFull = fmt.Sprintf("%s://", proto)
 	}
 
-	return fmt.Sprintf("%s%s:%s", protoFull, host, outerPort.Port()), nil
+	// JoinHostPort brackets IPv6 addresses
+	return protoFull + net.JoinHostPort(host, outerPort.Port()), nil
 }
 
-// Host gets host (ip or name) of the docker daemon where the container port is exposed
-// Warning: this is based on your Docker host setting. Will fail if using an SSH tunnel
+// Host gets host (ip or name) of the docker daemon where the container port is exposed, localhost
+// when the ports are forwarded through an ssh tunnel, see Config.SSHTunnel. The name is resolved to
+// an address of the family of Config.HostIPPreference if set.
 // You can use the "TC_HOST" env variable to set this yourself
 func (c *DockerContainer) Host(ctx context.Context) (string, error) {
 	host, err := c.provider.daemonHost()
@@ -92,7 +128,9 @@ func (c *DockerContainer) Host(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	return host, nil
+	return resolveHost(ctx, host, ReadConfig().HostIPPreference, func(ip string) bool {
+		return c.reachableOn(ctx, ip)
+	}), nil
 }
 
 // MappedPort gets externally mapped port for a container port
@@ -109,14 +147,26 @@ func (c *DockerContainer) MappedPort(ctx context.Context, port nat.Port) (nat.Po
 		if port.Proto() != "" && k.Proto() != port.Proto() {
 			continue
 		}
+		if c.provider.tunnel != nil && k.Proto() == "tcp" {
+			local, err := c.provider.tunnel.forward(p[0].HostPort)
+			if err != nil {
+				return "", err
+			}
+			return nat.NewPort(k.Proto(), local)
+		}
 		return nat.NewPort(k.Proto(), p[0].HostPort)
 	}
 
 	return "", errors.New("port not found")
 }
 
-// Ports gets the exposed ports for the container.
+// Ports gets the exposed ports for the container. They are read once the container is started and ready,
+// and are not looked up again until it is stopped, see RefreshPorts.
 func (c *DockerContainer) Ports(ctx context.Context) (nat.PortMap, error) {
+	if ports := c.cachedPorts(); ports != nil && !freshInspect(ctx) {
+		return ports, nil
+	}
+
 	inspect, err := c.inspectContainer(ctx)
 	if err != nil {
 		return nil, err
@@ -125,9 +175,41 @@ func (c *DockerContainer) Ports(ctx context.Context) (nat.PortMap, error) {
 	return inspect.NetworkSettings.Ports, nil
 }
 
+// RefreshPorts looks up the mapped ports of the container again, e.g. once it was restarted outside of
+// testcontainers and its ports were mapped again
+func (c *DockerContainer) RefreshPorts(ctx context.Context) error {
+	c.setPorts(nil)
+	inspect, err := c.inspectContainer(WithFreshInspect(ctx))
+	if err != nil {
+		return err
+	}
+	c.setPorts(inspect.NetworkSettings.Ports)
+
+	return nil
+}
+
+// cachedPorts gets the mapped ports read once the container was started, nil if they were not read
+func (c *DockerContainer) cachedPorts() nat.PortMap {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	return c.ports
+}
+
+// setPorts caches the mapped ports of the container, they are not cached if none are mapped yet
+func (c *DockerContainer) setPorts(ports nat.PortMap) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	if len(ports) == 0 {
+		ports = nil
+	}
+	c.ports = ports
+}
+
 // SessionID gets the current session id
 func (c *DockerContainer) SessionID() string {
-	return c.sessionID.String()
+	return c.sessionID
 }
 
 // IsRunning returns true if a container is running
@@ -142,8 +224,7 @@ func (c *DockerContainer) IsRunning(ctx context.Context) (bool, error) {
 
 // State returns current container's state
 func (c *DockerContainer) State(ctx context.Context) (*types.ContainerState, error) {
-	c.ResetCache(ctx)
-	inspect, err := c.inspectContainer(ctx)
+	inspect, err := c.inspectContainer(WithFreshInspect(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -163,15 +244,82 @@ func (c *DockerContainer) Image(ctx context.Context) (string, error) {
 
 // Start will start an already created container
 func (c *DockerContainer) Start(ctx context.Context) error {
-	if err := c.provider.client.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
+	return c.start(ctx, false)
+}
+
+// Restart stops the container, then starts it again and waits for its WaitingFor, so that it is ready again once
+// Restart returns, e.g. to test how the clients of a dependency recover. The log consumers and the strategies
+// reading the logs only get the lines written since the restart, those written before would tell it is ready too
+// early. The random ports of the host the ports of the container are mapped to may change.
+func (c *DockerContainer) Restart(ctx context.Context) error {
+	if err := c.Stop(ctx); err != nil {
 		return err
 	}
 
+	return c.start(ctx, true)
+}
+
+// restartedContainer is a container whose logs are the ones written since it restarted, at the time of the daemon
+type restartedContainer struct {
+	*DockerContainer
+	since string
+}
+
+// Logs gets the logs written since the container restarted
+func (c restartedContainer) Logs(ctx context.Context) (io.ReadCloser, error) {
+	options := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      c.since,
+	}
+
+	return c.provider.client.ContainerLogs(ctx, c.ID, options)
+}
+
+// start starts the container, then follows its logs and waits for it, since the restart if it is restarted
+func (c *DockerContainer) start(ctx context.Context, restarted bool) error {
+	c.setPorts(nil)
+	begin := time.Now()
+	err := retryPolicy(c.retryPolicy).retry(ctx, func() error {
+		return c.provider.throttle(ctx, func() error {
+			return c.provider.client.ContainerStart(ctx, c.ID, types.ContainerStartOptions{})
+		})
+	})
+	if err != nil {
+		return c.startError(err)
+	}
+	started := time.Now()
+	// the ports are only mapped once the container is started
+	c.ResetCache(ctx)
+	recordPhase(c.ID, PhaseStart, started.Sub(begin))
+	var target wait.StrategyTarget = c
+	since := ""
+	if restarted {
+		// the time of the daemon, whose clock may differ from the one of the host
+		if inspect, err := c.inspectContainer(ctx); err == nil && inspect.State != nil {
+			since = inspect.State.StartedAt
+			target = restartedContainer{DockerContainer: c, since: since}
+		}
+	}
+	if len(c.logConsumers) > 0 {
+		go func() {
+			if err := followLogs(context.Background(), c, since, c.logConsumers); err != nil {
+				Logger.Printf("Could not follow the logs of container %s: %s", c.ID, err)
+			}
+		}()
+	}
+
 	// if a Wait Strategy has been specified, wait before returning
 	if c.WaitingFor != nil {
-		if err := c.WaitingFor.WaitUntilReady(ctx, c); err != nil {
-			return err
+		if err := c.WaitingFor.WaitUntilReady(ctx, target); err != nil {
+			return c.startError(err)
 		}
+		recordPhase(c.ID, PhaseWait, time.Since(started))
+	}
+
+	// the ports do not change until the container is stopped, they are looked up again if it failed now
+	if inspect, err := c.inspectContainer(ctx); err == nil {
+		c.setPorts(inspect.NetworkSettings.Ports)
 	}
 
 	return nil
@@ -179,13 +327,112 @@ func (c *DockerContainer) Start(ctx context.Context) error {
 
 // Stop will stop a container
 func (c *DockerContainer) Stop(ctx context.Context) error {
-	if err := c.provider.client.ContainerStop(ctx, c.ID, nil); err != nil {
+	defer c.ResetCache(ctx)
+	c.setPorts(nil)
+	err := c.provider.throttle(ctx, func() error {
+		return c.provider.client.ContainerStop(ctx, c.ID, nil)
+	})
+	if err != nil {
 		return fmt.Errorf("could not stop container '%s': %s", c.ID, err)
 	}
 
 	return nil
 }
 
+// StopGracefully sends the signal to the container, its stop signal, SIGTERM by default, if empty, then waits
+// up to the grace period for it to exit before killing it. The result tells whether it had to be killed,
+// e.g. to test that the shutdown hooks of an application complete in time.
+func (c *DockerContainer) StopGracefully(ctx context.Context, signal string, gracePeriod time.Duration) (StopResult, error) {
+	defer c.ResetCache(ctx)
+	c.setPorts(nil)
+
+	if signal == "" {
+		signal = "SIGTERM"
+		if inspect, err := c.inspectContainer(ctx); err == nil && inspect.Config != nil && inspect.Config.StopSignal != "" {
+			signal = inspect.Config.StopSignal
+		}
+	}
+
+	// waiting before signalling, so that the exit cannot be missed
+	graceCtx, cancel := context.WithTimeout(ctx, gracePeriod)
+	defer cancel()
+	statusC, errC := c.provider.client.ContainerWait(graceCtx, c.ID, container.WaitConditionNotRunning)
+	if err := c.kill(ctx, signal); err != nil {
+		return StopResult{}, err
+	}
+	select {
+	case status := <-statusC:
+		return StopResult{ExitCode: status.StatusCode}, nil
+	case err := <-errC:
+		if graceCtx.Err() == nil || ctx.Err() != nil {
+			return StopResult{}, fmt.Errorf("could not wait for container '%s' to stop: %s", c.ID, err)
+		}
+	}
+
+	// the grace period elapsed
+	statusC, errC = c.provider.client.ContainerWait(ctx, c.ID, container.WaitConditionNotRunning)
+	if err := c.kill(ctx, "SIGKILL"); err != nil {
+		return StopResult{}, err
+	}
+	select {
+	case status := <-statusC:
+		return StopResult{Killed: true, ExitCode: status.StatusCode}, nil
+	case err := <-errC:
+		return StopResult{}, fmt.Errorf("could not wait for container '%s' to be killed: %s", c.ID, err)
+	}
+}
+
+// Pause freezes the processes of the container with the cgroup freezer. The container keeps its state, its
+// network and its ports, connections to it are accepted but nothing responds, e.g. to test the timeouts of the
+// clients of a database hanging mid-transaction. Unpause resumes it; a paused container can be terminated.
+func (c *DockerContainer) Pause(ctx context.Context) error {
+	defer c.ResetCache(ctx)
+	err := c.provider.throttle(ctx, func() error {
+		return c.provider.client.ContainerPause(ctx, c.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("could not pause container '%s': %s", c.ID, err)
+	}
+
+	return nil
+}
+
+// Unpause resumes the processes of the container frozen by Pause
+func (c *DockerContainer) Unpause(ctx context.Context) error {
+	defer c.ResetCache(ctx)
+	err := c.provider.throttle(ctx, func() error {
+		return c.provider.client.ContainerUnpause(ctx, c.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("could not unpause container '%s': %s", c.ID, err)
+	}
+
+	return nil
+}
+
+// Kill sends the signal to the process of the container, SIGKILL if empty, e.g. SIGHUP to test that an application
+// reloads its config. The container stops if the process exits on the signal, see StopGracefully to wait for it.
+func (c *DockerContainer) Kill(ctx context.Context, signal string) error {
+	defer c.ResetCache(ctx)
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+
+	return c.kill(ctx, signal)
+}
+
+// kill sends the signal to the container
+func (c *DockerContainer) kill(ctx context.Context, signal string) error {
+	err := c.provider.throttle(ctx, func() error {
+		return c.provider.client.ContainerKill(ctx, c.ID, signal)
+	})
+	if err != nil {
+		return fmt.Errorf("could not send %s to container '%s': %s", signal, c.ID, err)
+	}
+
+	return nil
+}
+
 // Remove will remove a container
 func (c *DockerContainer) Remove(ctx context.Context, force bool) error {
 	removeOpts := types.ContainerRemoveOptions{
@@ -193,7 +440,10 @@ func (c *DockerContainer) Remove(ctx context.Context, force bool) error {
 		RemoveLinks:   false,
 		Force:         force,
 	}
-	if err := c.provider.client.ContainerRemove(ctx, c.ID, removeOpts); err != nil {
+	err := c.provider.throttle(ctx, func() error {
+		return c.provider.client.ContainerRemove(ctx, c.ID, removeOpts)
+	})
+	if err != nil {
 		return fmt.Errorf("could not remove container '%s': %s", c.ID, err)
 	}
 
@@ -202,26 +452,50 @@ func (c *DockerContainer) Remove(ctx context.Context, force bool) error {
 
 // Terminate is used to kill the container. It is usally triggered by as defer function.
 func (c *DockerContainer) Terminate(ctx context.Context) error {
-	err := c.provider.client.ContainerRemove(ctx, c.GetContainerID(), types.ContainerRemoveOptions{
-		RemoveVolumes: true,
-		Force:         true,
+	runPreTerminateHooks(ctx, c, c.preTerminate)
+
+	err := c.provider.throttle(ctx, func() error {
+		return c.provider.client.ContainerRemove(ctx, c.GetContainerID(), types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+			Force:         true,
+		})
 	})
+	if err == nil {
+		untrackForCleanup(c.ID)
+		if c.provider.tunnel != nil {
+			c.provider.tunnel.close(c.cachedPorts())
+		}
+	}
 
 	return err
 }
 
 func (c *DockerContainer) inspectContainer(ctx context.Context) (*types.ContainerJSON, error) {
-	if c.raw != nil {
-		return c.raw, nil
+	c.cacheMutex.Lock()
+	if c.raw != nil && !freshInspect(ctx) && time.Since(c.rawTime) < InspectCacheTTL {
+		raw := c.raw
+		c.cacheMutex.Unlock()
+		return raw, nil
 	}
+	c.cacheMutex.Unlock()
 
-	inspect, err := c.provider.client.ContainerInspect(ctx, c.ID)
+	var inspect types.ContainerJSON
+	err := c.provider.throttle(ctx, func() error {
+		var err error
+		inspect, err = c.provider.client.ContainerInspect(ctx, c.ID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	// the inspection is replaced rather than updated, as callers may still read the previous one
+	c.cacheMutex.Lock()
 	c.raw = &inspect
+	c.rawTime = time.Now()
+	c.cacheMutex.Unlock()
 
-	return c.raw, nil
+	return &inspect, nil
 }
 
 // Logs will fetch both STDOUT and STDERR from the current container. Returns a
@@ -235,6 +509,54 @@ func (c *DockerContainer) Logs(ctx context.Context) (io.ReadCloser, error) {
 	return c.provider.client.ContainerLogs(ctx, c.ID, options)
 }
 
+// ContainerIP gets the IP address of the container on its default network, the network it was created on: the
+// bridge network, or the first of the Networks of its request. Other containers on that network, e.g. the one
+// the test runs in, reach the ports of the container at that address rather than at Host and MappedPort.
+func (c *DockerContainer) ContainerIP(ctx context.Context) (string, error) {
+	inspect, err := c.inspectContainer(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	ip := inspect.NetworkSettings.IPAddress
+	if ip == "" && inspect.HostConfig != nil {
+		if settings, ok := inspect.NetworkSettings.Networks[string(inspect.HostConfig.NetworkMode)]; ok && settings != nil {
+			ip = settings.IPAddress
+		}
+	}
+	if ip == "" {
+		return "", fmt.Errorf("container '%s' has no IP address on its default network, e.g. as it is not running", c.ID)
+	}
+
+	return ip, nil
+}
+
+// ContainerIPs gets the IP addresses of the container on each of its networks, keyed by network name, e.g. to
+// reach it from a container attached to another of its networks than the default one. The IPv6 address is
+// used on the networks without IPv4. The networks where the container has no address, e.g. host, are left out.
+func (c *DockerContainer) ContainerIPs(ctx context.Context) (map[string]string, error) {
+	inspect, err := c.inspectContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := map[string]string{}
+	for name, settings := range inspect.NetworkSettings.Networks {
+		if settings == nil {
+			continue
+		}
+		ip := settings.IPAddress
+		if ip == "" {
+			ip = settings.GlobalIPv6Address
+		}
+		if ip != "" {
+			ips[name] = ip
+		}
+	}
+
+	return ips, nil
+}
+
 // Name gets the name of the container.
 func (c *DockerContainer) Name(ctx context.Context) (string, error) {
 	inspect, err := c.inspectContainer(ctx)
@@ -247,215 +569,1212 @@ func (c *DockerContainer) Name(ctx context.Context) (string, error) {
 
 // ResetCache sets struct field raw to nil
 func (c *DockerContainer) ResetCache(ctx context.Context) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
 	c.raw = nil
 }
 
-// DockerProvider implements the ContainerProvider interface
-type DockerProvider struct {
-	client    *client.Client
-	hostCache string
+// ConnectToNetwork attaches the running container to the given network, reachable under the given aliases
+// on that network only, e.g. to heal a network partition simulated with DisconnectFromNetwork
+func (c *DockerContainer) ConnectToNetwork(ctx context.Context, networkName string, aliases ...string) error {
+	settings := &network.EndpointSettings{
+		Aliases: aliases,
+	}
+	if err := c.provider.client.NetworkConnect(ctx, networkName, c.ID, settings); err != nil {
+		return fmt.Errorf("could not connect container '%s' to network '%s': %s", c.ID, networkName, err)
+	}
+	c.ResetCache(ctx)
+
+	return nil
 }
 
-var _ ContainerProvider = (*DockerProvider)(nil)
+// DisconnectFromNetwork detaches the running container from the given network without stopping it
+func (c *DockerContainer) DisconnectFromNetwork(ctx context.Context, networkName string) error {
+	if err := c.provider.client.NetworkDisconnect(ctx, networkName, c.ID, false); err != nil {
+		return fmt.Errorf("could not disconnect container '%s' from network '%s': %s", c.ID, networkName, err)
+	}
+	c.ResetCache(ctx)
 
-// NewDockerProvider creates a Docker provider with the EnvClient
-func NewDockerProvider() (*DockerProvider, error) {
-	client, err := client.NewEnvClient()
+	return nil
+}
+
+// CopyToContainer writes the content to the file at the given path of the container, with the given mode,
+// creating it or replacing it. The directory of the file must exist. It works whether the container is
+// started or not, e.g. to provide a file the container waits for once it is started.
+func (c *DockerContainer) CopyToContainer(ctx context.Context, content []byte, containerFilePath string, fileMode int64) error {
+	archive, err := tarFile(path.Base(containerFilePath), content, fileMode)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	client.NegotiateAPIVersion(context.Background())
-	p := &DockerProvider{
-		client: client,
+	err = c.provider.client.CopyToContainer(ctx, c.ID, path.Dir(containerFilePath), archive, types.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("could not copy file to '%s' in container '%s': %s", containerFilePath, c.ID, err)
 	}
 
-	return p, nil
+	return nil
 }
 
-// CreateContainer fulfills a request for a container without starting it
-func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerRequest) (Container, error) {
-	exposedPortSet, exposedPortMap, err := nat.ParsePortSpecs(req.ExposedPorts)
+// CopyDirToContainer copies the directory of the host to the path of the container, started or not, along with
+// its files, subdirectories and symbolic links, their modes preserved. Files already at the path are kept unless
+// replaced. The parent directory of the path must exist. The archive is streamed, the directory may be large.
+func (c *DockerContainer) CopyDirToContainer(ctx context.Context, hostDirPath, containerDirPath string) error {
+	fi, err := os.Stat(hostDirPath)
 	if err != nil {
-		return nil, err
-	}
-
-	env := []string{}
-	for envKey, envVar := range req.Env {
-		env = append(env, envKey+"="+envVar)
+		return fmt.Errorf("could not copy directory '%s' to container '%s': %s", hostDirPath, c.ID, err)
 	}
-
-	if req.Labels == nil {
-		req.Labels = make(map[string]string)
+	if !fi.IsDir() {
+		return fmt.Errorf("could not copy directory '%s' to container '%s': not a directory", hostDirPath, c.ID)
 	}
 
-	sessionID := uuid.NewV4()
-
-	var termSignal chan bool
-	if !req.SkipReaper {
-		r, err := NewReaper(ctx, sessionID.String(), p)
-		if err != nil {
-			return nil, errors.Wrap(err, "creating reaper failed")
-		}
-		termSignal, err = r.Connect()
-		if err != nil {
-			return nil, errors.Wrap(err, "connecting to reaper failed")
-		}
-		for k, v := range r.Labels() {
-			if _, ok := req.Labels[k]; !ok {
-				req.Labels[k] = v
-			}
-		}
-	}
+	containerDirPath = path.Clean(containerDirPath)
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeTarDir(writer, hostDirPath, path.Base(containerDirPath)))
+	}()
+	// unblocks the archive if the daemon fails before reading it through
+	defer reader.Close()
 
-	dockerInput := &container.Config{
-		Image:        req.Image,
-		Env:          env,
-		ExposedPorts: exposedPortSet,
-		Labels:       req.Labels,
+	err = c.provider.client.CopyToContainer(ctx, c.ID, path.Dir(containerDirPath), reader, types.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("could not copy directory '%s' to '%s' in container '%s': %s", hostDirPath, containerDirPath, c.ID, err)
 	}
 
-	if req.Cmd != "" {
-		dockerInput.Cmd = strings.Split(req.Cmd, " ")
-	}
+	return nil
+}
 
-	if len(req.Entrypoint) > 0 {
-		dockerInput.Entrypoint = req.Entrypoint
+// Exec runs the command in the started container, as the user of the container and in its working directory,
+// and returns once the command exits with its exit code and its output, stdout and stderr interleaved as written.
+// A command which fails is not an error, only a command which cannot be run, e.g. as the container is stopped.
+func (c *DockerContainer) Exec(ctx context.Context, cmd []string) (int, io.Reader, error) {
+	if len(cmd) == 0 {
+		return 0, nil, fmt.Errorf("could not exec in container '%s': the command is empty", c.ID)
 	}
 
-	_, _, err = p.client.ImageInspectWithRaw(ctx, req.Image)
+	var execution types.IDResponse
+	err := c.provider.throttle(ctx, func() error {
+		var err error
+		execution, err = c.provider.client.ContainerExecCreate(ctx, c.ID, types.ExecConfig{
+			Cmd:          cmd,
+			AttachStdout: true,
+			AttachStderr: true,
+		})
+		return err
+	})
 	if err != nil {
-		if client.IsErrNotFound(err) {
-			pullOpt := types.ImagePullOptions{}
-			if req.RegistryCred != "" {
-				pullOpt.RegistryAuth = req.RegistryCred
-			}
-			var pull io.ReadCloser
-			err := backoff.Retry(func() error {
-				var err error
-				pull, err = p.client.ImagePull(ctx, req.Image, pullOpt)
-				return err
-			}, backoff.NewExponentialBackOff())
-			if err != nil {
-				return nil, err
-			}
-			defer pull.Close()
-
-			// download of docker image finishes at EOF of the pull request
-			_, err = ioutil.ReadAll(pull)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, err
-		}
+		return 0, nil, fmt.Errorf("could not exec '%s' in container '%s': %s", strings.Join(cmd, " "), c.ID, err)
 	}
 
-	// prepare mounts
-	bindMounts := []mount.Mount{}
-	for hostPath, innerPath := range req.BindMounts {
-		bindMounts = append(bindMounts, mount.Mount{
-			Type:   mount.TypeBind,
-			Source: hostPath,
-			Target: innerPath,
-		})
+	hijacked, err := c.provider.client.ContainerExecAttach(ctx, execution.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not exec '%s' in container '%s': %s", strings.Join(cmd, " "), c.ID, err)
 	}
+	defer hijacked.Close()
 
-	hostConfig := &container.HostConfig{
-		PortBindings: exposedPortMap,
-		Mounts:       bindMounts,
-		AutoRemove:   !req.DontRemove,
-		Privileged:   req.Privileged,
+	// the output is complete once the stream ends, when the command exits
+	output := &bytes.Buffer{}
+	if _, err := stdcopy.StdCopy(output, output, hijacked.Reader); err != nil {
+		return 0, nil, fmt.Errorf("could not read the output of '%s' in container '%s': %s", strings.Join(cmd, " "), c.ID, err)
 	}
 
-	resp, err := p.client.ContainerCreate(ctx, dockerInput, hostConfig, nil, req.Name)
+	inspect, err := c.provider.client.ContainerExecInspect(ctx, execution.ID)
 	if err != nil {
-		return nil, err
+		return 0, nil, fmt.Errorf("could not get the exit code of '%s' in container '%s': %s", strings.Join(cmd, " "), c.ID, err)
 	}
 
-	c := &DockerContainer{
-		ID:                resp.ID,
-		WaitingFor:        req.WaitingFor,
-		sessionID:         sessionID,
-		provider:          p,
-		terminationSignal: termSignal,
-		skipReaper:        req.SkipReaper,
-	}
+	return inspect.ExitCode, output, nil
+}
 
-	return c, nil
+// HTTPEndpoint gets the base URL of the HTTP server of the container listening on the port,
+// e.g. "http://localhost:32768/", IPv6 hosts being bracketed
+func (c *DockerContainer) HTTPEndpoint(ctx context.Context, port nat.Port) (*url.URL, error) {
+	return httpEndpoint(ctx, c, port, "http")
 }
 
-// ListContainers returns current existent containers
-func (p *DockerProvider) ListContainers(ctx context.Context, all bool) ([]Container, error) {
-	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: all})
-	if err != nil {
-		return nil, fmt.Errorf("error while trying to list containers: %s", err)
-	}
+// HTTPClientFor creates a client of the HTTP server of the container listening on the port, over TLS with the
+// config if not nil. The URLs of its requests without a host are resolved against the base URL of the server.
+func (c *DockerContainer) HTTPClientFor(ctx context.Context, port nat.Port, tlsConfig *tls.Config) (*http.Client, error) {
+	return httpClientFor(ctx, c, port, tlsConfig)
+}
 
-	result := make([]Container, 0, len(containers))
-	for _, c := range containers {
-		result = append(result, &DockerContainer{ID: c.ID, provider: p})
+// FileExists checks whether a file or a directory exists at the path of the container, started or not
+func (c *DockerContainer) FileExists(ctx context.Context, filePath string) (bool, error) {
+	_, err := c.provider.client.ContainerStatPath(ctx, c.ID, filePath)
+	if client.IsErrNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not check file '%s' in container '%s': %s", filePath, c.ID, err)
 	}
 
-	return result, nil
+	return true, nil
 }
 
-// ContainerExists returns true if container with given name exists
-func (p *DockerProvider) ContainerExists(ctx context.Context, name string) (bool, error) {
-	containers, err := p.ListContainers(ctx, true)
+// ReadFile reads the content of the file at the path of the container, started or not
+func (c *DockerContainer) ReadFile(ctx context.Context, filePath string) ([]byte, error) {
+	archive, stat, err := c.provider.client.CopyFromContainer(ctx, c.ID, filePath)
 	if err != nil {
-		return false, err
+		return nil, fmt.Errorf("could not read file '%s' in container '%s': %s", filePath, c.ID, err)
 	}
-
-	for _, c := range containers {
-		currentName, err := c.Name(ctx)
-		if err != nil {
-			return false, fmt.Errorf("error while trying to get container's name: %s", err)
-		}
-		if currentName == "/"+name {
-			return true, nil
-		}
+	defer archive.Close()
+	if stat.Mode.IsDir() {
+		return nil, fmt.Errorf("could not read file '%s' in container '%s': it is a directory", filePath, c.ID)
 	}
 
-	return false, nil
+	return untarFile(archive)
 }
 
-// CreateFromExistentContainer returns Container interface that uses existent container
-func (p *DockerProvider) CreateFromExistentContainer(ctx context.Context, containerName string) (Container, error) {
-	sessionID := uuid.NewV4()
+// Glob gets the paths of the files and directories of the container matching the absolute pattern, in the
+// syntax of path.Match, e.g. "/var/log/app/*.log", sorted. The files are read from the directory the pattern
+// starts with, which should not be too large, e.g. "/var/log/app" rather than "/" for "/*/log/app/*.log".
+func (c *DockerContainer) Glob(ctx context.Context, pattern string) ([]string, error) {
+	if !path.IsAbs(pattern) {
+		return nil, fmt.Errorf("pattern '%s' is not absolute", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %s", pattern, err)
+	}
 
-	inspect, err := p.client.ContainerInspect(ctx, containerName) // we can use name instead of ID
+	dir := globDir(pattern)
+	archive, _, err := c.provider.client.CopyFromContainer(ctx, c.ID, dir)
+	if client.IsErrNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error while trying to inspect thew container: %s", err)
+		return nil, fmt.Errorf("could not read directory '%s' in container '%s': %s", dir, c.ID, err)
 	}
+	defer archive.Close()
 
-	c := &DockerContainer{
-		ID:        inspect.ID,
-		sessionID: sessionID,
-		provider:  p,
-	}
+	// the paths of the archive are relative to the parent of the directory
+	return globTar(archive, path.Dir(dir), pattern)
+}
 
-	return c, nil
+// DockerNetwork represents a network started using Docker
+type DockerNetwork struct {
+	ID       string // Network ID from Docker
+	Driver   string
+	Name     string
+	provider *DockerProvider
 }
 
-// RunContainer takes a RequestContainer as input and it runs a container via the docker sdk
-func (p *DockerProvider) RunContainer(ctx context.Context, req ContainerRequest) (Container, error) {
-	c, err := p.CreateContainer(ctx, req)
-	if err != nil {
-		return nil, err
+// Remove is used to remove the network. It is usually triggered by as defer function.
+func (n *DockerNetwork) Remove(ctx context.Context) error {
+	if err := n.provider.client.NetworkRemove(ctx, n.ID); err != nil {
+		return fmt.Errorf("could not remove network '%s': %s", n.Name, err)
 	}
+	untrackForCleanup(n.ID)
 
-	if err := c.Start(ctx); err != nil {
+	return nil
+}
+
+// DockerVolume represents a named volume created using Docker
+type DockerVolume struct {
+	Name     string
+	Driver   string
+	provider *DockerProvider
+}
+
+// GetName gets the name of the volume
+func (v *DockerVolume) GetName() string {
+	return v.Name
+}
+
+// Remove is used to remove the volume. It is usually triggered by as defer function.
+func (v *DockerVolume) Remove(ctx context.Context) error {
+	if err := v.provider.client.VolumeRemove(ctx, v.Name, true); err != nil {
+		return fmt.Errorf("could not remove volume '%s': %s", v.Name, err)
+	}
+	untrackForCleanup(v.Name)
+
+	return nil
+}
+
+// DockerProvider implements the ContainerProvider interface
+type DockerProvider struct {
+	client     *client.Client
+	hostMutex  sync.Mutex // guards hostCache, as the provider is shared by containers used concurrently
+	hostCache  string
+	dockerHost string     // the daemon host as configured, which differs from the one of the client for ssh hosts
+	tunnel     *sshTunnel // forwards the mapped ports of a remote daemon host to local ports, nil if not enabled
+
+	externalHost string // the host where the ports are mapped, set with WithExternalHost, inferred if empty
+}
+
+// DockerProviderOption configures a Docker provider
+type DockerProviderOption func(p *DockerProvider)
+
+// WithExternalHost sets the host where the ports mapped by the daemon are reachable, returned by Host,
+// rather than TC_HOST or the host inferred from the daemon host, e.g. for each of two daemons. It is ignored
+// when the ports are forwarded through an ssh tunnel, see Config.SSHTunnel.
+func WithExternalHost(host string) DockerProviderOption {
+	return func(p *DockerProvider) {
+		p.externalHost = host
+	}
+}
+
+var _ ContainerProvider = (*DockerProvider)(nil)
+var _ NetworkProvider = (*DockerProvider)(nil)
+var _ VolumeProvider = (*DockerProvider)(nil)
+var _ ImageProvider = (*DockerProvider)(nil)
+
+// NewDockerProvider creates a Docker provider with the EnvClient
+func NewDockerProvider(opts ...DockerProviderOption) (*DockerProvider, error) {
+	dockerHost, err := resolveDockerHost()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDockerProviderWithHost(dockerHost, opts...)
+}
+
+// NewDockerProviderWithHost creates a Docker provider for the daemon at the given host,
+// in the DOCKER_HOST notation. The rest of the client configuration is read from the env.
+func NewDockerProviderWithHost(dockerHost string, opts ...DockerProviderOption) (*DockerProvider, error) {
+	client, err := newDockerClient(dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnelHost := dockerHost
+	if tunnelHost == "" {
+		tunnelHost = client.DaemonHost()
+	}
+	tunnel, err := newSSHTunnel(tunnelHost, ReadConfig().SSHTunnel)
+	if err != nil {
+		return nil, err
+	}
+
+	client.NegotiateAPIVersion(context.Background())
+	p := &DockerProvider{
+		client:     client,
+		dockerHost: dockerHost,
+		tunnel:     tunnel,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p, nil
+}
+
+// newDockerClient creates a client for the given daemon host, configured from the env otherwise.
+// The Docker client cannot dial ssh:// hosts itself, they are reached through the ssh command instead.
+func newDockerClient(dockerHost string) (*client.Client, error) {
+	opts := []client.Opt{client.FromEnv}
+
+	switch {
+	case strings.HasPrefix(dockerHost, "ssh://"):
+		dialer, err := sshDialer(dockerHost)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid docker host")
+		}
+		opts = append(opts, client.WithHost("http://docker"), client.WithDialContext(dialer))
+	case dockerHost != "":
+		opts = append(opts, client.WithHost(dockerHost))
+	}
+
+	return client.NewClientWithOpts(opts...)
+}
+
+// CreateContainer fulfills a request for a container without starting it
+func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerRequest) (Container, error) {
+	exposedPortSet, exposedPortMap, err := nat.ParsePortSpecs(req.ExposedPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	env := []string{}
+	for envKey, envVar := range req.Env {
+		env = append(env, envKey+"="+envVar)
+	}
+
+	if err := checkHostAccessPorts(req.HostAccessPorts); err != nil {
+		return nil, err
+	}
+
+	if req.Labels == nil {
+		req.Labels = make(map[string]string)
+	}
+
+	session := req.SessionID
+	if session == "" {
+		session = sessionID.String()
+	}
+	reaped := !req.SkipReaper && p.registerWithReaper(ctx, session, req.Labels)
+
+	dockerInput := &container.Config{
+		Image:        req.Image,
+		Env:          env,
+		ExposedPorts: exposedPortSet,
+		Labels:       req.Labels,
+		Healthcheck:  req.HealthCheck,
+	}
+
+	if len(req.Command) > 0 {
+		dockerInput.Cmd = req.Command
+	} else if req.Cmd != "" {
+		dockerInput.Cmd = strings.Split(req.Cmd, " ")
+	}
+
+	if len(req.Entrypoint) > 0 {
+		dockerInput.Entrypoint = req.Entrypoint
+	}
+
+	m := ContainerMetrics{Image: req.Image}
+	if req.ShouldBuildImage() {
+		m.Image = req.FromDockerfile.Context
+		begin := time.Now()
+		tag, err := p.BuildImage(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		m.Build = time.Since(begin)
+		dockerInput.Image = tag
+	} else {
+		shouldPull := ReadConfig().PullPolicy == PullPolicyAlways
+		if !shouldPull {
+			_, _, err = p.client.ImageInspectWithRaw(ctx, req.Image)
+			if err != nil {
+				if !client.IsErrNotFound(err) {
+					return nil, err
+				}
+				shouldPull = true
+			}
+		}
+		if shouldPull {
+			pullOpt := types.ImagePullOptions{}
+			if req.RegistryCred != "" {
+				pullOpt.RegistryAuth = req.RegistryCred
+			}
+			begin := time.Now()
+			if err := p.attemptToPullImage(ctx, req.Image, pullOpt, retryPolicy(req.RetryPolicy)); err != nil {
+				return nil, err
+			}
+			m.Pull = time.Since(begin)
+		}
+	}
+	if err := checkImage(ctx, dockerInput.Image); err != nil {
+		return nil, err
+	}
+
+	// prepare mounts
+	bindMounts := []mount.Mount{}
+	for hostPath, innerPath := range req.BindMounts {
+		bindMounts = append(bindMounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: hostPath,
+			Target: innerPath,
+		})
+	}
+	for volumeName, innerPath := range req.VolumeMounts {
+		bindMounts = append(bindMounts, mount.Mount{
+			Type:   mount.TypeVolume,
+			Source: volumeName,
+			Target: innerPath,
+		})
+	}
+
+	hostConfig := &container.HostConfig{
+		PortBindings: exposedPortMap,
+		Mounts:       bindMounts,
+		AutoRemove:   !req.DontRemove,
+		Privileged:   req.Privileged,
+		CapAdd:       req.CapAdd,
+		NetworkMode:  req.NetworkMode,
+	}
+
+	if req.SessionNetwork {
+		// the name is the alias of the container on the session network
+		if req.Name == "" {
+			req.Name = generateName(req.Image)
+		}
+		networkName, err := p.sessionNetwork(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get session network")
+		}
+		req.Networks = append(req.Networks, networkName)
+		aliases := make(map[string][]string, len(req.NetworkAliases)+1)
+		for k, v := range req.NetworkAliases {
+			aliases[k] = v
+		}
+		aliases[networkName] = append(aliases[networkName], req.Name)
+		req.NetworkAliases = aliases
+	}
+
+	if len(req.HostAccessPorts) > 0 {
+		hostAddress, err := p.hostGatewayAddress(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not expose host ports")
+		}
+		hostConfig.ExtraHosts = append(hostConfig.ExtraHosts, fmt.Sprintf("%s:%s", HostInternal, hostAddress))
+	}
+
+	// attach the container to the first network on creation, so that it is not attached
+	// to the default bridge network, and connect it to the other networks afterwards
+	var networkingConfig *network.NetworkingConfig
+	if len(req.Networks) > 0 {
+		hostConfig.NetworkMode = container.NetworkMode(req.Networks[0])
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				req.Networks[0]: endpointSettings(req, req.Networks[0]),
+			},
+		}
+	}
+
+	begin := time.Now()
+	policy := retryPolicy(req.RetryPolicy)
+	var resp container.ContainerCreateCreatedBody
+	err = policy.retry(ctx, func() error {
+		return p.throttle(ctx, func() error {
+			var err error
+			resp, err = p.client.ContainerCreate(ctx, dockerInput, hostConfig, networkingConfig, req.Name)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !reaped {
+		trackForCleanup(p, containerResource, resp.ID)
+	}
+
+	for i, n := range req.Networks {
+		if i == 0 {
+			continue
+		}
+		err := policy.retry(ctx, func() error {
+			return p.throttle(ctx, func() error {
+				return p.client.NetworkConnect(ctx, n, resp.ID, endpointSettings(req, n))
+			})
+		})
+		if err != nil {
+			p.removeContainer(resp.ID)
+			return nil, fmt.Errorf("could not connect container to network '%s': %s", n, err)
+		}
+	}
+	m.Create = time.Since(begin)
+	m.ContainerID = resp.ID
+	recordMetrics(m)
+
+	c := &DockerContainer{
+		ID:            resp.ID,
+		WaitingFor:    req.WaitingFor,
+		sessionID:     session,
+		provider:      p,
+		skipReaper:    req.SkipReaper,
+		retryPolicy:   req.RetryPolicy,
+		logArchiveDir: req.LogArchiveDir,
+		logConsumers:  req.LogConsumers,
+		preTerminate:  req.PreTerminateHooks,
+	}
+
+	if err := copyFilesToContainer(ctx, c, req.Files); err != nil {
+		p.removeContainer(resp.ID)
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// removeContainer removes a container which could not be set up, so that CreateContainer does not leave
+// behind a container the caller has no handle on. It does not use the context of the request, which may
+// have been cancelled, and the error of the removal is dropped in favour of the one of the set up.
+func (p *DockerProvider) removeContainer(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err := p.throttle(ctx, func() error {
+		return p.client.ContainerRemove(ctx, id, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+	})
+	if err == nil || client.IsErrNotFound(err) {
+		untrackForCleanup(id)
+	}
+}
+
+// endpointSettings returns the settings used to attach the container of the request to the given network
+func endpointSettings(req ContainerRequest, networkName string) *network.EndpointSettings {
+	settings := &network.EndpointSettings{
+		Aliases: req.NetworkAliases[networkName],
+	}
+
+	if ip, ok := req.NetworkIPs[networkName]; ok {
+		settings.IPAMConfig = &network.EndpointIPAMConfig{}
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+			settings.IPAMConfig.IPv6Address = ip
+		} else {
+			settings.IPAMConfig.IPv4Address = ip
+		}
+	}
+
+	return settings
+}
+
+// sessionNetwork gets the name of the network shared by the session, creating it on first use.
+// The network is registered with the reaper, so it is removed once the session ends, unless it is
+// the network named SessionNetworkName, which is reused.
+func (p *DockerProvider) sessionNetwork(ctx context.Context) (string, error) {
+	sessionNetworkMutex.Lock()
+	defer sessionNetworkMutex.Unlock()
+
+	if name, ok := sessionNetworkNames[p.dockerHost]; ok {
+		return name, nil
+	}
+
+	req := NetworkRequest{Name: fmt.Sprintf("testcontainers-%s", uuid.NewV4()), CheckDuplicate: true}
+	if SessionNetworkName != "" {
+		req = NetworkRequest{Name: SessionNetworkName, Reuse: true}
+	}
+	n, err := p.CreateNetwork(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	name := n.(*DockerNetwork).Name
+	sessionNetworkNames[p.dockerHost] = name
+
+	return name, nil
+}
+
+// hostGatewayAddress gets the address under which containers can reach the host running the tests.
+// Daemons supporting it resolve the special "host-gateway" value themselves, including on Docker Desktop.
+// Older daemons get the gateway of the default bridge network, which is the host on Linux.
+func (p *DockerProvider) hostGatewayAddress(ctx context.Context) (string, error) {
+	if p.requireAPIVersion(apiVersionHostGW, "host-gateway") == nil {
+		return "host-gateway", nil
+	}
+
+	bridge, err := p.client.NetworkInspect(ctx, "bridge", types.NetworkInspectOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, config := range bridge.IPAM.Config {
+		if config.Gateway != "" {
+			return config.Gateway, nil
+		}
+	}
+
+	return "", errors.New("could not find the gateway of the bridge network")
+}
+
+// checkHostAccessPorts validates the host ports a container needs to reach, and warns about the ones
+// nothing listens on, which usually means the server of the test is started too late
+func checkHostAccessPorts(ports []int) error {
+	for _, port := range ports {
+		if port <= 0 || port > 65535 {
+			return fmt.Errorf("invalid host access port %d", port)
+		}
+		conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), time.Second)
+		if err != nil {
+			Logger.Printf("Nothing listens on host access port %d yet: %s", port, err)
+			continue
+		}
+		conn.Close()
+	}
+
+	return nil
+}
+
+// ListContainers returns current existent containers
+func (p *DockerProvider) ListContainers(ctx context.Context, all bool) ([]Container, error) {
+	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: all})
+	if err != nil {
+		return nil, fmt.Errorf("error while trying to list containers: %s", err)
+	}
+
+	result := make([]Container, 0, len(containers))
+	for _, c := range containers {
+		result = append(result, &DockerContainer{ID: c.ID, provider: p})
+	}
+
+	return result, nil
+}
+
+// ContainerExists returns true if container with given name exists
+func (p *DockerProvider) ContainerExists(ctx context.Context, name string) (bool, error) {
+	c, err := p.findContainerByName(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	return c != nil, nil
+}
+
+// FindContainerByName gets the container with the given name, including a stopped one
+func (p *DockerProvider) FindContainerByName(ctx context.Context, name string) (Container, error) {
+	c, err := p.findContainerByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("container '%s' not found", name)
+	}
+
+	return &DockerContainer{
+		ID:        c.ID,
+		sessionID: c.Labels[TestcontainerLabelSessionID],
+		provider:  p,
+	}, nil
+}
+
+// findContainerByName lists the container with the given name, nil if there is none. The name filter
+// of the daemon is a regular expression matching parts of the names, so it is anchored, and the names
+// of the containers listed are checked in case the daemon does not support regular expressions.
+func (p *DockerProvider) findContainerByName(ctx context.Context, name string) (*types.Container, error) {
+	name = strings.TrimPrefix(name, "/")
+	f := filters.NewArgs(filters.Arg("name", "^/"+regexp.QuoteMeta(name)+"$"))
+	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("error while trying to list containers: %s", err)
+	}
+
+	for i, c := range containers {
+		for _, n := range c.Names {
+			if n == "/"+name {
+				return &containers[i], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// CreateFromExistentContainer returns Container interface that uses existent container
+func (p *DockerProvider) CreateFromExistentContainer(ctx context.Context, containerName string) (Container, error) {
+	inspect, err := p.client.ContainerInspect(ctx, containerName) // we can use name instead of ID
+	if err != nil {
+		return nil, fmt.Errorf("error while trying to inspect thew container: %s", err)
+	}
+
+	c := &DockerContainer{
+		ID:        inspect.ID,
+		sessionID: inspect.Config.Labels[TestcontainerLabelSessionID],
+		provider:  p,
+	}
+
+	return c, nil
+}
+
+// ReuseOrCreateContainer gets the container defined by the request if it exists, or creates it. The container
+// is found by the name of the request, or by the hash of the request if it has no name: a container of the same
+// name with another definition is replaced. The container is neither reaped nor auto removed.
+func (p *DockerProvider) ReuseOrCreateContainer(ctx context.Context, req ContainerRequest) (Container, error) {
+	if req.ShouldBuildImage() {
+		// the image built for the request cannot be compared with the one of the existing container
+		return nil, errors.New("a container built from a Dockerfile cannot be reused")
+	}
+	req.SkipReaper = true
+	req.DontRemove = true
+	hash := req.Hash()
+	req.Labels = withLabel(req.Labels, TestcontainerLabelHash, hash)
+
+	c, err := p.findReusableContainer(ctx, req, hash)
+	if err != nil || c != nil {
+		return c, err
+	}
+
+	created, err := p.CreateContainer(ctx, req)
+	if err != nil {
+		// another process may have created the container in the meantime
+		if c, findErr := p.findReusableContainer(ctx, req, hash); findErr == nil && c != nil {
+			return c, nil
+		}
+		return nil, err
+	}
+	// the reused container outlives the process, it is not cleaned up in process either
+	untrackForCleanup(created.GetContainerID())
+
+	return created, nil
+}
+
+// findReusableContainer gets the container of the request with the given hash, nil if there is none.
+// A container with the name of the request but another hash is removed.
+func (p *DockerProvider) findReusableContainer(ctx context.Context, req ContainerRequest, hash string) (Container, error) {
+	var id string
+	var labels map[string]string
+	if req.Name != "" {
+		inspect, err := p.client.ContainerInspect(ctx, req.Name)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("could not inspect container '%s': %s", req.Name, err)
+		}
+		if inspect.Config.Labels[TestcontainerLabelHash] != hash {
+			// the definition of the container changed, it is created again
+			err := p.client.ContainerRemove(ctx, inspect.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+			if err != nil && !client.IsErrNotFound(err) {
+				return nil, fmt.Errorf("could not remove outdated container '%s': %s", req.Name, err)
+			}
+			return nil, nil
+		}
+		id, labels = inspect.ID, inspect.Config.Labels
+	} else {
+		f := filters.NewArgs(filters.Arg("label", TestcontainerLabelHash+"="+hash))
+		containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: f})
+		if err != nil {
+			return nil, fmt.Errorf("could not list containers with hash '%s': %s", hash, err)
+		}
+		if len(containers) == 0 {
+			return nil, nil
+		}
+		id, labels = containers[0].ID, containers[0].Labels
+	}
+
+	c := &DockerContainer{
+		ID:         id,
+		WaitingFor: req.WaitingFor,
+		sessionID:  labels[TestcontainerLabelSessionID],
+		provider:   p,
+		skipReaper: true,
+	}
+
+	return c, nil
+}
+
+// RunContainer takes a RequestContainer as input and it runs a container via the docker sdk
+func (p *DockerProvider) RunContainer(ctx context.Context, req ContainerRequest) (Container, error) {
+	c, err := p.CreateContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Start(ctx); err != nil {
 		return c, errors.Wrap(err, "could not start container")
 	}
 
 	return c, nil
 }
 
+// registerWithReaper makes sure the reaper of the session is running and connected,
+// and adds the labels of the session to labels, so that the labelled resource is reaped with the session.
+// Where the reaper is disabled, or cannot be started, only the labels are added and false is returned:
+// the resource is then to be tracked for the in-process cleanup.
+func (p *DockerProvider) registerWithReaper(ctx context.Context, session string, labels map[string]string) bool {
+	for k, v := range (&Reaper{SessionID: session}).Labels() {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	if RuntimeEnvironment().ReaperDisabled {
+		return false
+	}
+
+	r, err := NewReaper(ctx, session, p)
+	if err != nil {
+		Logger.Printf("could not create the reaper, cleaning up in process instead: %s", err)
+		return false
+	}
+	if _, err := r.Connect(); err != nil {
+		Logger.Printf("could not connect to the reaper, cleaning up in process instead: %s", err)
+		return false
+	}
+
+	return true
+}
+
+// BuildImage builds the image described by the FromDockerfile part of the request and returns its tag.
+// The output of each build step is written to the Logger as it arrives, and the output of the
+// failing step is included in the returned error if the build fails.
+func (p *DockerProvider) BuildImage(ctx context.Context, req *ContainerRequest) (string, error) {
+	buildContext, err := req.GetContext()
+	if err != nil {
+		return "", err
+	}
+
+	// the image outlives the container it is built for, so it is registered with the reaper on its own
+	labels := make(map[string]string, len(req.Labels))
+	for k, v := range req.Labels {
+		labels[k] = v
+	}
+	reaped := false
+	if !req.SkipReaper {
+		session := req.SessionID
+		if session == "" {
+			session = sessionID.String()
+		}
+		reaped = p.registerWithReaper(ctx, session, labels)
+	}
+
+	repo := uuid.NewV4()
+	tag := fmt.Sprintf("%s:%s", repo, "latest")
+
+	buildOptions := types.ImageBuildOptions{
+		BuildArgs:   req.FromDockerfile.BuildArgs,
+		Dockerfile:  req.GetDockerfile(),
+		Context:     buildContext,
+		Labels:      labels,
+		Tags:        []string{tag},
+		Remove:      true,
+		ForceRemove: true,
+	}
+
+	resp, err := p.client.ImageBuild(ctx, buildContext, buildOptions)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build image")
+	}
+	defer resp.Body.Close()
+
+	// keep the output of the current step only, so that it can be reported if the step fails
+	step := []string{}
+	err = consumeJSONMessages(ctx, resp.Body, func(msg jsonmessage.JSONMessage) {
+		line := strings.TrimRight(msg.Stream, "\n")
+		if line == "" {
+			return
+		}
+		if strings.HasPrefix(line, "Step ") {
+			step = step[:0]
+		}
+		step = append(step, line)
+		Logger.Printf("%s", line)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build image: %s\n%s", err, strings.Join(step, "\n"))
+	}
+	if !reaped {
+		trackForCleanup(p, imageResource, tag)
+	}
+
+	return tag, nil
+}
+
+// CreateNetwork creates a network with the given parameters
+func (p *DockerProvider) CreateNetwork(ctx context.Context, req NetworkRequest) (Network, error) {
+	if req.Reuse {
+		return p.reuseOrCreateNetwork(ctx, req)
+	}
+	if req.Attachable {
+		if err := p.requireAPIVersion(apiVersionAttachable, "attachable networks"); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Labels == nil {
+		req.Labels = make(map[string]string)
+	}
+
+	reaped := !req.SkipReaper && p.registerWithReaper(ctx, sessionID.String(), req.Labels)
+
+	nc := types.NetworkCreate{
+		Driver:         req.Driver,
+		Options:        req.Options,
+		CheckDuplicate: req.CheckDuplicate,
+		EnableIPv6:     req.EnableIPv6,
+		Internal:       req.Internal,
+		Attachable:     req.Attachable,
+		Labels:         req.Labels,
+		IPAM:           req.IPAM,
+	}
+
+	resp, err := p.client.NetworkCreate(ctx, req.Name, nc)
+	if err != nil {
+		return nil, err
+	}
+	if !reaped {
+		trackForCleanup(p, networkResource, resp.ID)
+	}
+
+	n := &DockerNetwork{
+		ID:       resp.ID,
+		Driver:   req.Driver,
+		Name:     req.Name,
+		provider: p,
+	}
+
+	return n, nil
+}
+
+// reuseOrCreateNetwork gets the network with the name of the request, or creates it unreaped
+func (p *DockerProvider) reuseOrCreateNetwork(ctx context.Context, req NetworkRequest) (Network, error) {
+	if req.Name == "" {
+		return nil, errors.New("a network can only be reused by name")
+	}
+	req.Reuse = false
+	req.SkipReaper = true
+	req.CheckDuplicate = true
+	req.Labels = withLabel(req.Labels, TestcontainerLabel, "true")
+
+	n, err := p.findNetwork(ctx, req.Name)
+	if err != nil || n != nil {
+		return n, err
+	}
+
+	created, err := p.CreateNetwork(ctx, req)
+	if err != nil {
+		// another process may have created the network in the meantime
+		if n, findErr := p.findNetwork(ctx, req.Name); findErr == nil && n != nil {
+			return n, nil
+		}
+		return nil, err
+	}
+	// the reused network outlives the process, it is not cleaned up in process either
+	untrackForCleanup(created.(*DockerNetwork).ID)
+
+	return created, nil
+}
+
+// findNetwork gets the network with the given name, nil if there is none
+func (p *DockerProvider) findNetwork(ctx context.Context, name string) (Network, error) {
+	inspect, err := p.client.NetworkInspect(ctx, name, types.NetworkInspectOptions{})
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not inspect network '%s': %s", name, err)
+	}
+
+	return &DockerNetwork{
+		ID:       inspect.ID,
+		Driver:   inspect.Driver,
+		Name:     inspect.Name,
+		provider: p,
+	}, nil
+}
+
+// CreateVolume creates a named volume with the given parameters
+func (p *DockerProvider) CreateVolume(ctx context.Context, req VolumeRequest) (Volume, error) {
+	if req.Labels == nil {
+		req.Labels = make(map[string]string)
+	}
+
+	reaped := !req.SkipReaper && p.registerWithReaper(ctx, sessionID.String(), req.Labels)
+
+	vc := volumetypes.VolumeCreateBody{
+		Name:       req.Name,
+		Driver:     req.Driver,
+		DriverOpts: req.DriverOpts,
+		Labels:     req.Labels,
+	}
+
+	resp, err := p.client.VolumeCreate(ctx, vc)
+	if err != nil {
+		return nil, err
+	}
+	if !reaped {
+		trackForCleanup(p, volumeResource, resp.Name)
+	}
+
+	v := &DockerVolume{
+		Name:     resp.Name,
+		Driver:   resp.Driver,
+		provider: p,
+	}
+
+	return v, nil
+}
+
+// attemptToPullImage tries to pull the image while respecting the ctx cancellations.
+// The pull is retried with the given policy, an image which is not found is not retried.
+func (p *DockerProvider) attemptToPullImage(ctx context.Context, tag string, pullOpt types.ImagePullOptions, policy RetryPolicy) error {
+	var pull io.ReadCloser
+	err := policy.retry(ctx, func() error {
+		return p.throttle(ctx, func() error {
+			var err error
+			pull, err = p.client.ImagePull(ctx, tag, pullOpt)
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+	defer pull.Close()
+
+	// download of docker image finishes at EOF of the pull request
+	return consumeJSONMessages(ctx, pull, nil)
+}
+
+// consumeJSONMessages decodes the stream of progress messages returned by the daemon
+// one message at a time, handing each of them to fn if it is not nil.
+// It stops as soon as ctx is done or the daemon reports an error in the stream.
+func consumeJSONMessages(ctx context.Context, r io.Reader, fn func(jsonmessage.JSONMessage)) error {
+	dec := json.NewDecoder(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if fn != nil {
+			fn(msg)
+		}
+	}
+}
+
+// PullImage pulls an image from the registry configured for the Docker daemon
+func (p *DockerProvider) PullImage(ctx context.Context, image string) error {
+	return p.attemptToPullImage(ctx, image, types.ImagePullOptions{}, DefaultRetryPolicy)
+}
+
+// PrePull pulls the given images concurrently, so that the pull latency is paid once up front
+// (e.g. from TestMain) rather than by the first test using each image.
+// Images already present on the daemon are not pulled again.
+func (p *DockerProvider) PrePull(ctx context.Context, images ...string) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(images))
+	for i, image := range images {
+		wg.Add(1)
+		go func(i int, image string) {
+			defer wg.Done()
+			_, _, err := p.client.ImageInspectWithRaw(ctx, image)
+			if err == nil {
+				return
+			}
+			if !client.IsErrNotFound(err) {
+				errs[i] = err
+				return
+			}
+			errs[i] = p.PullImage(ctx, image)
+		}(i, image)
+	}
+	wg.Wait()
+
+	failed := []string{}
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", images[i], err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not pre-pull images: %s", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+// ListImages returns the images known to the Docker daemon, one entry per tag
+func (p *DockerProvider) ListImages(ctx context.Context) ([]ImageInfo, error) {
+	images, err := p.client.ImageList(ctx, types.ImageListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error while trying to list images: %s", err)
+	}
+
+	result := make([]ImageInfo, 0, len(images))
+	for _, img := range images {
+		if len(img.RepoTags) == 0 {
+			result = append(result, ImageInfo{ID: img.ID})
+			continue
+		}
+		for _, tag := range img.RepoTags {
+			result = append(result, ImageInfo{ID: img.ID, Name: tag})
+		}
+	}
+
+	return result, nil
+}
+
+// RemoveImage removes an image by name or ID
+func (p *DockerProvider) RemoveImage(ctx context.Context, image string, force bool) error {
+	removeOpts := types.ImageRemoveOptions{
+		Force:         force,
+		PruneChildren: true,
+	}
+	if _, err := p.client.ImageRemove(ctx, image, removeOpts); err != nil {
+		return fmt.Errorf("could not remove image '%s': %s", image, err)
+	}
+	untrackForCleanup(image)
+
+	return nil
+}
+
+// PruneImages removes all unused images labelled with the given session id
+func (p *DockerProvider) PruneImages(ctx context.Context, sessionID string) error {
+	if err := p.requireAPIVersion(apiVersionPrune, "pruning images"); err != nil {
+		return err
+	}
+
+	f := filters.NewArgs(
+		filters.Arg("label", TestcontainerLabelSessionID+"="+sessionID),
+		filters.Arg("dangling", "false"),
+	)
+	if _, err := p.client.ImagesPrune(ctx, f); err != nil {
+		return fmt.Errorf("could not prune images of session '%s': %s", sessionID, err)
+	}
+
+	return nil
+}
+
+// SaveImages writes the given images as a tar archive, in the format of `docker save`, into w
+func (p *DockerProvider) SaveImages(ctx context.Context, w io.Writer, images ...string) error {
+	if len(images) == 0 {
+		return errors.New("no images to save")
+	}
+
+	save, err := p.client.ImageSave(ctx, images)
+	if err != nil {
+		return fmt.Errorf("could not save images %v: %s", images, err)
+	}
+	defer save.Close()
+
+	if _, err := io.Copy(w, save); err != nil {
+		return fmt.Errorf("could not write images %v: %s", images, err)
+	}
+
+	return nil
+}
+
+// LoadImage loads the images from a tar archive, in the format of `docker save`, read from r
+func (p *DockerProvider) LoadImage(ctx context.Context, r io.Reader) error {
+	resp, err := p.client.ImageLoad(ctx, r, true)
+	if err != nil {
+		return fmt.Errorf("could not load images: %s", err)
+	}
+	defer resp.Body.Close()
+
+	// the images are loaded once the response body is consumed, failures are reported in the stream
+	if err := consumeJSONMessages(ctx, resp.Body, nil); err != nil {
+		return fmt.Errorf("could not load images: %s", err)
+	}
+
+	return nil
+}
+
+// CopyFileFromImage reads the file at the path of the image, pulled if missing, without starting a container,
+// e.g. to assert on the default configuration files of an image. The file is copied from a container created,
+// but not started, which is removed right away, so it is not registered with the reaper.
+func (p *DockerProvider) CopyFileFromImage(ctx context.Context, image, filePath string) ([]byte, error) {
+	c, err := p.CreateContainer(ctx, ContainerRequest{
+		Image: image,
+		// the container is not started, the entrypoint only lets images without a command be created
+		Entrypoint: []string{"/bin/true"},
+		SkipReaper: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create container of image '%s' to copy file from: %s", image, err)
+	}
+	defer c.Terminate(ctx)
+
+	return c.ReadFile(ctx, filePath)
+}
+
+// InvalidateHostCache forgets the host of the provider, inferred or read from TC_HOST once, so that it is looked
+// up again, e.g. once TC_HOST changed. It does not change the host set with WithExternalHost.
+func (p *DockerProvider) InvalidateHostCache() {
+	p.hostMutex.Lock()
+	defer p.hostMutex.Unlock()
+	p.hostCache = ""
+}
+
 // daemonHost gets the host or ip of the Docker daemon where ports are exposed on
-// Warning: this is based on your Docker host setting. Will fail if using an SSH tunnel
-// You can use the "TC_HOST" env variable to set this yourself
+// Warning: this is based on your Docker host setting, the ports are forwarded to localhost through an ssh tunnel
+// You can use the "TC_HOST" env variable to set this yourself, or WithExternalHost per provider
 func (p *DockerProvider) daemonHost() (string, error) {
+	if p.tunnel != nil {
+		return "localhost", nil
+	}
+	if p.externalHost != "" {
+		return p.externalHost, nil
+	}
+
+	p.hostMutex.Lock()
+	defer p.hostMutex.Unlock()
+
 	if p.hostCache != "" {
 		return p.hostCache, nil
 	}
@@ -467,16 +1786,21 @@ func (p *DockerProvider) daemonHost() (string, error) {
 	}
 
 	// infer from Docker host
-	url, err := url.Parse(p.client.DaemonHost())
+	dockerHost := p.client.DaemonHost()
+	if p.dockerHost != "" {
+		dockerHost = p.dockerHost
+	}
+	url, err := url.Parse(dockerHost)
 	if err != nil {
 		return "", err
 	}
 
 	switch url.Scheme {
-	case "http", "https", "tcp":
+	case "http", "https", "tcp", "ssh":
+		// mapped ports are exposed on the remote host
 		p.hostCache = url.Hostname()
 	case "unix", "npipe":
-		if inAContainer() {
+		if RuntimeEnvironment().InContainer {
 			ip, err := getGatewayIp()
 			if err != nil {
 				return "", err
@@ -497,6 +1821,10 @@ func inAContainer() bool {
 	if _, err := os.Stat("/.dockerenv"); err == nil {
 		return true
 	}
+	// Podman
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return true
+	}
 	return false
 }
 
@@ -0,0 +1,162 @@
+package testcontainers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// resolveDockerHost resolves the endpoint of the Docker daemon the way the docker CLI does:
+// DOCKER_HOST first, then the docker.host of the configuration, then the endpoint of the current docker context, as selected by DOCKER_CONTEXT
+// or by the docker config file. Contexts are how Docker Desktop, Colima or Rancher Desktop point
+// the CLI to their daemon. TC_DOCKER_SOCKET overrides the context with the path of a socket.
+// Without any of them, the default socket is used if it exists, otherwise the sockets of rootless
+// Docker, Colima, Lima and Podman are probed. An empty host means the default of the Docker client.
+func resolveDockerHost() (string, error) {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return host, nil
+	}
+
+	if host := ReadConfig().Host; host != "" {
+		return host, nil
+	}
+
+	if socket := os.Getenv("TC_DOCKER_SOCKET"); socket != "" {
+		return "unix://" + socket, nil
+	}
+
+	configDir := dockerConfigDir()
+	name, err := currentDockerContext(configDir)
+	if err != nil {
+		return "", err
+	}
+	if name != "" && name != "default" {
+		return dockerContextHost(configDir, name)
+	}
+
+	if socket := discoverDockerSocket(defaultDockerSocket); socket != "" && socket != defaultDockerSocket {
+		return "unix://" + socket, nil
+	}
+
+	return "", nil
+}
+
+// defaultDockerSocket is the socket the Docker client connects to by default
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// dockerSocketCandidates lists the sockets of the Docker compatible daemons commonly installed
+// by developers, probed in order when the default socket does not exist
+func dockerSocketCandidates() []string {
+	candidates := []string{}
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir != "" {
+		// rootless Docker
+		candidates = append(candidates, filepath.Join(runtimeDir, "docker.sock"))
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			filepath.Join(home, ".docker", "run", "docker.sock"), // Docker Desktop
+			filepath.Join(home, ".colima", "docker.sock"),
+			filepath.Join(home, ".colima", "default", "docker.sock"),
+			filepath.Join(home, ".rd", "docker.sock"), // Rancher Desktop
+		)
+		if limaSockets, err := filepath.Glob(filepath.Join(home, ".lima", "*", "sock", "docker.sock")); err == nil {
+			candidates = append(candidates, limaSockets...)
+		}
+	}
+
+	// Podman, rootless then rootful
+	if runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+
+	return candidates
+}
+
+// discoverDockerSocket gets the first existing socket among the given default one and the candidates,
+// empty if none exists. TC_DOCKER_SOCKET can be set to skip the discovery.
+func discoverDockerSocket(defaultSocket string) string {
+	for _, socket := range append([]string{defaultSocket}, dockerSocketCandidates()...) {
+		if isSocket(socket) {
+			return socket
+		}
+	}
+
+	return ""
+}
+
+func isSocket(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode()&os.ModeSocket != 0
+}
+
+// dockerConfigDir gets the directory of the docker CLI configuration, ~/.docker unless DOCKER_CONFIG is set
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// currentDockerContext gets the name of the docker context in use, empty if none is selected
+func currentDockerContext(configDir string) (string, error) {
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name, nil
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "could not read docker config")
+	}
+
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return "", errors.Wrap(err, "could not parse docker config")
+	}
+
+	return config.CurrentContext, nil
+}
+
+// dockerContextHost gets the Docker endpoint of the named context,
+// stored by the docker CLI under contexts/meta/<sha256 of the name>/meta.json
+func dockerContextHost(configDir string, name string) (string, error) {
+	digest := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))
+	b, err := ioutil.ReadFile(filepath.Join(configDir, "contexts", "meta", digest, "meta.json"))
+	if err != nil {
+		return "", errors.Wrapf(err, "could not read docker context '%s'", name)
+	}
+
+	var meta struct {
+		Endpoints map[string]struct {
+			Host string `json:"Host"`
+		} `json:"Endpoints"`
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return "", errors.Wrapf(err, "could not parse docker context '%s'", name)
+	}
+
+	host := meta.Endpoints["docker"].Host
+	if host == "" {
+		return "", fmt.Errorf("docker context '%s' has no docker endpoint", name)
+	}
+
+	return host, nil
+}
@@ -0,0 +1,96 @@
+package testcontainers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveDockerHostFromCurrentContext(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "docker-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(configDir)
+
+	for _, env := range []string{"DOCKER_HOST", "DOCKER_CONTEXT", "DOCKER_CONFIG"} {
+		if value, exists := os.LookupEnv(env); exists {
+			defer os.Setenv(env, value)
+		} else {
+			defer os.Unsetenv(env)
+		}
+		os.Unsetenv(env)
+	}
+	os.Setenv("DOCKER_CONFIG", configDir)
+
+	host, err := resolveDockerHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "" {
+		t.Fatalf("expected the default host without any context, got '%s'", host)
+	}
+
+	metaDir := filepath.Join(configDir, "contexts", "meta", fmt.Sprintf("%x", sha256.Sum256([]byte("colima"))))
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	meta := `{"Name":"colima","Endpoints":{"docker":{"Host":"unix:///Users/me/.colima/default/docker.sock"}}}`
+	if err := ioutil.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext":"colima"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	host, err = resolveDockerHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "unix:///Users/me/.colima/default/docker.sock" {
+		t.Fatalf("expected the host of the current context, got '%s'", host)
+	}
+
+	os.Setenv("DOCKER_HOST", "tcp://docker:2375")
+	host, err = resolveDockerHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "tcp://docker:2375" {
+		t.Fatalf("expected DOCKER_HOST to take precedence, got '%s'", host)
+	}
+}
+
+func TestDiscoverDockerSocket(t *testing.T) {
+	runtimeDir, err := ioutil.TempDir("", "xdg-runtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(runtimeDir)
+
+	if value, exists := os.LookupEnv("XDG_RUNTIME_DIR"); exists {
+		defer os.Setenv("XDG_RUNTIME_DIR", value)
+	} else {
+		defer os.Unsetenv("XDG_RUNTIME_DIR")
+	}
+	os.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	podmanSocket := filepath.Join(runtimeDir, "podman", "podman.sock")
+	if err := os.MkdirAll(filepath.Dir(podmanSocket), 0755); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("unix", podmanSocket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	missingDefault := filepath.Join(runtimeDir, "missing.sock")
+	if socket := discoverDockerSocket(missingDefault); socket != podmanSocket {
+		t.Fatalf("expected the podman socket to be discovered, got '%s'", socket)
+	}
+}
@@ -1,9 +1,20 @@
 package testcontainers
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,6 +25,8 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
@@ -697,3 +710,803 @@ func TestContainerCreationWaitsForLog(t *testing.T) {
 		t.Errorf("error creating table: %+v\n", err)
 	}
 }
+
+func TestPullListAndRemoveImage(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewDockerProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := "alpine:3.10"
+	if err := provider.PullImage(ctx, image); err != nil {
+		t.Fatal(err)
+	}
+
+	images, err := provider.ListImages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, img := range images {
+		if img.Name == image {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected image '%s' to be listed", image)
+	}
+
+	if err := provider.RemoveImage(ctx, image, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveAndLoadImage(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewDockerProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := "alpine:3.10"
+	if err := provider.PullImage(ctx, image); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := provider.SaveImages(ctx, &archive, image); err != nil {
+		t.Fatal(err)
+	}
+	if archive.Len() == 0 {
+		t.Fatal("expected a non-empty image archive")
+	}
+
+	if err := provider.RemoveImage(ctx, image, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.LoadImage(ctx, &archive); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.RemoveImage(ctx, image, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsumeJSONMessagesReportsStreamErrors(t *testing.T) {
+	stream := `{"status":"Pulling from library/alpine","id":"3.10"}
+{"status":"Downloading","id":"abc"}
+{"errorDetail":{"message":"unexpected EOF"},"error":"unexpected EOF"}
+`
+	statuses := []string{}
+	err := consumeJSONMessages(context.Background(), strings.NewReader(stream), func(msg jsonmessage.JSONMessage) {
+		statuses = append(statuses, msg.Status)
+	})
+	if err == nil || err.Error() != "unexpected EOF" {
+		t.Fatalf("expected the error from the stream, got %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 messages before the error, got %d", len(statuses))
+	}
+}
+
+// newFakeDaemonProvider creates a provider for a fake Docker daemon answering with handler,
+// to test how the provider handles the responses of the daemon
+func newFakeDaemonProvider(t *testing.T, handler http.HandlerFunc) *DockerProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := client.NewClientWithOpts(client.WithHost("tcp://"+server.Listener.Addr().String()), client.WithVersion("1.40"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &DockerProvider{client: c, dockerHost: "tcp://" + server.Listener.Addr().String()}
+}
+
+func TestLoadImageReportsStreamErrors(t *testing.T) {
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/images/load") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"errorDetail":{"message":"archive/tar: invalid tar header"},"error":"archive/tar: invalid tar header"}`)
+	})
+
+	err := provider.LoadImage(context.Background(), strings.NewReader("not a tar"))
+	if err == nil || !strings.Contains(err.Error(), "invalid tar header") {
+		t.Fatalf("expected the error from the stream, got %v", err)
+	}
+}
+
+func TestCopyFileFromImage(t *testing.T) {
+	var started, removed bool
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/nginx:alpine/json"):
+			fmt.Fprintln(w, `{"Id":"sha256:nginx"}`)
+		case strings.HasSuffix(r.URL.Path, "/containers/create"):
+			var config struct{ Entrypoint []string }
+			json.NewDecoder(r.Body).Decode(&config)
+			if strings.Join(config.Entrypoint, " ") != "/bin/true" {
+				t.Errorf("expected the entrypoint to be replaced, got %v", config.Entrypoint)
+			}
+			fmt.Fprintln(w, `{"Id":"copied"}`)
+		case strings.HasSuffix(r.URL.Path, "/containers/copied/start"):
+			started = true
+		case strings.HasSuffix(r.URL.Path, "/containers/copied/archive"):
+			if r.URL.Query().Get("path") != "/etc/nginx/nginx.conf" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("X-Docker-Container-Path-Stat", "e30=") // {}
+			tw := tar.NewWriter(w)
+			tw.WriteHeader(&tar.Header{Name: "nginx.conf", Typeflag: tar.TypeReg, Mode: 0644, Size: 17})
+			tw.Write([]byte("worker_processes;"))
+			tw.Close()
+		case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/containers/copied"):
+			removed = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	content, err := provider.CopyFileFromImage(ctx, "nginx:alpine", "/etc/nginx/nginx.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "worker_processes;" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if started || !removed {
+		t.Errorf("expected the container to be removed without being started, started: %t, removed: %t", started, removed)
+	}
+	if _, err := provider.CopyFileFromImage(ctx, "nginx:alpine", "/missing"); err == nil {
+		t.Error("expected a missing file not to be read")
+	}
+}
+
+func TestCopyDirToContainer(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "lib"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "01-schema.sql"), []byte("CREATE TABLE"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "lib", "02-seed.sh"), []byte("#!/bin/sh"), 0755)
+
+	entries := map[string]int64{}
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.HasSuffix(r.URL.Path, "/containers/db/archive") || r.URL.Query().Get("path") != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		tr := tar.NewReader(r.Body)
+		for {
+			header, err := tr.Next()
+			if err != nil {
+				break
+			}
+			entries[header.Name] = header.Mode & 0777
+		}
+	})
+	c := &DockerContainer{ID: "db", provider: provider}
+
+	if err := c.CopyDirToContainer(context.Background(), dir, "/docker-entrypoint-initdb.d/"); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int64{
+		"docker-entrypoint-initdb.d":                0755,
+		"docker-entrypoint-initdb.d/01-schema.sql":  0644,
+		"docker-entrypoint-initdb.d/lib":            0755,
+		"docker-entrypoint-initdb.d/lib/02-seed.sh": 0755,
+	}
+	if fmt.Sprint(entries) != fmt.Sprint(expected) {
+		t.Errorf("expected the entries %v, got %v", expected, entries)
+	}
+
+	if err := c.CopyDirToContainer(context.Background(), filepath.Join(dir, "01-schema.sql"), "/tmp"); err == nil {
+		t.Error("expected a file not to be copied as a directory")
+	}
+}
+
+func TestCreateContainerCopiesFilesBeforeStart(t *testing.T) {
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "app.conf"), []byte("debug = true"), 0600)
+
+	calls := []string{}
+	var mode int64
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "/images/"):
+			fmt.Fprintln(w, `{"Id":"sha256:app"}`)
+		case strings.HasSuffix(r.URL.Path, "/containers/create"):
+			calls = append(calls, "create")
+			fmt.Fprintln(w, `{"Id":"app"}`)
+		case strings.HasSuffix(r.URL.Path, "/containers/app/archive") && r.URL.Query().Get("path") == "/etc/app":
+			calls = append(calls, "copy")
+			header, err := tar.NewReader(r.Body).Next()
+			if err != nil || header.Name != "app.conf" {
+				t.Errorf("unexpected archive %v, %v", header, err)
+			}
+			mode = header.Mode
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	_, err := provider.CreateContainer(context.Background(), ContainerRequest{
+		Image:      "app",
+		SkipReaper: true,
+		Files:      []ContainerFile{{HostFilePath: filepath.Join(dir, "app.conf"), ContainerFilePath: "/etc/app/app.conf"}},
+	})
+	untrackForCleanup("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "create,copy" || mode != 0600 {
+		t.Errorf("expected the file to be copied with its mode once created, got calls %v and mode %o", calls, mode)
+	}
+}
+
+func TestCreateContainerRemovesContainerWhenSetUpFails(t *testing.T) {
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "app.conf"), []byte("debug = true"), 0600)
+
+	tests := []struct {
+		name string
+		req  ContainerRequest
+	}{
+		{
+			name: "files",
+			req:  ContainerRequest{Files: []ContainerFile{{HostFilePath: filepath.Join(dir, "app.conf"), ContainerFilePath: "/etc/app/app.conf"}}},
+		},
+		{
+			name: "networks",
+			req:  ContainerRequest{Networks: []string{"front", "back"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := false
+			provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case strings.Contains(r.URL.Path, "/images/"):
+					fmt.Fprintln(w, `{"Id":"sha256:app"}`)
+				case strings.HasSuffix(r.URL.Path, "/containers/create"):
+					fmt.Fprintln(w, `{"Id":"app"}`)
+				case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/containers/app"):
+					removed = r.URL.Query().Get("force") == "1"
+					w.WriteHeader(http.StatusNoContent)
+				case strings.HasSuffix(r.URL.Path, "/containers/app/archive"):
+					w.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprintln(w, `{"message":"no space left on device"}`)
+				case strings.HasSuffix(r.URL.Path, "/networks/back/connect"):
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprintln(w, `{"message":"network back not found"}`)
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			req := tt.req
+			req.Image = "app"
+			req.SkipReaper = true
+			if _, err := provider.CreateContainer(context.Background(), req); err == nil {
+				t.Fatal("expected the container not to be created")
+			}
+			if !removed {
+				t.Error("expected the container to be removed")
+			}
+			if isTrackedForCleanup("app") {
+				untrackForCleanup("app")
+				t.Error("expected the removed container not to be tracked for clean up")
+			}
+		})
+	}
+}
+
+func TestRestartWaitsOnTheLogsSinceTheRestart(t *testing.T) {
+	calls := []string{}
+	since := ""
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/db/stop"):
+			calls = append(calls, "stop")
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/containers/db/start"):
+			calls = append(calls, "start")
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/containers/db/json"):
+			fmt.Fprintln(w, `{"Id":"db","State":{"Running":true,"StartedAt":"2021-06-01T10:00:00.5Z"},"NetworkSettings":{"Ports":{}}}`)
+		case strings.HasSuffix(r.URL.Path, "/containers/db/logs"):
+			calls = append(calls, "logs")
+			since = r.URL.Query().Get("since")
+			fmt.Fprint(w, "ready to accept connections\n")
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	c := &DockerContainer{
+		ID:         "db",
+		provider:   provider,
+		WaitingFor: wait.ForLog("ready to accept connections").WithPollInterval(10 * time.Millisecond),
+	}
+
+	if err := c.Restart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "stop,start,logs" {
+		t.Errorf("expected the container to be stopped, started and waited for, got %v", calls)
+	}
+	if since != "1622541600.500000000" {
+		t.Errorf("expected only the logs since the restart to be read, got since %q", since)
+	}
+}
+
+func TestContainerIP(t *testing.T) {
+	inspections := map[string]string{
+		"bridged": `{"Id":"bridged","HostConfig":{"NetworkMode":"default"},"NetworkSettings":{"IPAddress":"172.17.0.2",` +
+			`"Networks":{"bridge":{"IPAddress":"172.17.0.2"}}}}`,
+		"attached": `{"Id":"attached","HostConfig":{"NetworkMode":"backend"},"NetworkSettings":{"IPAddress":"",` +
+			`"Networks":{"monitoring":{"IPAddress":"172.19.0.3"},"backend":{"IPAddress":"172.18.0.5"}}}}`,
+		"host": `{"Id":"host","HostConfig":{"NetworkMode":"host"},"NetworkSettings":{"IPAddress":"","Networks":{"host":{"IPAddress":""}}}}`,
+	}
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		for id, inspection := range inspections {
+			if strings.HasSuffix(r.URL.Path, "/containers/"+id+"/json") {
+				fmt.Fprintln(w, inspection)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+
+	for id, expected := range map[string]string{"bridged": "172.17.0.2", "attached": "172.18.0.5"} {
+		c := &DockerContainer{ID: id, provider: provider}
+		ip, err := c.ContainerIP(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ip != expected {
+			t.Errorf("%s: expected the IP address %s, got %s", id, expected, ip)
+		}
+	}
+
+	c := &DockerContainer{ID: "host", provider: provider}
+	if _, err := c.ContainerIP(context.Background()); err == nil {
+		t.Error("expected a container on the network of the host to have no IP address")
+	}
+	if ips, err := c.ContainerIPs(context.Background()); err != nil || len(ips) != 0 {
+		t.Errorf("expected no IP addresses on the network of the host, got %v, %v", ips, err)
+	}
+
+	c = &DockerContainer{ID: "attached", provider: provider}
+	ips, err := c.ContainerIPs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ips) != 2 || ips["backend"] != "172.18.0.5" || ips["monitoring"] != "172.19.0.3" {
+		t.Errorf("unexpected IP addresses %v", ips)
+	}
+}
+
+func TestKill(t *testing.T) {
+	signals := []string{}
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/containers/app/kill") {
+			http.NotFound(w, r)
+			return
+		}
+		signals = append(signals, r.URL.Query().Get("signal"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c := &DockerContainer{ID: "app", provider: provider}
+
+	for _, signal := range []string{"SIGHUP", ""} {
+		if err := c.Kill(context.Background(), signal); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Join(signals, ",") != "SIGHUP,SIGKILL" {
+		t.Errorf("expected SIGHUP then SIGKILL to be sent, got %v", signals)
+	}
+}
+
+func TestPauseAndUnpause(t *testing.T) {
+	calls := []string{}
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/containers/db/pause"):
+			calls = append(calls, "pause")
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/containers/db/unpause"):
+			calls = append(calls, "unpause")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, `{"message":"Container missing is not running"}`, http.StatusConflict)
+		}
+	})
+	ctx := context.Background()
+	c := &DockerContainer{ID: "db", provider: provider}
+
+	if err := c.Pause(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Unpause(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "pause,unpause" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+
+	missing := &DockerContainer{ID: "missing", provider: provider}
+	if err := missing.Pause(ctx); err == nil || !strings.Contains(err.Error(), "is not running") {
+		t.Errorf("expected the error of the daemon, got %v", err)
+	}
+}
+
+func TestExec(t *testing.T) {
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/redis/exec"):
+			var config types.ExecConfig
+			json.NewDecoder(r.Body).Decode(&config)
+			if strings.Join(config.Cmd, " ") != "redis-cli flushall" || !config.AttachStdout || !config.AttachStderr {
+				t.Errorf("unexpected exec config %+v", config)
+			}
+			fmt.Fprintln(w, `{"Id":"flush"}`)
+		case strings.HasSuffix(r.URL.Path, "/exec/flush/start"):
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			fmt.Fprint(conn, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+			stdcopy.NewStdWriter(conn, stdcopy.Stdout).Write([]byte("flushing\n"))
+			stdcopy.NewStdWriter(conn, stdcopy.Stderr).Write([]byte("(error) READONLY\n"))
+		case strings.HasSuffix(r.URL.Path, "/exec/flush/json"):
+			fmt.Fprintln(w, `{"ID":"flush","Running":false,"ExitCode":1}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	c := &DockerContainer{ID: "redis", provider: provider}
+
+	exitCode, output, err := c.Exec(context.Background(), []string{"redis-cli", "flushall"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(output)
+	if exitCode != 1 || string(content) != "flushing\n(error) READONLY\n" {
+		t.Errorf("unexpected exit code %d and output %q", exitCode, content)
+	}
+
+	if _, _, err := c.Exec(context.Background(), nil); err == nil {
+		t.Error("expected an empty command not to be run")
+	}
+}
+
+func TestContainerCreationFromDockerfile(t *testing.T) {
+	ctx := context.Background()
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			FromDockerfile: FromDockerfile{
+				Context: "./testresources",
+			},
+			WaitingFor: wait.ForLog("this is from the Dockerfile"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+}
+
+func TestContainerCreationFromFailingDockerfileReportsStepOutput(t *testing.T) {
+	ctx := context.Background()
+	_, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			FromDockerfile: FromDockerfile{
+				Context:    "./testresources",
+				Dockerfile: "failing.Dockerfile",
+			},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected the build to fail")
+	}
+	if !strings.Contains(err.Error(), "about to fail") {
+		t.Fatalf("expected the output of the failing step in the error, got: %s", err)
+	}
+}
+
+func TestContainerCanReachHostAccessPorts(t *testing.T) {
+	ctx := context.Background()
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello from the host")
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:           "alpine:3.10",
+			Entrypoint:      []string{"sh", "-c", fmt.Sprintf("wget -q -O - http://%s:%d && sleep 60", HostInternal, port)},
+			HostAccessPorts: []int{port},
+			WaitingFor:      wait.ForLog("hello from the host"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+}
+
+func TestContainerReuseByName(t *testing.T) {
+	ctx := context.Background()
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:        "nginx",
+			Name:         fmt.Sprintf("reused_nginx_%d", time.Now().UnixNano()),
+			ExposedPorts: []string{"80/tcp"},
+			WaitingFor:   wait.ForListeningPort("80/tcp"),
+		},
+		Started: true,
+		Reuse:   true,
+	}
+
+	first, err := GenericContainer(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Terminate(ctx)
+
+	second, err := GenericContainer(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetContainerID() != second.GetContainerID() {
+		t.Fatalf("expected container %s to be reused, got %s", first.GetContainerID(), second.GetContainerID())
+	}
+
+	req.ExposedPorts = []string{"80/tcp", "443/tcp"}
+	third, err := GenericContainer(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer third.Terminate(ctx)
+	if third.GetContainerID() == first.GetContainerID() {
+		t.Fatal("expected the container to be created again once its definition changed")
+	}
+}
+
+func TestHostAccessPortsAreValidated(t *testing.T) {
+	provider, err := NewDockerProviderWithHost("tcp://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.CreateContainer(context.Background(), ContainerRequest{
+		Image:           "alpine:3.10",
+		HostAccessPorts: []int{8080, 70000},
+		SkipReaper:      true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid host access port 70000") {
+		t.Fatalf("expected the port to be rejected, got %v", err)
+	}
+}
+
+func TestContainerExistsFiltersByName(t *testing.T) {
+	var filter string
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/containers/json") {
+			http.NotFound(w, r)
+			return
+		}
+		filter = r.URL.Query().Get("filters")
+		w.Header().Set("Content-Type", "application/json")
+		// a daemon matching parts of the names
+		json.NewEncoder(w).Encode([]types.Container{
+			{ID: "other", Names: []string{"/db-replica"}},
+			{ID: "db", Names: []string{"/db"}, Labels: map[string]string{TestcontainerLabelSessionID: "session"}},
+		})
+	})
+	ctx := context.Background()
+
+	exists, err := provider.ContainerExists(ctx, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists || !strings.Contains(filter, `"name":{"^/db$":true}`) {
+		t.Fatalf("expected the container to be found with a name filter, got %v with filter %s", exists, filter)
+	}
+
+	c, err := provider.FindContainerByName(ctx, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.GetContainerID() != "db" || c.SessionID() != "session" {
+		t.Fatalf("expected the container named db, got %s", c.GetContainerID())
+	}
+
+	if _, err := provider.FindContainerByName(ctx, "db-"); err == nil {
+		t.Fatal("expected a container matching only a part of the name not to be found")
+	}
+}
+
+func TestCopyToContainer(t *testing.T) {
+	var dir string
+	var header *tar.Header
+	var content []byte
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.HasSuffix(r.URL.Path, "/containers/copied/archive") {
+			http.NotFound(w, r)
+			return
+		}
+		dir = r.URL.Query().Get("path")
+		tr := tar.NewReader(r.Body)
+		header, _ = tr.Next()
+		content, _ = ioutil.ReadAll(tr)
+	})
+
+	c := &DockerContainer{ID: "copied", provider: provider}
+	if err := c.CopyToContainer(context.Background(), []byte("#!/bin/sh\n"), "/usr/sbin/start.sh", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if dir != "/usr/sbin" || header.Name != "start.sh" || header.Mode != 0755 || string(content) != "#!/bin/sh\n" {
+		t.Fatalf("unexpected copy of %s to %s with mode %o: %q", header.Name, dir, header.Mode, content)
+	}
+}
+
+func TestStopGracefully(t *testing.T) {
+	var mutex sync.Mutex
+	var signals []string
+	exited := map[string]chan int{"graceful": make(chan int, 1), "stubborn": make(chan int, 1)}
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.URL.Path, "/")
+		id, action := parts[len(parts)-2], parts[len(parts)-1]
+		switch action {
+		case "kill":
+			signal := r.URL.Query().Get("signal")
+			mutex.Lock()
+			signals = append(signals, id+" "+signal)
+			mutex.Unlock()
+			switch {
+			case signal == "SIGKILL":
+				exited[id] <- 137
+			case id == "graceful":
+				exited[id] <- 0
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case "wait":
+			// like the daemon, the headers are sent before the container exits
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			select {
+			case code := <-exited[id]:
+				// the exit is seen by the following waits too
+				exited[id] <- code
+				fmt.Fprintf(w, `{"StatusCode":%d}`, code)
+			case <-r.Context().Done():
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	graceful := &DockerContainer{ID: "graceful", provider: provider}
+	result, err := graceful.StopGracefully(ctx, "SIGTERM", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Killed || result.ExitCode != 0 {
+		t.Errorf("expected the container to exit on the signal, got %+v", result)
+	}
+
+	stubborn := &DockerContainer{ID: "stubborn", provider: provider}
+	result, err = stubborn.StopGracefully(ctx, "SIGINT", 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Killed || result.ExitCode != 137 {
+		t.Errorf("expected the container to be killed, got %+v", result)
+	}
+
+	expected := "graceful SIGTERM,stubborn SIGINT,stubborn SIGKILL"
+	if strings.Join(signals, ",") != expected {
+		t.Errorf("expected the signals %s, got %v", expected, signals)
+	}
+}
+
+func TestReadFilesOfContainer(t *testing.T) {
+	archives := map[string][]string{
+		"/etc/app.conf": {"app.conf"},
+		"/var/log":      {"log/", "log/app.log", "log/app.err", "log/old/", "log/old/app.log"},
+	}
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		files, ok := archives[r.URL.Query().Get("path")]
+		if !strings.HasSuffix(r.URL.Path, "/containers/written/archive") || !ok {
+			http.NotFound(w, r)
+			return
+		}
+		mode := os.FileMode(0644)
+		if strings.HasSuffix(files[0], "/") {
+			mode |= os.ModeDir
+		}
+		stat, _ := json.Marshal(types.ContainerPathStat{Name: files[0], Mode: mode})
+		w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(stat))
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		tw := tar.NewWriter(w)
+		for _, name := range files {
+			content := "content of " + name
+			if strings.HasSuffix(name, "/") {
+				content = ""
+			}
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))})
+			tw.Write([]byte(content))
+		}
+		tw.Close()
+	})
+	c := &DockerContainer{ID: "written", provider: provider}
+	ctx := context.Background()
+
+	for file, expected := range map[string]bool{"/etc/app.conf": true, "/var/log": true, "/etc/other.conf": false} {
+		exists, err := c.FileExists(ctx, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists != expected {
+			t.Errorf("expected %s to exist: %v", file, expected)
+		}
+	}
+
+	content, err := c.ReadFile(ctx, "/etc/app.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "content of app.conf" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if _, err := c.ReadFile(ctx, "/var/log"); err == nil {
+		t.Error("expected a directory not to be read")
+	}
+
+	matches, err := c.Glob(ctx, "/var/log/*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(matches, ",") != "/var/log/app.log" {
+		t.Errorf("unexpected matches %v", matches)
+	}
+	matches, err = c.Glob(ctx, "/var/log/*/app.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(matches, ",") != "/var/log/old/app.log" {
+		t.Errorf("unexpected matches %v", matches)
+	}
+	if matches, err := c.Glob(ctx, "/var/lib/*"); err != nil || matches != nil {
+		t.Errorf("expected no match in a missing directory, got %v, %v", matches, err)
+	}
+}
@@ -0,0 +1,64 @@
+package testcontainers
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// httpEndpoint gets the base URL of the HTTP server of the container listening on port, for the
+// implementations of HTTPEndpoint and HTTPClientFor
+func httpEndpoint(ctx context.Context, c Container, port nat.Port, scheme string) (*url.URL, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mapped, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return nil, err
+	}
+
+	// JoinHostPort brackets IPv6 addresses
+	return &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, mapped.Port()), Path: "/"}, nil
+}
+
+// httpClientFor creates a client of the HTTP server of the container listening on port, over TLS with the
+// config if not nil, for the implementations of HTTPClientFor
+func httpClientFor(ctx context.Context, c Container, port nat.Port, tlsConfig *tls.Config) (*http.Client, error) {
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	endpoint, err := httpEndpoint(ctx, c, port, scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+	return &http.Client{Transport: &baseURLTransport{base: endpoint, next: transport}}, nil
+}
+
+// baseURLTransport resolves the URLs of the requests without a host, e.g. "/health", against its base URL
+type baseURLTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t *baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	// a round tripper must not modify the request
+	resolved := req.WithContext(req.Context())
+	resolved.URL = t.base.ResolveReference(req.URL)
+	resolved.Host = resolved.URL.Host
+	return t.next.RoundTrip(resolved)
+}
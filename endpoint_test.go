@@ -0,0 +1,113 @@
+package testcontainers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+)
+
+func TestHTTPEndpoint(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{host: "localhost", expected: "http://localhost:32768/"},
+		{host: "::1", expected: "http://[::1]:32768/"},
+	}
+
+	for _, tt := range tests {
+		c := &ContainerMock{HostName: tt.host, PortMap: nat.PortMap{"80/tcp": {{HostPort: "32768"}}}}
+		endpoint, err := c.HTTPEndpoint(ctx, "80/tcp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if endpoint.String() != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, endpoint)
+		}
+	}
+}
+
+func TestHTTPClientFor(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.RequestURI()))
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	c := &ContainerMock{HostName: host, PortMap: nat.PortMap{"443/tcp": {{HostPort: port}}}}
+	ctx := context.Background()
+
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+	client, err := c.HTTPClientFor(ctx, "443/tcp", tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range []string{"/health?verbose=true", server.URL + "/health?verbose=true"} {
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "/health?verbose=true" {
+			t.Errorf("%s: expected the request to reach the server, got %q", target, body)
+		}
+	}
+
+	client, err = c.HTTPClientFor(ctx, "443/tcp", &tls.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get("/health"); err == nil {
+		t.Error("expected the certificate of the server to be checked")
+	}
+}
+
+func TestDockerContainerHTTPEndpoint(t *testing.T) {
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("nginx"))
+	}))
+	defer web.Close()
+	_, webPort, _ := net.SplitHostPort(web.Listener.Addr().String())
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/containers/web/json") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"Id":"web","State":{"Running":true},"NetworkSettings":{"Ports":{"80/tcp":[{"HostIp":"0.0.0.0","HostPort":"%s"}]}}}`, webPort)
+	})
+	c := &DockerContainer{ID: "web", provider: provider}
+	ctx := context.Background()
+
+	for _, port := range []nat.Port{"80/tcp", "80"} {
+		endpoint, err := c.HTTPEndpoint(ctx, port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "http://127.0.0.1:" + webPort + "/"; endpoint.String() != expected {
+			t.Errorf("%s: expected %s, got %s", port, expected, endpoint)
+		}
+		resp, err := http.Get(endpoint.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "nginx" {
+			t.Errorf("%s: expected the server to be reached, got %q", port, body)
+		}
+	}
+
+	if _, err := c.HTTPEndpoint(ctx, "443/tcp"); err == nil {
+		t.Error("expected a port which is not mapped to have no endpoint")
+	}
+}
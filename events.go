@@ -0,0 +1,19 @@
+package testcontainers
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// Events subscribes to the events of the daemon about the resources of the session, further filtered
+// by f, e.g. with filters.Arg("event", "oom") to catch containers killed for lack of memory. Events are
+// sent until ctx is cancelled, or until an error is sent on the error channel, which ends the subscription.
+func (p *DockerProvider) Events(ctx context.Context, f filters.Args) (<-chan events.Message, <-chan error) {
+	f = f.Clone()
+	f.Add("label", TestcontainerLabelSessionID+"="+sessionID.String())
+
+	return p.client.Events(ctx, types.EventsOptions{Filters: f})
+}
@@ -0,0 +1,42 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+)
+
+func TestEventsOfTheSession(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	provider, err := NewDockerProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages, errs := provider.Events(ctx, filters.NewArgs(filters.Arg("type", "container")))
+
+	c, err := provider.RunContainer(ctx, ContainerRequest{
+		Image: "nginx",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+
+	for {
+		select {
+		case m := <-messages:
+			if m.Actor.ID == c.GetContainerID() && m.Action == "start" {
+				return
+			}
+		case err := <-errs:
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("no start event received for the container")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package testcontainers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envReferencePattern matches the references to the env of the host, ${VAR} or ${VAR:-default}
+var envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv gets a copy of the request where the ${VAR} and ${VAR:-default} references in the image, the values
+// of the env, the command and the paths of the mounts are replaced with the values looked up. A reference to
+// a variable which is not set, without a default, fails. Other $ signs are kept, e.g. for the shell of the container.
+func (c *ContainerRequest) expandEnv(lookup func(string) (string, bool)) (ContainerRequest, error) {
+	undefined := map[string]bool{}
+	expand := func(s string) string {
+		return envReferencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			match := envReferencePattern.FindStringSubmatch(ref)
+			hasDefault := strings.Contains(ref, ":-")
+			if value, ok := lookup(match[1]); ok && (value != "" || !hasDefault) {
+				return value
+			}
+			if hasDefault {
+				// as in the shell, the default applies to an empty variable as well
+				return match[2]
+			}
+			undefined[match[1]] = true
+			return ref
+		})
+	}
+
+	expanded := c.Clone()
+	expanded.Image = expand(c.Image)
+	for k, v := range c.Env {
+		expanded.Env[k] = expand(v)
+	}
+	expanded.Cmd = expand(c.Cmd)
+	for i, arg := range c.Command {
+		expanded.Command[i] = expand(arg)
+	}
+	if c.BindMounts != nil {
+		expanded.BindMounts = make(map[string]string, len(c.BindMounts))
+		for host, target := range c.BindMounts {
+			expanded.BindMounts[expand(host)] = expand(target)
+		}
+	}
+	for volume, target := range c.VolumeMounts {
+		expanded.VolumeMounts[volume] = expand(target)
+	}
+
+	if len(undefined) > 0 {
+		names := make([]string, 0, len(undefined))
+		for name := range undefined {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return expanded, fmt.Errorf("the env variables %s referred to by the request are not set", strings.Join(names, ", "))
+	}
+
+	return expanded, nil
+}
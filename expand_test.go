@@ -0,0 +1,85 @@
+package testcontainers
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"PG_VERSION": "14", "DATA": "/tmp/data", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	req := ContainerRequest{
+		Image:        "postgres:${PG_VERSION}",
+		Env:          map[string]string{"POSTGRES_DB": "${DB:-test}", "POSTGRES_USER": "${EMPTY:-postgres}"},
+		Cmd:          "postgres -c log_min_duration_statement=${SLOW_MS:-100}",
+		Command:      []string{"sh", "-c", "echo $HOME ${PG_VERSION}"},
+		BindMounts:   map[string]string{"${DATA}/init": "/docker-entrypoint-initdb.d"},
+		VolumeMounts: map[string]string{"pgdata": "${PGDATA:-/var/lib/postgresql/data}"},
+	}
+
+	expanded, err := req.expandEnv(lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ContainerRequest{
+		Image:        "postgres:14",
+		Env:          map[string]string{"POSTGRES_DB": "test", "POSTGRES_USER": "postgres"},
+		Cmd:          "postgres -c log_min_duration_statement=100",
+		Command:      []string{"sh", "-c", "echo $HOME 14"},
+		BindMounts:   map[string]string{"/tmp/data/init": "/docker-entrypoint-initdb.d"},
+		VolumeMounts: map[string]string{"pgdata": "/var/lib/postgresql/data"},
+	}
+	if !reflect.DeepEqual(expanded, expected) {
+		t.Errorf("expected %+v, got %+v", expected, expanded)
+	}
+	if req.Image != "postgres:${PG_VERSION}" || req.Command[2] != "echo $HOME ${PG_VERSION}" {
+		t.Error("expected the request itself not to be changed")
+	}
+
+	req.Image = "${REGISTRY}/postgres:${TAG}"
+	if _, err := req.expandEnv(lookup); err == nil || !strings.Contains(err.Error(), "REGISTRY, TAG") {
+		t.Errorf("expected the variables which are not set to fail, got %v", err)
+	}
+}
+
+func TestGenericContainerExpandsEnv(t *testing.T) {
+	provider := NewProviderMock()
+	var created ContainerRequest
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		created = req
+		return nil
+	}
+	if err := RegisterProvider("mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("mock")
+	os.Setenv("TC_TEST_NGINX_TAG", "1.21")
+	defer os.Unsetenv("TC_TEST_NGINX_TAG")
+
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{Image: "nginx:${TC_TEST_NGINX_TAG}"},
+		ProviderName:     "mock",
+	}
+	if _, err := GenericContainer(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if created.Image != "nginx:${TC_TEST_NGINX_TAG}" {
+		t.Errorf("expected the image not to be expanded without ExpandEnv, got '%s'", created.Image)
+	}
+
+	if err := WithEnvExpansion()(&req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GenericContainer(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if created.Image != "nginx:1.21" {
+		t.Errorf("expected the image to be expanded, got '%s'", created.Image)
+	}
+}
@@ -0,0 +1,234 @@
+package testcontainers
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// tarDir creates an in-memory tar archive of the src directory, with paths relative to src
+// and the file modes of the original files preserved
+func tarDir(src string) (*bytes.Buffer, error) {
+	buffer := &bytes.Buffer{}
+	if err := writeTarDir(buffer, src, ""); err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// writeTarDir writes a tar archive of the src directory to w, with the file modes of the original files preserved.
+// The paths are relative to src, or to the parent of src if the name of the directory in the archive is not empty.
+func writeTarDir(w io.Writer, src, name string) error {
+	tw := tar.NewWriter(w)
+
+	err := filepath.Walk(src, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." && name == "" {
+			return nil
+		}
+		rel = path.Join(name, filepath.ToSlash(rel))
+
+		link := ""
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		header.Name = rel
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// tarFile creates an in-memory tar archive of a single file of the given name, content and mode
+func tarFile(name string, content []byte, mode int64) (*bytes.Buffer, error) {
+	buffer := &bytes.Buffer{}
+	tw := tar.NewWriter(buffer)
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    mode,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// copyFilesToContainer copies the files and the directories of the host into the container, see ContainerRequest.Files
+func copyFilesToContainer(ctx context.Context, c Container, files []ContainerFile) error {
+	for _, f := range files {
+		fi, err := os.Stat(f.HostFilePath)
+		if err != nil {
+			return fmt.Errorf("could not copy '%s' to container '%s': %s", f.HostFilePath, c.GetContainerID(), err)
+		}
+		if fi.IsDir() {
+			err = c.CopyDirToContainer(ctx, f.HostFilePath, f.ContainerFilePath)
+		} else {
+			err = copyFileToContainer(ctx, c, f, fi.Mode())
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyFileToContainer copies the file of the host into the container, with its mode unless another one is set
+func copyFileToContainer(ctx context.Context, c Container, f ContainerFile, mode os.FileMode) error {
+	content, err := ioutil.ReadFile(f.HostFilePath)
+	if err != nil {
+		return fmt.Errorf("could not copy '%s' to container '%s': %s", f.HostFilePath, c.GetContainerID(), err)
+	}
+	fileMode := f.FileMode
+	if fileMode == 0 {
+		fileMode = int64(mode.Perm())
+	}
+
+	return c.CopyToContainer(ctx, content, f.ContainerFilePath, fileMode)
+}
+
+// untarFile reads the content of the single file of a tar archive
+func untarFile(archive io.Reader) ([]byte, error) {
+	tr := tar.NewReader(archive)
+	if _, err := tr.Next(); err != nil {
+		return nil, errors.Wrap(err, "could not read archive")
+	}
+
+	return ioutil.ReadAll(tr)
+}
+
+// globDir gets the directory the absolute pattern starts with, before its first component with a
+// special character, e.g. "/var/log" for "/var/log/*/app.log"
+func globDir(pattern string) string {
+	components := strings.Split(pattern, "/")
+	for i, component := range components {
+		if strings.ContainsAny(component, `*?[\`) {
+			return path.Clean("/" + strings.Join(components[:i], "/"))
+		}
+	}
+
+	return path.Clean(pattern)
+}
+
+// globTar gets the sorted paths of the entries of the tar archive, relative to dir, matching the pattern
+func globTar(archive io.Reader, dir, pattern string) ([]string, error) {
+	var matches []string
+	tr := tar.NewReader(archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read archive")
+		}
+
+		name := path.Join(dir, header.Name)
+		if ok, _ := path.Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+// untarTo extracts the directories, regular files and symbolic links of the tar archive into dir
+func untarTo(archive io.Reader, dir string) error {
+	tr := tar.NewReader(archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read archive")
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !withinDir(dir, target) {
+			return fmt.Errorf("entry '%s' of the archive is outside of the directory", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg, tar.TypeRegA:
+			err = writeFile(target, tr, os.FileMode(header.Mode).Perm())
+		case tar.TypeSymlink:
+			err = os.Symlink(header.Linkname, target)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(path string, content io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
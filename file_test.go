@@ -0,0 +1,20 @@
+package testcontainers
+
+import "testing"
+
+func TestGlobDir(t *testing.T) {
+	tests := map[string]string{
+		"/var/log/*.log":        "/var/log",
+		"/var/log/*/app.log":    "/var/log",
+		"/var/log/app-?.log":    "/var/log",
+		"/*/log":                "/",
+		"/etc/app.conf":         "/etc/app.conf",
+		"/var/log/[ab]/app.log": "/var/log",
+	}
+
+	for pattern, expected := range tests {
+		if dir := globDir(pattern); dir != expected {
+			t.Errorf("%s: expected %s, got %s", pattern, expected, dir)
+		}
+	}
+}
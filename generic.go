@@ -2,6 +2,7 @@ package testcontainers
 
 import (
 	"context"
+	"os"
 
 	"github.com/pkg/errors"
 )
@@ -11,32 +12,100 @@ type GenericContainerRequest struct {
 	ContainerRequest              // embedded request for provider
 	Started          bool         // whether to auto-start the container
 	ProviderType     ProviderType // which provider to use, Docker if empty
+	ProviderName     string       // which registered provider to use, overrides ProviderType
+
+	// Reuse gets the container defined by the request if it exists, rather than creating a new one: the container
+	// with the Name of the request, or the one labelled with the Hash of the request if it has no name.
+	// A container of the same name with another definition is replaced.
+	// Reused containers are neither reaped nor removed when stopped, so that they outlive the process
+	// and are shared by the test processes of several packages.
+	Reuse bool
+
+	// DryRun logs the docker commands equivalent to the request rather than running them, see
+	// ContainerRequest.DockerCommands. The container returned is a ContainerMock, so that the rest
+	// of the setup of the test runs dry as well.
+	DryRun bool
+
+	// DependsOn are the requests of the containers to start, and wait for, before the container of the
+	// request, which is started by StartWithDependencies rather than GenericContainer
+	DependsOn []*GenericContainerRequest
+	// DependsOnNames refer to the requests the container depends on by the Name of their container, among
+	// the requests given to StartWithDependencies
+	DependsOnNames []string
 }
 
 // GenericContainer creates a generic container with parameters
 func GenericContainer(ctx context.Context, req GenericContainerRequest) (Container, error) {
-	provider, err := req.ProviderType.GetProvider()
+	if req.ExpandEnv {
+		expanded, err := req.expandEnv(os.LookupEnv)
+		if err != nil {
+			return nil, err
+		}
+		req.ContainerRequest = expanded
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if len(req.DependsOn) > 0 || len(req.DependsOnNames) > 0 {
+		return nil, errors.New("a request with dependencies is started by StartWithDependencies")
+	}
+	if req.DryRun {
+		return dryRun(ctx, req)
+	}
+
+	provider, err := getProvider(req.ProviderType, req.ProviderName)
 	if err != nil {
 		return nil, err
 	}
 
-	c, err := provider.CreateContainer(ctx, req.ContainerRequest)
+	var c Container
+	if req.Reuse {
+		c, err = provider.ReuseOrCreateContainer(ctx, req.ContainerRequest)
+	} else {
+		c, err = provider.CreateContainer(ctx, req.ContainerRequest)
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create container")
 	}
 
 	if req.Started {
-		if err := c.Start(ctx); err != nil {
-			return c, errors.Wrap(err, "failed to start container")
+		if err := startContainer(ctx, c, req); err != nil {
+			if !req.Reuse && IsPortAllocated(err) {
+				c, err = retryPortCollisions(ctx, provider, c, req, err)
+			}
+			if err != nil {
+				return c, errors.Wrap(err, "failed to start container")
+			}
 		}
 	}
 
 	return c, nil
 }
 
+// startContainer starts the container of the request, unless it is a reused container which is running,
+// in which case it only waits for it to be ready
+func startContainer(ctx context.Context, c Container, req GenericContainerRequest) error {
+	if !req.Reuse {
+		return c.Start(ctx)
+	}
+
+	running, err := c.IsRunning(ctx)
+	if err != nil {
+		return err
+	}
+	if !running {
+		return c.Start(ctx)
+	}
+	if req.WaitingFor != nil {
+		return req.WaitingFor.WaitUntilReady(ctx, c)
+	}
+
+	return nil
+}
+
 // UseExistent uses existent container
 func UseExistent(ctx context.Context, req GenericContainerRequest) (Container, error) {
-	provider, err := req.ProviderType.GetProvider()
+	provider, err := getProvider(req.ProviderType, req.ProviderName)
 	if err != nil {
 		return nil, err
 	}
@@ -48,3 +117,47 @@ func UseExistent(ctx context.Context, req GenericContainerRequest) (Container, e
 
 	return c, nil
 }
+
+// GenericNetworkRequest represents parameters to a generic network
+type GenericNetworkRequest struct {
+	NetworkRequest              // embedded request for provider
+	ProviderType   ProviderType // which provider to use, Docker if empty
+	ProviderName   string       // which registered provider to use, overrides ProviderType
+}
+
+// GenericNetwork creates a generic network with parameters
+func GenericNetwork(ctx context.Context, req GenericNetworkRequest) (Network, error) {
+	provider, err := getProvider(req.ProviderType, req.ProviderName)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := provider.CreateNetwork(ctx, req.NetworkRequest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create network")
+	}
+
+	return n, nil
+}
+
+// GenericVolumeRequest represents parameters to a generic volume
+type GenericVolumeRequest struct {
+	VolumeRequest              // embedded request for provider
+	ProviderType  ProviderType // which provider to use, Docker if empty
+	ProviderName  string       // which registered provider to use, overrides ProviderType
+}
+
+// GenericVolume creates a generic named volume with parameters
+func GenericVolume(ctx context.Context, req GenericVolumeRequest) (Volume, error) {
+	provider, err := getProvider(req.ProviderType, req.ProviderName)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := provider.CreateVolume(ctx, req.VolumeRequest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create volume")
+	}
+
+	return v, nil
+}
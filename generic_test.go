@@ -0,0 +1,130 @@
+package testcontainers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestGenericContainerReuse(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+	if err := RegisterProvider("mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("mock")
+
+	first, err := Run(ctx, "postgres", WithProvider("mock"), WithName("shared-postgres"), WithReuse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Run(ctx, "postgres", WithProvider("mock"), WithName("shared-postgres"), WithReuse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetContainerID() != second.GetContainerID() {
+		t.Fatal("expected the container to be reused")
+	}
+	if calls := strings.Join(second.(*ContainerMock).Calls(), ","); calls != "Start" {
+		t.Fatalf("expected the running container not to be started again, got calls %s", calls)
+	}
+	if req := first.(*ContainerMock).Request; !req.SkipReaper || !req.DontRemove {
+		t.Fatal("expected a reused container to be neither reaped nor removed")
+	}
+
+	if err := first.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Run(ctx, "postgres", WithProvider("mock"), WithName("shared-postgres"), WithReuse()); err != nil {
+		t.Fatal(err)
+	}
+	if running, _ := first.IsRunning(ctx); !running {
+		t.Fatal("expected the stopped container to be started again")
+	}
+
+	mysql, err := Run(ctx, "mysql", WithProvider("mock"), WithName("shared-postgres"), WithReuse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mysql.GetContainerID() == first.GetContainerID() {
+		t.Fatal("expected the container to be created again once its definition changed")
+	}
+	if running, _ := first.IsRunning(ctx); running {
+		t.Fatal("expected the outdated container to be removed")
+	}
+	_, err = GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			FromDockerfile: FromDockerfile{Context: "./testresources"},
+			Name:           "shared-postgres",
+		},
+		ProviderName: "mock",
+		Reuse:        true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "cannot be reused") {
+		t.Fatalf("expected a container built from a Dockerfile not to be reusable, got %v", err)
+	}
+	if n := len(provider.Containers()); n != 2 {
+		t.Fatalf("expected 2 containers, got %d", n)
+	}
+}
+
+func TestGenericContainerReuseByHash(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+	if err := RegisterProvider("mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("mock")
+
+	first, err := Run(ctx, "redis", WithProvider("mock"), WithExposedPorts("6379/tcp"), WithReuse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the session does not change the definition of the container
+	second, err := Run(ctx, "redis", WithProvider("mock"), WithExposedPorts("6379/tcp"), WithReuse(), WithSessionID("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetContainerID() != second.GetContainerID() {
+		t.Fatal("expected the container to be reused")
+	}
+
+	third, err := Run(ctx, "redis", WithProvider("mock"), WithExposedPorts("6379/tcp"), WithReuse(),
+		WithEnv(map[string]string{"REDIS_ARGS": "--save 60 1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.GetContainerID() == first.GetContainerID() {
+		t.Fatal("expected a container with another definition not to be reused")
+	}
+	if running, _ := first.IsRunning(ctx); !running {
+		t.Fatal("expected the container without name to be kept")
+	}
+}
+
+func TestContainerRequestHash(t *testing.T) {
+	req := ContainerRequest{Image: "redis", Env: map[string]string{"A": "1", "B": "2"}}
+	hash := req.Hash()
+
+	same := ContainerRequest{
+		Image:        "redis",
+		Env:          map[string]string{"B": "2", "A": "1"},
+		WaitingFor:   wait.ForLog("Ready to accept connections"),
+		SkipReaper:   true,
+		RegistryCred: "secret",
+	}
+	if same.Hash() != hash {
+		t.Fatal("expected the hash not to depend on the order of the map or on how the container is managed")
+	}
+	same.Labels = map[string]string{TestcontainerLabelHash: hash}
+	if same.Hash() != hash {
+		t.Fatal("expected the hash not to depend on the hash label")
+	}
+
+	other := ContainerRequest{Image: "redis", Env: map[string]string{"A": "1", "B": "3"}}
+	if other.Hash() == hash {
+		t.Fatal("expected another definition to have another hash")
+	}
+}
@@ -0,0 +1,214 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+// ContainerGroupRequest is a set of requests of containers started, stopped and terminated together, a
+// lightweight pod, e.g. an application and its sidecars
+type ContainerGroupRequest struct {
+	Requests []GenericContainerRequest
+
+	// SharedNetworkNamespace runs the containers in the network namespace of the first one, where they reach
+	// each other on localhost, as the containers of a pod. The ports of all the requests are then exposed by
+	// the first container, which is started before and stopped after the others.
+	SharedNetworkNamespace bool
+
+	ProviderType ProviderType // which provider creates the network of the group, Docker if empty
+	ProviderName string       // which registered provider creates the network of the group, overrides ProviderType
+}
+
+// ContainerGroup is a set of containers started, stopped and terminated together, see StartContainerGroup
+type ContainerGroup struct {
+	Containers []Container // the containers, in the order of their requests, nil for those which failed
+	Network    Network     // the network of the group, on which the containers reach each other by Name
+
+	sharedNamespace bool
+}
+
+// ContainerGroupError aggregates the failures of an operation on the containers of a group, keyed by container ID
+type ContainerGroupError struct {
+	Operation string
+	Errors    map[string]error
+}
+
+func (e ContainerGroupError) Error() string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", id, e.Errors[id]))
+	}
+
+	return fmt.Sprintf("could not %s %d of the containers of the group: %s", e.Operation, len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// StartContainerGroup creates a network for the group and starts the containers of the requests on it, whatever
+// the Started field of the requests, concurrently unless they share the network namespace of the first one.
+// It returns once all of them are ready. If any fails, the error is a ParallelContainersError reporting the failed
+// requests, and the group is returned along with it to be terminated.
+func StartContainerGroup(ctx context.Context, req ContainerGroupRequest) (*ContainerGroup, error) {
+	if len(req.Requests) == 0 {
+		return nil, errors.New("a container group needs at least one request")
+	}
+
+	name := generateName("group")
+	n, err := GenericNetwork(ctx, GenericNetworkRequest{
+		NetworkRequest: NetworkRequest{Name: name, CheckDuplicate: true},
+		ProviderType:   req.ProviderType,
+		ProviderName:   req.ProviderName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the network of the group")
+	}
+	g := &ContainerGroup{
+		Containers:      make([]Container, len(req.Requests)),
+		Network:         n,
+		sharedNamespace: req.SharedNetworkNamespace,
+	}
+
+	reqs := make([]GenericContainerRequest, len(req.Requests))
+	for i, r := range req.Requests {
+		r.ContainerRequest = r.ContainerRequest.Clone()
+		r.Started = true
+		if i == 0 || !req.SharedNetworkNamespace {
+			r.Networks = append(r.Networks, name)
+			if r.Name != "" {
+				r.NetworkAliases = withNetworkAlias(r.NetworkAliases, name, r.Name)
+			}
+		} else {
+			// a container in the namespace of another one cannot expose ports, the ports are the ones of the pod
+			reqs[0].ExposedPorts = append(reqs[0].ExposedPorts, r.ExposedPorts...)
+			r.ExposedPorts = nil
+		}
+		reqs[i] = r
+	}
+
+	errs := make([]error, len(reqs))
+	g.each("start", false, func(i int, _ Container) error {
+		r := reqs[i]
+		if i > 0 && req.SharedNetworkNamespace {
+			r.NetworkMode = container.NetworkMode("container:" + g.Containers[0].GetContainerID())
+		}
+		g.Containers[i], errs[i] = GenericContainer(ctx, r)
+		return errs[i]
+	})
+
+	failures := ParallelContainersError{}
+	for i, err := range errs {
+		if i > 0 && req.SharedNetworkNamespace && errs[0] != nil {
+			err = fmt.Errorf("container %s, whose network namespace it shares, failed", reqs[0].describe())
+		}
+		if err != nil {
+			failures.Errors = append(failures.Errors, ParallelContainersRequestError{Request: reqs[i], Error: err})
+		}
+	}
+	if len(failures.Errors) > 0 {
+		return g, failures
+	}
+
+	return g, nil
+}
+
+// withNetworkAlias returns a copy of the aliases with the alias added on the network
+func withNetworkAlias(aliases map[string][]string, network, alias string) map[string][]string {
+	result := make(map[string][]string, len(aliases)+1)
+	for k, v := range aliases {
+		result[k] = v
+	}
+	result[network] = append(cloneStrings(aliases[network]), alias)
+
+	return result
+}
+
+// each calls do with the containers of the group concurrently for the named operation. If they share the network
+// namespace of the first one, do is called with the first one alone, before the others unless reverse is set,
+// in which case it is called after them. The others are skipped if do fails with the first one before them.
+func (g *ContainerGroup) each(operation string, reverse bool, do func(i int, c Container) error) error {
+	var mutex sync.Mutex
+	errs := map[int]error{}
+	call := func(i int) {
+		if err := do(i, g.Containers[i]); err != nil {
+			mutex.Lock()
+			errs[i] = err
+			mutex.Unlock()
+		}
+	}
+
+	if !g.sharedNamespace {
+		runConcurrently(len(g.Containers), defaultParallelWorkers, call)
+	} else {
+		others := func() {
+			runConcurrently(len(g.Containers)-1, defaultParallelWorkers, func(i int) { call(i + 1) })
+		}
+		if reverse {
+			others()
+			call(0)
+		} else {
+			call(0)
+			if errs[0] == nil {
+				others()
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	failures := ContainerGroupError{Operation: operation, Errors: map[string]error{}}
+	for i, err := range errs {
+		id := fmt.Sprintf("#%d", i)
+		if c := g.Containers[i]; c != nil {
+			id = c.GetContainerID()
+		}
+		failures.Errors[id] = err
+	}
+
+	return failures
+}
+
+// Start starts the containers of the group, e.g. once stopped, and returns once all of them are ready
+func (g *ContainerGroup) Start(ctx context.Context) error {
+	return g.each("start", false, func(_ int, c Container) error {
+		if c == nil {
+			return nil
+		}
+		return c.Start(ctx)
+	})
+}
+
+// Stop stops the containers of the group
+func (g *ContainerGroup) Stop(ctx context.Context) error {
+	return g.each("stop", true, func(_ int, c Container) error {
+		if c == nil {
+			return nil
+		}
+		return c.Stop(ctx)
+	})
+}
+
+// Terminate terminates the containers of the group, then removes its network
+func (g *ContainerGroup) Terminate(ctx context.Context) error {
+	err := g.each("terminate", true, func(_ int, c Container) error {
+		if c == nil {
+			return nil
+		}
+		return c.Terminate(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.Network.Remove(ctx)
+}
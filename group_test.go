@@ -0,0 +1,115 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStartContainerGroup(t *testing.T) {
+	provider := NewProviderMock()
+	if err := RegisterProvider("group-mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("group-mock")
+	ctx := context.Background()
+
+	base := ContainerRequest{Image: "app", Name: "app", ExposedPorts: []string{"8080/tcp"}}
+	g, err := StartContainerGroup(ctx, ContainerGroupRequest{
+		Requests: []GenericContainerRequest{
+			{ContainerRequest: base, ProviderName: "group-mock"},
+			{ContainerRequest: ContainerRequest{Image: "envoy", Name: "proxy"}, ProviderName: "group-mock"},
+		},
+		ProviderName: "group-mock",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	network := g.Network.(*NetworkMock).Request.Name
+	for _, c := range provider.Containers() {
+		if running, _ := c.IsRunning(ctx); !running {
+			t.Errorf("expected container %s to be started", c.Request.Name)
+		}
+		if len(c.Request.Networks) != 1 || c.Request.Networks[0] != network ||
+			c.Request.NetworkAliases[network][0] != c.Request.Name {
+			t.Errorf("expected container %s to be attached to the network of the group by name, got %v, %v",
+				c.Request.Name, c.Request.Networks, c.Request.NetworkAliases)
+		}
+	}
+	if len(base.Networks) > 0 {
+		t.Errorf("expected the requests not to be changed, got %v", base.Networks)
+	}
+
+	if err := g.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if running, _ := g.Containers[1].IsRunning(ctx); running {
+		t.Error("expected the containers to be stopped")
+	}
+	if err := g.Terminate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Network.Remove(ctx); err == nil {
+		t.Error("expected the network to be removed with the group")
+	}
+}
+
+func TestStartContainerGroupSharingTheNetworkNamespace(t *testing.T) {
+	provider := NewProviderMock()
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		if req.Image == "broken" {
+			c.StartErr = errors.New("exited with code 1")
+		}
+		return nil
+	}
+	if err := RegisterProvider("pod-mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("pod-mock")
+	ctx := context.Background()
+
+	request := func(image string, ports ...string) GenericContainerRequest {
+		return GenericContainerRequest{
+			ContainerRequest: ContainerRequest{Image: image, ExposedPorts: ports},
+			ProviderName:     "pod-mock",
+		}
+	}
+	g, err := StartContainerGroup(ctx, ContainerGroupRequest{
+		Requests:               []GenericContainerRequest{request("app", "8080/tcp"), request("envoy", "9901/tcp")},
+		SharedNetworkNamespace: true,
+		ProviderName:           "pod-mock",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := provider.Containers()[0]
+	sidecar := provider.Containers()[1]
+	if strings.Join(first.Request.ExposedPorts, ",") != "8080/tcp,9901/tcp" || len(sidecar.Request.ExposedPorts) > 0 {
+		t.Errorf("expected the ports to be exposed by the first container, got %v and %v",
+			first.Request.ExposedPorts, sidecar.Request.ExposedPorts)
+	}
+	if string(sidecar.Request.NetworkMode) != "container:"+first.ID || len(sidecar.Request.Networks) > 0 {
+		t.Errorf("expected the sidecar to share the network namespace of the first container, got %s", sidecar.Request.NetworkMode)
+	}
+	if err := g.Terminate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err = StartContainerGroup(ctx, ContainerGroupRequest{
+		Requests:               []GenericContainerRequest{request("broken"), request("envoy")},
+		SharedNetworkNamespace: true,
+		ProviderName:           "pod-mock",
+	})
+	var failures ParallelContainersError
+	if !errors.As(err, &failures) || len(failures.Errors) != 2 {
+		t.Fatalf("expected both containers to fail, got %v", err)
+	}
+	if len(provider.Containers()) != 3 || g.Containers[1] != nil {
+		t.Error("expected the sidecar not to be created once the first container failed")
+	}
+	if err := g.Terminate(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,53 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DaemonInfo describes the daemon a provider talks to
+type DaemonInfo struct {
+	Host          string // the endpoint of the daemon
+	ServerVersion string
+	APIVersion    string // the API version negotiated with the daemon
+	OS            string
+	Arch          string
+	KernelVersion string
+}
+
+// diagnosticEnv are the env variables which select and configure the daemon, reported when it is unreachable
+var diagnosticEnv = []string{"DOCKER_HOST", "DOCKER_CONTEXT", "DOCKER_CONFIG", "DOCKER_TLS_VERIFY", "DOCKER_CERT_PATH", "TC_DOCKER_SOCKET", "TC_HOST"}
+
+// Health pings the Docker daemon and describes it. When the daemon is unreachable, the error
+// tells which endpoint was tried and how it was configured from the env.
+func (p *DockerProvider) Health(ctx context.Context) (DaemonInfo, error) {
+	host := p.dockerHost
+	if host == "" {
+		host = p.client.DaemonHost()
+	}
+
+	version, err := p.client.ServerVersion(ctx)
+	if err != nil {
+		env := []string{}
+		for _, name := range diagnosticEnv {
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, name+"="+value)
+			}
+		}
+		if len(env) == 0 {
+			env = append(env, "none of "+strings.Join(diagnosticEnv, ", ")+" set")
+		}
+		return DaemonInfo{}, fmt.Errorf("docker daemon at '%s' is unreachable (env: %s): %s", host, strings.Join(env, " "), err)
+	}
+
+	return DaemonInfo{
+		Host:          host,
+		ServerVersion: version.Version,
+		APIVersion:    p.client.ClientVersion(),
+		OS:            version.Os,
+		Arch:          version.Arch,
+		KernelVersion: version.KernelVersion,
+	}, nil
+}
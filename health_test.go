@@ -0,0 +1,37 @@
+package testcontainers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	provider, err := NewDockerProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := provider.Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ServerVersion == "" || info.OS == "" || info.Arch == "" {
+		t.Fatalf("expected the daemon to be described, got %+v", info)
+	}
+}
+
+func TestHealthDescribesUnreachableDaemon(t *testing.T) {
+	provider, err := NewDockerProviderWithHost("tcp://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.Health(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for an unreachable daemon")
+	}
+	if !strings.Contains(err.Error(), "tcp://127.0.0.1:1") || !strings.Contains(err.Error(), "env:") {
+		t.Fatalf("expected the endpoint and the env in the error, got: %s", err)
+	}
+}
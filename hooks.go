@@ -0,0 +1,62 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ContainerHook is called with a container at a step of its lifecycle, see ContainerRequest.PreTerminateHooks
+type ContainerHook func(ctx context.Context, c Container) error
+
+// runPreTerminateHooks calls the hooks in order before the container is terminated. Their errors are logged
+// rather than returned, so that a failed collection does not leave the container behind.
+func runPreTerminateHooks(ctx context.Context, c Container, hooks []ContainerHook) {
+	for _, hook := range hooks {
+		if err := hook(ctx, c); err != nil {
+			Logger.Printf("Pre-termination hook of container %s failed: %s", c.GetContainerID(), err)
+		}
+	}
+}
+
+// CollectLogs is a pre-termination hook writing the full logs of the container to <dir>/<container ID>.log,
+// as they are lost with the container
+func CollectLogs(dir string) ContainerHook {
+	return func(ctx context.Context, c Container) error {
+		_, err := archiveLogs(ctx, c, dir, c.GetContainerID())
+		return err
+	}
+}
+
+// CollectFiles is a pre-termination hook copying the file or the directory at the path of the container into
+// the directory of the host, e.g. the data directory of a database or a heap dump, as the volumes of the
+// container are removed with it. The directory of the host is created if needed.
+func CollectFiles(containerPath, hostDir string) ContainerHook {
+	return func(ctx context.Context, c Container) error {
+		dc, ok := c.(*DockerContainer)
+		if !ok {
+			return errors.New("files can only be collected from docker containers")
+		}
+
+		archive, _, err := dc.provider.client.CopyFromContainer(ctx, dc.ID, containerPath)
+		if err != nil {
+			return fmt.Errorf("could not collect '%s' of container '%s': %s", containerPath, dc.ID, err)
+		}
+		defer archive.Close()
+
+		if err := os.MkdirAll(hostDir, 0755); err != nil {
+			return err
+		}
+		return untarTo(archive, hostDir)
+	}
+}
+
+// withinDir checks that the path, e.g. of an entry of an archive, does not escape the directory
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
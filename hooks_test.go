@@ -0,0 +1,112 @@
+package testcontainers
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreTerminateHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	provider := NewProviderMock()
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		c.LogOutput = "shutting down\n"
+		return nil
+	}
+	if err := RegisterProvider("hooks-mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("hooks-mock")
+	ctx := context.Background()
+
+	var called []string
+	failing := func(ctx context.Context, c Container) error {
+		called = append(called, "failing")
+		return errors.New("no heap dump")
+	}
+	recording := func(ctx context.Context, c Container) error {
+		called = append(called, "recording")
+		return nil
+	}
+	req := GenericContainerRequest{ContainerRequest: ContainerRequest{Image: "app"}, Started: true}
+	for _, opt := range []CustomizeRequestOption{
+		WithProvider("hooks-mock"),
+		WithPreTerminateHooks(failing, CollectLogs(dir)),
+		WithPreTerminateHooks(recording),
+	} {
+		if err := opt(&req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := GenericContainer(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Terminate(ctx); err != nil {
+		t.Fatalf("expected the container to be terminated despite the failing hook, got %s", err)
+	}
+	if strings.Join(called, ",") != "failing,recording" {
+		t.Errorf("expected the hooks to be called in order, got %v", called)
+	}
+	logs, err := ioutil.ReadFile(filepath.Join(dir, c.GetContainerID()+".log"))
+	if err != nil || string(logs) != "shutting down\n" {
+		t.Errorf("expected the logs to be collected, got %q, %v", logs, err)
+	}
+}
+
+func TestCollectFiles(t *testing.T) {
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("path") != "/var/lib/app" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-Docker-Container-Path-Stat", "e30=") // {}
+		tw := tar.NewWriter(w)
+		tw.WriteHeader(&tar.Header{Name: "app/", Typeflag: tar.TypeDir, Mode: 0755})
+		tw.WriteHeader(&tar.Header{Name: "app/heap.hprof", Typeflag: tar.TypeReg, Mode: 0600, Size: 4})
+		tw.Write([]byte("heap"))
+		tw.Close()
+	})
+	dir, err := ioutil.TempDir("", "collect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := &DockerContainer{ID: "dumped", provider: provider}
+	ctx := context.Background()
+
+	if err := CollectFiles("/var/lib/app", filepath.Join(dir, "artifacts"))(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "artifacts", "app", "heap.hprof"))
+	if err != nil || string(content) != "heap" {
+		t.Errorf("expected the files to be collected, got %q, %v", content, err)
+	}
+	if err := CollectFiles("/missing", dir)(ctx, c); err == nil {
+		t.Error("expected a missing path not to be collected")
+	}
+}
+
+func TestUntarRefusesEntriesOutsideOfTheDirectory(t *testing.T) {
+	archive := &bytes.Buffer{}
+	tw := tar.NewWriter(archive)
+	tw.WriteHeader(&tar.Header{Name: "../escaped", Typeflag: tar.TypeReg, Mode: 0644})
+	tw.Close()
+
+	if err := untarTo(archive, os.TempDir()); err == nil {
+		t.Fatal("expected an entry outside of the directory to be refused")
+	}
+}
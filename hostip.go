@@ -0,0 +1,76 @@
+package testcontainers
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// hostProbeTimeout bounds how long connecting to a mapped port takes when probing the addresses of the host
+const hostProbeTimeout = 500 * time.Millisecond
+
+// resolveHost resolves the host to an address of the preferred family, see Config.HostIPPreference. The host
+// is returned as it is if it is already an IP address, if it cannot be resolved, or if no address suits.
+// With HostIPAuto, reachable tells whether the mapped ports are reachable on an address.
+func resolveHost(ctx context.Context, host, preference string, reachable func(ip string) bool) string {
+	if preference == HostIPAny || net.ParseIP(host) != nil {
+		return host
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return host
+	}
+	v4 := []string{}
+	v6 := []string{}
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			v4 = append(v4, addr.IP.String())
+		} else {
+			v6 = append(v6, addr.IP.String())
+		}
+	}
+
+	// the addresses of the preferred family come first, those of the other one are the fallback
+	candidates := append(v4, v6...)
+	if preference == HostIPv6 {
+		candidates = append(v6, v4...)
+	}
+	if preference != HostIPAuto {
+		return candidates[0]
+	}
+	for _, ip := range candidates {
+		if reachable(ip) {
+			return ip
+		}
+	}
+
+	return host
+}
+
+// reachableOn tells whether the first TCP port mapped by the container accepts connections on the IP address,
+// true if it maps none, as any address is as good as the others then
+func (c *DockerContainer) reachableOn(ctx context.Context, ip string) bool {
+	ports, err := c.Ports(ctx)
+	if err != nil {
+		return false
+	}
+	for port := range ports {
+		if port.Proto() != "tcp" {
+			continue
+		}
+		mapped, err := c.MappedPort(ctx, port)
+		if err != nil {
+			return false
+		}
+		dialer := net.Dialer{Timeout: hostProbeTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, mapped.Port()))
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+
+	return true
+}
@@ -0,0 +1,49 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+)
+
+func TestResolveHost(t *testing.T) {
+	ctx := context.Background()
+	reachable := func(ip string) bool { return ip == "127.0.0.1" }
+	unreachable := func(ip string) bool { return false }
+
+	if host := resolveHost(ctx, "localhost", HostIPAny, unreachable); host != "localhost" {
+		t.Errorf("expected the host to be kept without preference, got %s", host)
+	}
+	if host := resolveHost(ctx, "::1", HostIPv4, unreachable); host != "::1" {
+		t.Errorf("expected an IP address to be kept, got %s", host)
+	}
+	if host := resolveHost(ctx, "localhost", HostIPv4, unreachable); host != "127.0.0.1" {
+		t.Errorf("expected the IPv4 address of localhost, got %s", host)
+	}
+	// localhost may not resolve to ::1, the IPv4 address is the fallback then
+	if host := resolveHost(ctx, "localhost", HostIPv6, unreachable); host != "::1" && host != "127.0.0.1" {
+		t.Errorf("expected an address of localhost, got %s", host)
+	}
+	if host := resolveHost(ctx, "localhost", HostIPAuto, reachable); host != "127.0.0.1" {
+		t.Errorf("expected the reachable address of localhost, got %s", host)
+	}
+	if host := resolveHost(ctx, "localhost", HostIPAuto, unreachable); host != "localhost" {
+		t.Errorf("expected the host to be kept when no address is reachable, got %s", host)
+	}
+}
+
+func TestPortEndpointBracketsIPv6Hosts(t *testing.T) {
+	c := &ContainerMock{
+		HostName: "::1",
+		PortMap:  nat.PortMap{"80/tcp": {{HostIP: "::", HostPort: "32768"}}},
+	}
+
+	endpoint, err := c.PortEndpoint(context.Background(), "80/tcp", "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint != "http://[::1]:32768" {
+		t.Errorf("expected the IPv6 host to be bracketed, got %s", endpoint)
+	}
+}
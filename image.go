@@ -0,0 +1,25 @@
+package testcontainers
+
+import (
+	"context"
+	"io"
+)
+
+// ImageInfo represents a summary of information about an image
+type ImageInfo struct {
+	ID   string
+	Name string
+}
+
+// ImageProvider allows manipulating images on an arbitrary system
+type ImageProvider interface {
+	PullImage(context.Context, string) error                // pull an image, retrying on transient failures
+	ListImages(context.Context) ([]ImageInfo, error)        // list images
+	RemoveImage(context.Context, string, bool) error        // remove an image, forcing the removal if requested
+	PruneImages(context.Context, string) error              // prune unused images labelled with the given session id
+	SaveImages(context.Context, io.Writer, ...string) error // save images as a tar archive into the writer
+	LoadImage(context.Context, io.Reader) error             // load images from a tar archive
+
+	// CopyFileFromImage reads the file at the path of the image without starting a container of it
+	CopyFileFromImage(ctx context.Context, image, filePath string) ([]byte, error)
+}
@@ -0,0 +1,81 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ImageCheck checks the image of a container once it is pulled or built, and before the container is created,
+// e.g. to scan it with Trivy or Grype, or to enforce the policy of the images run in production. The image is
+// referred to as in the request, or by the tag of the image built from a Dockerfile. An error vetoes the creation.
+type ImageCheck func(ctx context.Context, image string) error
+
+// ImageRejectedError is the failure of a container whose image was vetoed by an ImageCheck
+type ImageRejectedError struct {
+	Image string
+	Err   error
+}
+
+func (e *ImageRejectedError) Error() string {
+	return fmt.Sprintf("image '%s' is rejected: %s", e.Image, e.Err)
+}
+
+// Cause gets the error of the check, for errors.Cause
+func (e *ImageRejectedError) Cause() error {
+	return e.Err
+}
+
+// Unwrap gets the error of the check, for errors.Is and errors.As
+func (e *ImageRejectedError) Unwrap() error {
+	return e.Err
+}
+
+type registeredImageCheck struct {
+	id    int
+	check ImageCheck
+}
+
+var (
+	imageChecksMutex sync.Mutex
+	imageChecks      []registeredImageCheck
+	nextImageCheckID int
+)
+
+// OnImageReady registers a check of the images of all the containers created by the process, called before
+// each container is created, after the checks registered before it. The returned func unregisters the check.
+func OnImageReady(check ImageCheck) (remove func()) {
+	imageChecksMutex.Lock()
+	defer imageChecksMutex.Unlock()
+
+	id := nextImageCheckID
+	nextImageCheckID++
+	imageChecks = append(imageChecks, registeredImageCheck{id: id, check: check})
+
+	return func() {
+		imageChecksMutex.Lock()
+		defer imageChecksMutex.Unlock()
+
+		for i, c := range imageChecks {
+			if c.id == id {
+				imageChecks = append(imageChecks[:i:i], imageChecks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// checkImage runs the registered checks of the image, and fails with an ImageRejectedError at the first veto
+func checkImage(ctx context.Context, image string) error {
+	imageChecksMutex.Lock()
+	checks := imageChecks
+	imageChecksMutex.Unlock()
+
+	for _, c := range checks {
+		if err := c.check(ctx, image); err != nil {
+			return &ImageRejectedError{Image: image, Err: err}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestImageChecksVetoTheCreation(t *testing.T) {
+	created := 0
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "/images/"):
+			fmt.Fprintln(w, `{"Id":"sha256:image"}`)
+		case strings.HasSuffix(r.URL.Path, "/containers/create"):
+			created++
+			fmt.Fprintln(w, `{"Id":"created"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	checked := []string{}
+	removeRecording := OnImageReady(func(ctx context.Context, image string) error {
+		checked = append(checked, image)
+		return nil
+	})
+	defer removeRecording()
+	vulnerable := errors.New("CVE-2021-44228 is not fixed")
+	removePolicy := OnImageReady(func(ctx context.Context, image string) error {
+		if strings.HasPrefix(image, "log4shell") {
+			return vulnerable
+		}
+		return nil
+	})
+	ctx := context.Background()
+
+	_, err := provider.CreateContainer(ctx, ContainerRequest{Image: "log4shell:1.0", SkipReaper: true})
+	var rejected *ImageRejectedError
+	if !errors.As(err, &rejected) || rejected.Image != "log4shell:1.0" || !errors.Is(err, vulnerable) {
+		t.Fatalf("expected the image to be rejected, got %v", err)
+	}
+	if created > 0 {
+		t.Error("expected the container of the rejected image not to be created")
+	}
+
+	removePolicy()
+	if _, err := provider.CreateContainer(ctx, ContainerRequest{Image: "log4shell:1.0", SkipReaper: true}); err != nil {
+		t.Fatal(err)
+	}
+	untrackForCleanup("created")
+	if created != 1 || strings.Join(checked, ",") != "log4shell:1.0,log4shell:1.0" {
+		t.Errorf("expected the container to be created once checked, got %d containers and checks %v", created, checked)
+	}
+}
@@ -0,0 +1,25 @@
+package testcontainers
+
+import (
+	"context"
+	"time"
+)
+
+// InspectCacheTTL is how long the inspection of a container is cached, so that the lookups of its ports and
+// endpoints in hot paths do not all hit the daemon. The cache is invalidated when the container is started,
+// stopped or attached to networks, and WithFreshInspect bypasses it.
+var InspectCacheTTL = time.Second
+
+type freshInspectKey struct{}
+
+// WithFreshInspect returns a context with which the methods of containers inspect them again rather than
+// reading the cached inspection, e.g. after changing the container outside of testcontainers
+func WithFreshInspect(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshInspectKey{}, true)
+}
+
+// freshInspect tells whether the context asks for a fresh inspection
+func freshInspect(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshInspectKey{}).(bool)
+	return fresh
+}
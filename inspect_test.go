@@ -0,0 +1,104 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestInspectCache(t *testing.T) {
+	inspections := 0
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/cached/json"):
+			inspections++
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: "cached", State: &types.ContainerState{Running: true}},
+				NetworkSettings:   &types.NetworkSettings{},
+			})
+		case strings.HasSuffix(r.URL.Path, "/containers/cached/stop"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+	c := &DockerContainer{ID: "cached", provider: provider}
+
+	for _, step := range []struct {
+		name        string
+		do          func() error
+		inspections int
+	}{
+		{"first lookup", func() error { _, err := c.Ports(ctx); return err }, 1},
+		{"cached lookup", func() error { _, err := c.Ports(ctx); return err }, 1},
+		{"fresh lookup", func() error { _, err := c.Ports(WithFreshInspect(ctx)); return err }, 2},
+		{"state", func() error { _, err := c.State(ctx); return err }, 3},
+		{"stop", func() error { return c.Stop(ctx) }, 3},
+		{"lookup after stop", func() error { _, err := c.Ports(ctx); return err }, 4},
+		{"expired lookup", func() error {
+			c.rawTime = time.Now().Add(-InspectCacheTTL)
+			_, err := c.Ports(ctx)
+			return err
+		}, 5},
+	} {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %s", step.name, err)
+		}
+		if inspections != step.inspections {
+			t.Fatalf("%s: expected %d inspections, got %d", step.name, step.inspections, inspections)
+		}
+	}
+}
+
+func TestPortsAreCachedOnceStarted(t *testing.T) {
+	inspections := 0
+	hostPort := "32768"
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/web/json"):
+			inspections++
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: "web", State: &types.ContainerState{Running: true}},
+				NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{
+					Ports: nat.PortMap{"80/tcp": {{HostIP: "0.0.0.0", HostPort: hostPort}}},
+				}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/containers/web/start"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+	c := &DockerContainer{ID: "web", provider: provider}
+
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c.ResetCache(ctx)
+	for i := 0; i < 3; i++ {
+		if port, err := c.MappedPort(ctx, "80/tcp"); err != nil || port.Port() != "32768" {
+			t.Fatalf("expected the mapped port, got %s and %v", port, err)
+		}
+	}
+	if inspections != 1 {
+		t.Fatalf("expected the ports to be read once, got %d inspections", inspections)
+	}
+
+	hostPort = "32999"
+	if err := c.RefreshPorts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if port, _ := c.MappedPort(ctx, "80/tcp"); port.Port() != "32999" {
+		t.Fatalf("expected the refreshed port, got %s", port)
+	}
+}
@@ -27,7 +27,7 @@ func (c *DockerContainer) GetHostEndpoint(ctx context.Context, port string) (str
 }
 
 // GetIPAddress is deprecated and kept for backwards compat
-// Deprecated: Use IPAddress()
+// Deprecated: Use Host()
 func (c *DockerContainer) GetIPAddress(ctx context.Context) (string, error) {
 	return c.Host(ctx)
 }
@@ -36,15 +36,16 @@ func (c *DockerContainer) GetIPAddress(ctx context.Context) (string, error) {
 // Deprecated: Use Ports()
 func (c *DockerContainer) LivenessCheckPorts(ctx context.Context) (nat.PortSet, error) {
 	ports, err := c.Ports(ctx)
-	var portSet nat.PortSet
+	portSet := nat.PortSet{}
 	for port := range ports {
 		portSet[port] = struct{}{}
 	}
 	return portSet, err
 }
 
-// RequestContainer supplies input parameters for a container
-// Deprecated: Use ContainerRequest with provider pattern
+// RequestContainer supplies input parameters for a container, its fields match the ones of ContainerRequest
+// except ExportedPort, which is ContainerRequest.ExposedPorts
+// Deprecated: Use Run with options, or ContainerRequest with GenericContainer
 type RequestContainer struct {
 	Env          map[string]string
 	ExportedPort []string
@@ -53,23 +54,20 @@ type RequestContainer struct {
 	WaitingFor   wait.Strategy
 }
 
-// RunContainer takes a RequestContainer as input and it runs a container via the docker sdk
-// Deprecated: Use GenericContainer()
+// RunContainer takes a RequestContainer as input and it runs a container via the docker sdk.
+// It is a shim over Run, which takes the same parameters as options:
+//
+//	Run(ctx, image, WithEnv(env), WithExposedPorts(ports...), WithCmd(cmd), WithWaitStrategy(strategy))
+//
+// Deprecated: Use Run() or GenericContainer()
 func RunContainer(ctx context.Context, containerImage string, input RequestContainer) (DeprecatedContainer, error) {
-	req := ContainerRequest{
-		Image:        containerImage,
-		Env:          input.Env,
-		ExposedPorts: input.ExportedPort,
-		Cmd:          input.Cmd,
-		RegistryCred: input.RegistryCred,
-		WaitingFor:   input.WaitingFor,
-	}
-
-	container, err := GenericContainer(ctx, GenericContainerRequest{
-		ContainerRequest: req,
-		ProviderType:     ProviderDocker,
-		Started:          true,
-	})
+	container, err := Run(ctx, containerImage,
+		WithEnv(input.Env),
+		WithExposedPorts(input.ExportedPort...),
+		WithCmd(input.Cmd),
+		WithRegistryCred(input.RegistryCred),
+		WithWaitStrategy(input.WaitingFor),
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to launch generic container")
 	}
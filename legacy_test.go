@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
@@ -242,3 +243,31 @@ func TestLegacyContainerCreationTimesOutWithHttp(t *testing.T) {
 		t.Error("Expected timeout")
 	}
 }
+
+func TestLegacyLivenessCheckPorts(t *testing.T) {
+	// the inspection is cached, so that no daemon is needed
+	c := &DockerContainer{
+		rawTime: time.Now(),
+		raw: &types.ContainerJSON{
+			NetworkSettings: &types.NetworkSettings{
+				NetworkSettingsBase: types.NetworkSettingsBase{
+					Ports: nat.PortMap{
+						"80/tcp":   []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "32768"}},
+						"6379/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "32769"}},
+					},
+				},
+			},
+		},
+	}
+
+	ports, err := c.LivenessCheckPorts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ports) != 2 {
+		t.Fatalf("expected the 2 ports of the container, got %v", ports)
+	}
+	if _, ok := ports["6379/tcp"]; !ok {
+		t.Fatalf("expected port 6379/tcp, got %v", ports)
+	}
+}
@@ -0,0 +1,48 @@
+package testcontainers
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+
+	errorLockViolation syscall.Errno = 33
+)
+
+// tryLockFile takes an exclusive lock on the first byte of the file at path, which is created if needed.
+// It returns a nil function if the file is locked by another process. As flock on Unix, the lock is released
+// by the system if the process dies, but it is held by the handle rather than by the process: the lock is
+// mandatory, the other handles cannot read or write the locked byte, and it conflicts with the handles of
+// the same process too. The file is kept, as on Unix, since removing it would race with the next locker.
+func tryLockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	overlapped := &syscall.Overlapped{}
+	r, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock|lockfileFailImmediately, 0, 1, 0,
+		uintptr(unsafe.Pointer(overlapped)))
+	if r == 0 {
+		f.Close()
+		if err == errorLockViolation {
+			return nil, nil
+		}
+		return nil, os.NewSyscallError("LockFileEx", err)
+	}
+
+	return func() {
+		procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+		f.Close()
+	}, nil
+}
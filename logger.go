@@ -0,0 +1,15 @@
+package testcontainers
+
+import (
+	"log"
+	"os"
+)
+
+// Logger is the default log instance, used by the library to report progress
+// such as image build output. It can be replaced to redirect those messages
+var Logger Logging = log.New(os.Stderr, "", log.LstdFlags)
+
+// Logging defines the Logger interface
+type Logging interface {
+	Printf(format string, v ...interface{})
+}
@@ -0,0 +1,157 @@
+package testcontainers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Log is a line of the output of a container
+type Log struct {
+	Stream  string    // "stdout" or "stderr"
+	Time    time.Time // when the container wrote the line
+	Content string    // the line, without its line break
+}
+
+// LogConsumer consumes the lines of the output of a container as the container writes them
+type LogConsumer interface {
+	Accept(Log)
+}
+
+// FollowLogs streams the output of the container to the consumers, line by line, until the container
+// stops or the context is done. Containers of other providers than Docker only stream the logs written
+// so far.
+func FollowLogs(ctx context.Context, c Container, consumers ...LogConsumer) error {
+	return followLogs(ctx, c, "", consumers)
+}
+
+// followLogs streams the output of the container written since the timestamp of the daemon, all of it if empty
+func followLogs(ctx context.Context, c Container, since string, consumers []LogConsumer) error {
+	dc, ok := c.(*DockerContainer)
+	if !ok {
+		logs, err := c.Logs(ctx)
+		if err != nil {
+			return err
+		}
+		defer logs.Close()
+		return consumeLogLines(logs, "stdout", false, consumers)
+	}
+
+	logs, err := dc.provider.client.ContainerLogs(ctx, dc.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+		Since:      since,
+	})
+	if err != nil {
+		return fmt.Errorf("could not follow the logs of container %s: %s", dc.ID, err)
+	}
+	defer logs.Close()
+
+	stdout, stdoutWriter := io.Pipe()
+	stderr, stderrWriter := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(stdoutWriter, stderrWriter, logs)
+		stdoutWriter.CloseWithError(err)
+		stderrWriter.CloseWithError(err)
+	}()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, stream := range []struct {
+		name   string
+		reader io.Reader
+	}{{"stdout", stdout}, {"stderr", stderr}} {
+		wg.Add(1)
+		go func(i int, name string, reader io.Reader) {
+			defer wg.Done()
+			errs[i] = consumeLogLines(reader, name, true, consumers)
+		}(i, stream.name, stream.reader)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil && err != context.Canceled {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// consumeLogLines sends the lines of the stream to the consumers, the lines being prefixed
+// with their timestamp if timestamps is set
+func consumeLogLines(r io.Reader, stream string, timestamps bool, consumers []LogConsumer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		log := Log{Stream: stream, Time: time.Now(), Content: scanner.Text()}
+		if timestamps {
+			if i := strings.IndexByte(log.Content, ' '); i > 0 {
+				if t, err := time.Parse(time.RFC3339Nano, log.Content[:i]); err == nil {
+					log.Time, log.Content = t, log.Content[i+1:]
+				}
+			}
+		}
+		for _, consumer := range consumers {
+			consumer.Accept(log)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// ANSI colors of the prefixes of the logs of the containers, told apart by their color
+var logColors = []string{"\x1b[36m", "\x1b[33m", "\x1b[35m", "\x1b[32m", "\x1b[34m", "\x1b[31m"}
+
+// testingLogConsumer writes the logs of a container to the log of a test
+type testingLogConsumer struct {
+	t      testing.TB
+	prefix string
+
+	mutex sync.Mutex
+	done  bool
+}
+
+// NewTestingLogConsumer returns a LogConsumer writing the lines of the output of a container to the log
+// of the test with t.Logf, with their time and the given prefix, e.g. the name of the service, so that the
+// interleaved logs of several containers are readable. The prefix is colored, unless the NO_COLOR env
+// variable is set. The lines written once the test completed are dropped, as t.Logf cannot be called then.
+func NewTestingLogConsumer(t testing.TB, prefix string) LogConsumer {
+	if _, noColor := os.LookupEnv("NO_COLOR"); !noColor {
+		h := fnv.New32a()
+		h.Write([]byte(prefix))
+		prefix = logColors[h.Sum32()%uint32(len(logColors))] + prefix + "\x1b[0m"
+	}
+
+	consumer := &testingLogConsumer{t: t, prefix: prefix}
+	t.Cleanup(func() {
+		consumer.mutex.Lock()
+		consumer.done = true
+		consumer.mutex.Unlock()
+	})
+
+	return consumer
+}
+
+// Accept writes the line to the log of the test, unless the test completed
+func (c *testingLogConsumer) Accept(log Log) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.done {
+		return
+	}
+	c.t.Logf("%s %s | %s", log.Time.Format("15:04:05.000"), c.prefix, log.Content)
+}
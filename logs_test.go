@@ -0,0 +1,75 @@
+package testcontainers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// collectingLogConsumer collects the logs it accepts
+type collectingLogConsumer struct {
+	mutex sync.Mutex
+	logs  []Log
+}
+
+func (c *collectingLogConsumer) Accept(log Log) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.logs = append(c.logs, log)
+}
+
+func TestFollowLogs(t *testing.T) {
+	var query string
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/containers/app/logs") {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		stdcopy.NewStdWriter(w, stdcopy.Stdout).Write([]byte("2019-07-17T16:10:51.123456789Z listening on :8080\n"))
+		stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte("2019-07-17T16:10:52Z connection refused\n"))
+	})
+
+	consumer := &collectingLogConsumer{}
+	if err := FollowLogs(context.Background(), &DockerContainer{ID: "app", provider: provider}, consumer); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(query, "follow=1") || !strings.Contains(query, "timestamps=1") {
+		t.Fatalf("expected the logs to be followed with timestamps, got %s", query)
+	}
+	sort.Slice(consumer.logs, func(i, j int) bool { return consumer.logs[i].Time.Before(consumer.logs[j].Time) })
+	if len(consumer.logs) != 2 {
+		t.Fatalf("expected 2 lines, got %v", consumer.logs)
+	}
+	first, second := consumer.logs[0], consumer.logs[1]
+	if first.Stream != "stdout" || first.Content != "listening on :8080" || first.Time.Nanosecond() != 123456789 {
+		t.Fatalf("unexpected first line %+v", first)
+	}
+	if second.Stream != "stderr" || second.Content != "connection refused" {
+		t.Fatalf("unexpected second line %+v", second)
+	}
+}
+
+func TestTestingLogConsumer(t *testing.T) {
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+
+	ft := &fakeT{}
+	consumer := NewTestingLogConsumer(ft, "postgres")
+	at := time.Date(2019, 7, 17, 16, 10, 51, 0, time.UTC)
+	consumer.Accept(Log{Stream: "stdout", Time: at, Content: "database system is ready"})
+	ft.cleanup()
+	consumer.Accept(Log{Stream: "stdout", Time: at, Content: "shutting down"})
+
+	if len(ft.logs) != 1 || ft.logs[0] != "16:10:51.000 postgres | database system is ready" {
+		t.Fatalf("expected the line to be logged until the test completes, got %v", ft.logs)
+	}
+}
@@ -0,0 +1,183 @@
+package testcontainers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StartupPhase is a step of the startup of a container
+type StartupPhase string
+
+// the steps of the startup of a container, in order
+const (
+	PhasePull   StartupPhase = "pull"
+	PhaseBuild  StartupPhase = "build"
+	PhaseCreate StartupPhase = "create"
+	PhaseStart  StartupPhase = "start"
+	PhaseWait   StartupPhase = "wait"
+)
+
+// PhaseTiming is how long a step of the startup of a container took
+type PhaseTiming struct {
+	ContainerID string
+	Image       string
+	Phase       StartupPhase
+	Duration    time.Duration
+}
+
+// ContainerMetrics are the durations of the steps of the startup of a container
+type ContainerMetrics struct {
+	ContainerID string
+	Image       string        // the image of the request, the Dockerfile context for built images
+	Pull        time.Duration // pulling the image, 0 if it was present
+	Build       time.Duration // building the image from a Dockerfile
+	Create      time.Duration // creating the container and attaching it to its networks
+	Start       time.Duration // starting the container
+	Wait        time.Duration // waiting for the container to be ready
+}
+
+// Total is the duration of the startup, from the pull of the image until the container is ready
+func (m ContainerMetrics) Total() time.Duration {
+	return m.Pull + m.Build + m.Create + m.Start + m.Wait
+}
+
+func (m ContainerMetrics) String() string {
+	steps := []string{}
+	for _, phase := range []StartupPhase{PhasePull, PhaseBuild, PhaseCreate, PhaseStart, PhaseWait} {
+		if d := *m.phase(phase); d > 0 {
+			steps = append(steps, fmt.Sprintf("%s %.1fs", phase, d.Seconds()))
+		}
+	}
+
+	return fmt.Sprintf("%s ready in %.1fs (%s)", m.Image, m.Total().Seconds(), strings.Join(steps, ", "))
+}
+
+// phase gets the duration of the step of the startup
+func (m *ContainerMetrics) phase(phase StartupPhase) *time.Duration {
+	switch phase {
+	case PhasePull:
+		return &m.Pull
+	case PhaseBuild:
+		return &m.Build
+	case PhaseCreate:
+		return &m.Create
+	case PhaseStart:
+		return &m.Start
+	default:
+		return &m.Wait
+	}
+}
+
+// the metrics of the containers created by the process, in the order of their creation,
+// and the hooks they are reported to
+var (
+	metricsMutex sync.Mutex
+	metrics      []*ContainerMetrics
+	metricsByID  = map[string]*ContainerMetrics{}
+	phaseHooks   = map[int]func(PhaseTiming){}
+	nextHookID   int
+)
+
+// OnStartupPhase registers a hook called with the timing of each step of the startup of every container
+// created by the process, once the step completes, e.g. to report them to a benchmark or a custom reporter.
+// The pull and the build of the image are reported once the container is created. The hook is called
+// from the goroutine starting the container, concurrently for containers started concurrently.
+// The returned func unregisters the hook.
+func OnStartupPhase(hook func(PhaseTiming)) (remove func()) {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+
+	id := nextHookID
+	nextHookID++
+	phaseHooks[id] = hook
+
+	return func() {
+		metricsMutex.Lock()
+		defer metricsMutex.Unlock()
+
+		delete(phaseHooks, id)
+	}
+}
+
+// recordMetrics records the metrics of the creation of a container
+func recordMetrics(m ContainerMetrics) {
+	metricsMutex.Lock()
+	metrics = append(metrics, &m)
+	metricsByID[m.ContainerID] = &m
+	hooks := currentPhaseHooks()
+	metricsMutex.Unlock()
+
+	for _, phase := range []StartupPhase{PhasePull, PhaseBuild, PhaseCreate} {
+		if d := *m.phase(phase); d > 0 {
+			reportPhase(hooks, PhaseTiming{ContainerID: m.ContainerID, Image: m.Image, Phase: phase, Duration: d})
+		}
+	}
+}
+
+// recordPhase records how long a step of the startup of the container took, if its creation was recorded
+func recordPhase(id string, phase StartupPhase, d time.Duration) {
+	metricsMutex.Lock()
+	m, ok := metricsByID[id]
+	if !ok {
+		metricsMutex.Unlock()
+		return
+	}
+	*m.phase(phase) = d
+	timing := PhaseTiming{ContainerID: id, Image: m.Image, Phase: phase, Duration: d}
+	hooks := currentPhaseHooks()
+	metricsMutex.Unlock()
+
+	reportPhase(hooks, timing)
+}
+
+// currentPhaseHooks gets the hooks registered, the metrics lock being held
+func currentPhaseHooks() []func(PhaseTiming) {
+	hooks := make([]func(PhaseTiming), 0, len(phaseHooks))
+	for _, hook := range phaseHooks {
+		hooks = append(hooks, hook)
+	}
+	return hooks
+}
+
+// reportPhase calls the hooks, outside of the metrics lock so that they can read the metrics
+func reportPhase(hooks []func(PhaseTiming), timing PhaseTiming) {
+	for _, hook := range hooks {
+		hook(timing)
+	}
+}
+
+// StartupMetrics returns the metrics of the startup of the containers created by the process,
+// in the order of their creation
+func StartupMetrics() []ContainerMetrics {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+
+	result := make([]ContainerMetrics, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, *m)
+	}
+
+	return result
+}
+
+// StartupSummary describes how long each container created by the process took to be ready, one per line,
+// e.g. "postgres:14 ready in 8.2s (pull 3.1s, create 0.2s, start 0.5s, wait 4.4s)". It is logged by
+// TerminateSession when the TC_STARTUP_SUMMARY env variable is "true".
+func StartupSummary() string {
+	lines := []string{}
+	for _, m := range StartupMetrics() {
+		lines = append(lines, m.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// startupSummaryEnabled tells whether the summary is logged at the end of the session
+func startupSummaryEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("TC_STARTUP_SUMMARY"))
+	return enabled
+}
@@ -0,0 +1,84 @@
+package testcontainers
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContainerMetricsString(t *testing.T) {
+	m := ContainerMetrics{Image: "postgres:14", Pull: 3100 * time.Millisecond, Create: 200 * time.Millisecond, Start: 500 * time.Millisecond, Wait: 4400 * time.Millisecond}
+
+	expected := "postgres:14 ready in 8.2s (pull 3.1s, create 0.2s, start 0.5s, wait 4.4s)"
+	if m.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, m.String())
+	}
+}
+
+func TestStartRecordsMetrics(t *testing.T) {
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/containers/measured/start") {
+			http.NotFound(w, r)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	recordMetrics(ContainerMetrics{ContainerID: "measured", Image: "redis", Create: time.Second})
+
+	c := &DockerContainer{ID: "measured", provider: provider}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range StartupMetrics() {
+		if m.ContainerID != "measured" {
+			continue
+		}
+		if m.Start < 10*time.Millisecond || m.Create != time.Second {
+			t.Fatalf("expected the start to be measured, got %+v", m)
+		}
+		if !strings.Contains(StartupSummary(), "redis ready in") {
+			t.Fatalf("expected the container in the summary, got %q", StartupSummary())
+		}
+		return
+	}
+	t.Fatal("expected the metrics of the container")
+}
+
+func TestOnStartupPhase(t *testing.T) {
+	var timings []PhaseTiming
+	remove := OnStartupPhase(func(timing PhaseTiming) {
+		timings = append(timings, timing)
+	})
+	recordMetrics(ContainerMetrics{ContainerID: "hooked", Image: "redis", Pull: time.Second, Create: time.Millisecond})
+	recordPhase("hooked", PhaseStart, 2*time.Millisecond)
+	recordPhase("unknown", PhaseStart, time.Millisecond)
+	remove()
+	recordPhase("hooked", PhaseWait, time.Second)
+
+	expected := []PhaseTiming{
+		{ContainerID: "hooked", Image: "redis", Phase: PhasePull, Duration: time.Second},
+		{ContainerID: "hooked", Image: "redis", Phase: PhaseCreate, Duration: time.Millisecond},
+		{ContainerID: "hooked", Image: "redis", Phase: PhaseStart, Duration: 2 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(timings, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, timings)
+	}
+}
+
+func TestReportStartupMetrics(t *testing.T) {
+	result := testing.Benchmark(func(b *testing.B) {
+		ReportStartupMetrics(b)
+		for i := 0; i < b.N; i++ {
+			recordMetrics(ContainerMetrics{ContainerID: "benchmarked", Image: "redis", Create: time.Millisecond})
+		}
+	})
+
+	if result.Extra["create-ns/op"] != float64(time.Millisecond) {
+		t.Fatalf("expected the create time per op, got %v", result.Extra)
+	}
+}
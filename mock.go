@@ -0,0 +1,895 @@
+package testcontainers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProviderMock is an in-memory provider which does not need a Docker daemon, so that libraries built
+// on top of testcontainers can unit test their orchestration logic. The containers it creates are
+// ContainerMocks, which can be scripted in OnCreate. Wait strategies are not run, as nothing listens
+// on the ports of the mocks. It can be selected in generic requests once registered with RegisterProvider.
+type ProviderMock struct {
+	// OnCreate is called with each container before it is returned by CreateContainer, to script
+	// its behaviour. Returning an error fails the creation.
+	OnCreate func(req ContainerRequest, c *ContainerMock) error
+	// Host is the host where the ports of the containers are exposed, "localhost" if empty
+	Host string
+
+	mutex      sync.Mutex
+	containers []*ContainerMock
+	networks   map[string]*NetworkMock
+	volumes    map[string]*VolumeMock
+	nextPort   int
+}
+
+// NewProviderMock creates an empty mock provider
+func NewProviderMock() *ProviderMock {
+	return &ProviderMock{}
+}
+
+// CreateContainer creates a mock container, created but not running, with its exposed ports
+// mapped to increasing host ports
+func (p *ProviderMock) CreateContainer(ctx context.Context, req ContainerRequest) (Container, error) {
+	_, bindings, err := nat.ParsePortSpecs(req.ExposedPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if req.Name != "" {
+		for _, c := range p.containers {
+			if c.Request.Name == req.Name && !c.removed {
+				return nil, fmt.Errorf("container name '%s' is already in use", req.Name)
+			}
+		}
+	}
+
+	host := p.Host
+	if host == "" {
+		host = "localhost"
+	}
+	c := &ContainerMock{
+		ID:          uuid.NewV4().String(),
+		Request:     req,
+		HostName:    host,
+		PortMap:     nat.PortMap{},
+		Networks:    map[string][]string{},
+		IPAddresses: map[string]string{},
+		status:      "created",
+	}
+	for port := range bindings {
+		if p.nextPort == 0 {
+			p.nextPort = 32768
+		}
+		c.PortMap[port] = []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: fmt.Sprintf("%d", p.nextPort)}}
+		p.nextPort++
+	}
+	for _, name := range req.Networks {
+		c.Networks[name] = req.NetworkAliases[name]
+		c.IPAddresses[name] = nextMockIP()
+		if ip, ok := req.NetworkIPs[name]; ok {
+			c.IPAddresses[name] = ip
+		}
+	}
+	if len(req.Networks) == 0 && req.NetworkMode == "" {
+		c.IPAddresses["bridge"] = nextMockIP()
+	}
+	if err := copyFilesToContainer(ctx, c, req.Files); err != nil {
+		return nil, err
+	}
+
+	if p.OnCreate != nil {
+		if err := p.OnCreate(req, c); err != nil {
+			return nil, err
+		}
+	}
+	p.containers = append(p.containers, c)
+
+	return c, nil
+}
+
+// CreateFromExistentContainer gets the mock container with the given name
+func (p *ProviderMock) CreateFromExistentContainer(ctx context.Context, name string) (Container, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, c := range p.containers {
+		if c.Request.Name == name && !c.removed {
+			return c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("container '%s' not found", name)
+}
+
+// ReuseOrCreateContainer gets the mock container defined by the request, by its name or by its hash
+// if it has no name, or creates it. A container of the same name with another definition is removed.
+func (p *ProviderMock) ReuseOrCreateContainer(ctx context.Context, req ContainerRequest) (Container, error) {
+	if req.ShouldBuildImage() {
+		return nil, errors.New("a container built from a Dockerfile cannot be reused")
+	}
+	req.SkipReaper = true
+	req.DontRemove = true
+	hash := req.Hash()
+	req.Labels = withLabel(req.Labels, TestcontainerLabelHash, hash)
+
+	if c := p.findReusableContainer(req, hash); c != nil {
+		return c, nil
+	}
+
+	return p.CreateContainer(ctx, req)
+}
+
+// findReusableContainer gets the mock container of the request with the given hash, nil if there is none
+func (p *ProviderMock) findReusableContainer(req ContainerRequest, hash string) *ContainerMock {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, c := range p.containers {
+		if c.removed || (req.Name != "" && c.Request.Name != req.Name) {
+			continue
+		}
+		if c.Request.Labels[TestcontainerLabelHash] == hash {
+			return c
+		}
+		if req.Name != "" {
+			c.mutex.Lock()
+			c.removed = true
+			c.mutex.Unlock()
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// RunContainer creates and starts a mock container
+func (p *ProviderMock) RunContainer(ctx context.Context, req ContainerRequest) (Container, error) {
+	c, err := p.CreateContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Start(ctx); err != nil {
+		return c, errors.Wrap(err, "could not start container")
+	}
+
+	return c, nil
+}
+
+// ListContainers lists the mock containers which are not removed, including the stopped ones if all is set
+func (p *ProviderMock) ListContainers(ctx context.Context, all bool) ([]Container, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	containers := []Container{}
+	for _, c := range p.containers {
+		c.mutex.Lock()
+		listed := !c.removed && (all || c.status == "running")
+		c.mutex.Unlock()
+		if listed {
+			containers = append(containers, c)
+		}
+	}
+
+	return containers, nil
+}
+
+// ContainerExists checks if a mock container with the given name exists
+func (p *ProviderMock) ContainerExists(ctx context.Context, name string) (bool, error) {
+	_, err := p.CreateFromExistentContainer(ctx, name)
+	return err == nil, nil
+}
+
+// FindContainerByName gets the mock container with the given name
+func (p *ProviderMock) FindContainerByName(ctx context.Context, name string) (Container, error) {
+	return p.CreateFromExistentContainer(ctx, name)
+}
+
+// Containers gets all the containers created by the mock, including the removed ones,
+// to assert on what the code under test did
+func (p *ProviderMock) Containers() []*ContainerMock {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]*ContainerMock{}, p.containers...)
+}
+
+// CreateNetwork creates a mock network
+func (p *ProviderMock) CreateNetwork(ctx context.Context, req NetworkRequest) (Network, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.networks == nil {
+		p.networks = map[string]*NetworkMock{}
+	}
+	if req.Reuse && req.Name == "" {
+		return nil, errors.New("a network can only be reused by name")
+	}
+	if n, ok := p.networks[req.Name]; ok && req.Reuse {
+		return n, nil
+	}
+	if _, ok := p.networks[req.Name]; ok && req.CheckDuplicate {
+		return nil, fmt.Errorf("network with name %s already exists", req.Name)
+	}
+	n := &NetworkMock{Request: req, provider: p}
+	p.networks[req.Name] = n
+
+	return n, nil
+}
+
+// CreateVolume creates a mock volume
+func (p *ProviderMock) CreateVolume(ctx context.Context, req VolumeRequest) (Volume, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.volumes == nil {
+		p.volumes = map[string]*VolumeMock{}
+	}
+	if req.Name == "" {
+		req.Name = uuid.NewV4().String()
+	}
+	v := &VolumeMock{Request: req, provider: p}
+	p.volumes[req.Name] = v
+
+	return v, nil
+}
+
+// NetworkMock is a network created by ProviderMock
+type NetworkMock struct {
+	Request NetworkRequest
+
+	provider *ProviderMock
+}
+
+// Remove removes the mock network
+func (n *NetworkMock) Remove(ctx context.Context) error {
+	n.provider.mutex.Lock()
+	defer n.provider.mutex.Unlock()
+
+	if n.provider.networks[n.Request.Name] != n {
+		return fmt.Errorf("network %s not found", n.Request.Name)
+	}
+	delete(n.provider.networks, n.Request.Name)
+
+	return nil
+}
+
+// VolumeMock is a volume created by ProviderMock
+type VolumeMock struct {
+	Request VolumeRequest
+
+	provider *ProviderMock
+}
+
+// GetName gets the name of the mock volume
+func (v *VolumeMock) GetName() string {
+	return v.Request.Name
+}
+
+// Remove removes the mock volume
+func (v *VolumeMock) Remove(ctx context.Context) error {
+	v.provider.mutex.Lock()
+	defer v.provider.mutex.Unlock()
+
+	if v.provider.volumes[v.Request.Name] != v {
+		return fmt.Errorf("volume %s not found", v.Request.Name)
+	}
+	delete(v.provider.volumes, v.Request.Name)
+
+	return nil
+}
+
+// ContainerMock is a container created by ProviderMock. Its exported fields script its behaviour,
+// they can be set in the OnCreate function of the provider.
+type ContainerMock struct {
+	ID       string
+	Request  ContainerRequest
+	HostName string              // the host where the ports are exposed
+	PortMap  nat.PortMap         // the mapped ports of the container
+	Networks map[string][]string // the networks the container is attached to, with its aliases
+
+	// IPAddresses are the IP addresses of the container, keyed by network, generated unless set in NetworkIPs
+	IPAddresses map[string]string
+
+	// LogOutput is what Logs returns
+	LogOutput string
+	// StateTransitions are the statuses returned by successive calls to State once the container is started,
+	// e.g. "running" then "exited" for a container which crashes. The last one is kept once they are consumed.
+	StateTransitions []string
+	// HealthTransitions are the health statuses returned by successive calls to State once the container is
+	// started, e.g. "starting" then "healthy". The last one is kept once they are consumed, no health if empty.
+	HealthTransitions []string
+	// ExitCode is the exit code reported once the container is exited
+	ExitCode int
+	// IgnoresStopSignal makes StopGracefully kill the container, as if the grace period elapsed
+	IgnoresStopSignal bool
+	// OnExec is called with the commands run by Exec to get their exit code and output, 0 and nothing if nil
+	OnExec func(cmd []string) (exitCode int, output string, err error)
+
+	// errors returned by the lifecycle methods, to test failure handling
+	StartErr     error
+	StopErr      error
+	TerminateErr error
+
+	mutex   sync.Mutex
+	status  string
+	removed bool
+	calls   []string
+	signals []string
+	files   map[string][]byte
+}
+
+func (c *ContainerMock) record(call string) {
+	c.calls = append(c.calls, call)
+}
+
+// Calls gets the names of the lifecycle methods called on the container, in order
+func (c *ContainerMock) Calls() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.calls...)
+}
+
+// GetContainerID gets the ID of the mock container
+func (c *ContainerMock) GetContainerID() string {
+	return c.ID
+}
+
+// Endpoint gets proto://host:port string for the first exposed port
+func (c *ContainerMock) Endpoint(ctx context.Context, proto string) (string, error) {
+	for port := range c.PortMap {
+		return c.PortEndpoint(ctx, port, proto)
+	}
+
+	return "", errors.New("no exposed port")
+}
+
+// PortEndpoint gets proto://host:port string for the given exposed port
+func (c *ContainerMock) PortEndpoint(ctx context.Context, port nat.Port, proto string) (string, error) {
+	outerPort, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	protoFull := ""
+	if proto != "" {
+		protoFull = fmt.Sprintf("%s://", proto)
+	}
+
+	return protoFull + net.JoinHostPort(c.HostName, outerPort.Port()), nil
+}
+
+// Host gets the host where the ports of the mock container are exposed
+func (c *ContainerMock) Host(ctx context.Context) (string, error) {
+	return c.HostName, nil
+}
+
+// MappedPort gets the host port mapped to the given port of the mock container
+func (c *ContainerMock) MappedPort(ctx context.Context, port nat.Port) (nat.Port, error) {
+	for k, bindings := range c.PortMap {
+		if k.Port() != port.Port() || (port.Proto() != "" && k.Proto() != port.Proto()) {
+			continue
+		}
+		if len(bindings) == 0 {
+			break
+		}
+		return nat.NewPort(k.Proto(), bindings[0].HostPort)
+	}
+
+	return "", errors.New("port not found")
+}
+
+// Ports gets the mapped ports of the mock container
+func (c *ContainerMock) Ports(ctx context.Context) (nat.PortMap, error) {
+	return c.PortMap, nil
+}
+
+// SessionID gets the current session id
+func (c *ContainerMock) SessionID() string {
+	if c.Request.SessionID != "" {
+		return c.Request.SessionID
+	}
+	return sessionID.String()
+}
+
+// Start starts the mock container, or fails with StartErr
+func (c *ContainerMock) Start(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Start")
+	if c.StartErr != nil {
+		return c.StartErr
+	}
+	if c.removed {
+		return fmt.Errorf("container '%s' is removed", c.ID)
+	}
+	c.status = "running"
+
+	return nil
+}
+
+// Stop stops the mock container, or fails with StopErr
+func (c *ContainerMock) Stop(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Stop")
+	if c.StopErr != nil {
+		return c.StopErr
+	}
+	if c.removed {
+		return fmt.Errorf("could not stop container '%s': removed", c.ID)
+	}
+	c.status = "exited"
+	c.StateTransitions = nil
+
+	return nil
+}
+
+// StopGracefully stops the mock container, killing it with the exit code 137 if it IgnoresStopSignal
+func (c *ContainerMock) StopGracefully(ctx context.Context, signal string, gracePeriod time.Duration) (StopResult, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("StopGracefully")
+	if c.StopErr != nil {
+		return StopResult{}, c.StopErr
+	}
+	if c.removed {
+		return StopResult{}, fmt.Errorf("could not stop container '%s': removed", c.ID)
+	}
+	c.status = "exited"
+	c.StateTransitions = nil
+	if c.IgnoresStopSignal {
+		c.ExitCode = 137
+		return StopResult{Killed: true, ExitCode: 137}, nil
+	}
+
+	return StopResult{ExitCode: int64(c.ExitCode)}, nil
+}
+
+// Kill sends the signal to the running mock container, SIGKILL if empty. SIGKILL exits the container with 137,
+// the other signals are only recorded, see Signals.
+func (c *ContainerMock) Kill(ctx context.Context, signal string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Kill")
+	if c.removed || c.status != "running" {
+		return fmt.Errorf("could not send %s to container '%s': it is not running", signal, c.ID)
+	}
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	c.signals = append(c.signals, signal)
+	if signal == "SIGKILL" {
+		c.status = "exited"
+		c.StateTransitions = nil
+		c.ExitCode = 137
+	}
+
+	return nil
+}
+
+// Signals gets the signals sent to the mock container by Kill, in order
+func (c *ContainerMock) Signals() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.signals...)
+}
+
+// Restart stops the mock container, then starts it again
+func (c *ContainerMock) Restart(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Restart")
+	if c.StopErr != nil {
+		return c.StopErr
+	}
+	if c.StartErr != nil {
+		return c.StartErr
+	}
+	if c.removed {
+		return fmt.Errorf("could not restart container '%s': removed", c.ID)
+	}
+	c.status = "running"
+
+	return nil
+}
+
+// Pause freezes the running mock container, which is still running but paused until Unpause
+func (c *ContainerMock) Pause(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Pause")
+	if c.removed || c.status != "running" {
+		return fmt.Errorf("could not pause container '%s': it is not running", c.ID)
+	}
+	c.status = "paused"
+
+	return nil
+}
+
+// Unpause resumes the mock container frozen by Pause
+func (c *ContainerMock) Unpause(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Unpause")
+	if c.removed || c.status != "paused" {
+		return fmt.Errorf("could not unpause container '%s': it is not paused", c.ID)
+	}
+	c.status = "running"
+
+	return nil
+}
+
+// Clone creates a running copy of the mock container, with its scripted behaviour and files, named newName
+func (c *ContainerMock) Clone(ctx context.Context, newName string) (Container, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Clone")
+	if c.removed {
+		return nil, fmt.Errorf("could not clone container '%s': removed", c.ID)
+	}
+	clone := &ContainerMock{
+		ID:                uuid.NewV4().String(),
+		Request:           c.Request,
+		HostName:          c.HostName,
+		PortMap:           c.PortMap,
+		Networks:          c.Networks,
+		LogOutput:         c.LogOutput,
+		ExitCode:          c.ExitCode,
+		IgnoresStopSignal: c.IgnoresStopSignal,
+		status:            "running",
+		files:             make(map[string][]byte, len(c.files)),
+	}
+	clone.Request.Name = newName
+	clone.IPAddresses = make(map[string]string, len(c.IPAddresses))
+	for network := range c.IPAddresses {
+		clone.IPAddresses[network] = nextMockIP()
+	}
+	for k, v := range c.files {
+		clone.files[k] = v
+	}
+
+	return clone, nil
+}
+
+// Remove removes the mock container, which must be stopped unless forced
+func (c *ContainerMock) Remove(ctx context.Context, force bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Remove")
+	if c.removed {
+		return fmt.Errorf("could not remove container '%s': already removed", c.ID)
+	}
+	if c.status == "running" && !force {
+		return fmt.Errorf("could not remove container '%s': running", c.ID)
+	}
+	c.removed = true
+
+	return nil
+}
+
+// Terminate removes the mock container, or fails with TerminateErr
+func (c *ContainerMock) Terminate(ctx context.Context) error {
+	runPreTerminateHooks(ctx, c, c.Request.PreTerminateHooks)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.record("Terminate")
+	if c.TerminateErr != nil {
+		return c.TerminateErr
+	}
+	c.removed = true
+
+	return nil
+}
+
+// Logs gets the scripted LogOutput
+func (c *ContainerMock) Logs(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(c.LogOutput)), nil
+}
+
+// mockIPs counts the IP addresses of the mock containers
+var mockIPs uint32
+
+// nextMockIP generates a new IP address for a mock container, e.g. 172.17.0.2
+func nextMockIP() string {
+	n := atomic.AddUint32(&mockIPs, 1)
+
+	return fmt.Sprintf("172.17.%d.%d", n/254%256, n%254+1)
+}
+
+// ContainerIP gets the IP address of the mock container on the first of the Networks of its request, on the
+// bridge network if there are none
+func (c *ContainerMock) ContainerIP(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	network := "bridge"
+	if len(c.Request.Networks) > 0 {
+		network = c.Request.Networks[0]
+	}
+	ip, ok := c.IPAddresses[network]
+	if !ok {
+		return "", fmt.Errorf("container '%s' has no IP address on its default network", c.ID)
+	}
+
+	return ip, nil
+}
+
+// ContainerIPs gets a copy of the IPAddresses of the mock container
+func (c *ContainerMock) ContainerIPs(ctx context.Context) (map[string]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ips := make(map[string]string, len(c.IPAddresses))
+	for network, ip := range c.IPAddresses {
+		ips[network] = ip
+	}
+
+	return ips, nil
+}
+
+// Name gets the name of the mock container, prefixed with a slash as Docker does
+func (c *ContainerMock) Name(ctx context.Context) (string, error) {
+	return "/" + c.Request.Name, nil
+}
+
+// IsRunning returns true if the mock container is running
+func (c *ContainerMock) IsRunning(ctx context.Context) (bool, error) {
+	state, err := c.State(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return state.Running, nil
+}
+
+// State gets the state of the mock container, following the StateTransitions once started
+func (c *ContainerMock) State(ctx context.Context) (*types.ContainerState, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.removed {
+		return nil, fmt.Errorf("no such container: %s", c.ID)
+	}
+	if c.status != "created" && len(c.StateTransitions) > 0 {
+		c.status = c.StateTransitions[0]
+		if len(c.StateTransitions) > 1 {
+			c.StateTransitions = c.StateTransitions[1:]
+		}
+	}
+
+	state := &types.ContainerState{
+		Status:  c.status,
+		Running: c.status == "running" || c.status == "paused", // as with Docker, a paused container is running
+		Paused:  c.status == "paused",
+		Dead:    c.status == "dead",
+	}
+	if c.status == "exited" || c.status == "dead" {
+		state.ExitCode = c.ExitCode
+	}
+	if c.status != "created" && len(c.HealthTransitions) > 0 {
+		state.Health = &types.Health{Status: c.HealthTransitions[0]}
+		if len(c.HealthTransitions) > 1 {
+			c.HealthTransitions = c.HealthTransitions[1:]
+		}
+	}
+
+	return state, nil
+}
+
+// Image gets the image of the request of the mock container
+func (c *ContainerMock) Image(ctx context.Context) (string, error) {
+	return c.Request.Image, nil
+}
+
+// ResetCache does nothing, the mock container has no cache
+func (c *ContainerMock) ResetCache(ctx context.Context) {}
+
+// ConnectToNetwork attaches the mock container to the network
+func (c *ContainerMock) ConnectToNetwork(ctx context.Context, networkName string, aliases ...string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.Networks[networkName]; ok {
+		return fmt.Errorf("container '%s' is already connected to network '%s'", c.ID, networkName)
+	}
+	c.Networks[networkName] = aliases
+	if c.IPAddresses == nil {
+		c.IPAddresses = map[string]string{}
+	}
+	c.IPAddresses[networkName] = nextMockIP()
+
+	return nil
+}
+
+// DisconnectFromNetwork detaches the mock container from the network
+func (c *ContainerMock) DisconnectFromNetwork(ctx context.Context, networkName string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.Networks[networkName]; !ok {
+		return fmt.Errorf("container '%s' is not connected to network '%s'", c.ID, networkName)
+	}
+	delete(c.Networks, networkName)
+	delete(c.IPAddresses, networkName)
+
+	return nil
+}
+
+// CopyToContainer records the content of the file at the given path of the mock container, see Files
+func (c *ContainerMock) CopyToContainer(ctx context.Context, content []byte, containerFilePath string, fileMode int64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.removed {
+		return fmt.Errorf("container '%s' is removed", c.ID)
+	}
+	if c.files == nil {
+		c.files = map[string][]byte{}
+	}
+	c.files[containerFilePath] = append([]byte{}, content...)
+
+	return nil
+}
+
+// CopyDirToContainer copies the files of the directory of the host to the path of the mock container, recursively
+func (c *ContainerMock) CopyDirToContainer(ctx context.Context, hostDirPath, containerDirPath string) error {
+	files := map[string][]byte{}
+	err := filepath.Walk(hostDirPath, func(file string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(hostDirPath, file)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		files[path.Join(containerDirPath, filepath.ToSlash(rel))] = content
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not copy directory '%s' to container '%s': %s", hostDirPath, c.ID, err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.removed {
+		return fmt.Errorf("container '%s' is removed", c.ID)
+	}
+	if c.files == nil {
+		c.files = map[string][]byte{}
+	}
+	for file, content := range files {
+		c.files[file] = content
+	}
+
+	return nil
+}
+
+// Exec runs the command in the running mock container, scripted by OnExec
+func (c *ContainerMock) Exec(ctx context.Context, cmd []string) (int, io.Reader, error) {
+	c.mutex.Lock()
+	c.record("Exec")
+	running := c.status == "running" && !c.removed
+	c.mutex.Unlock()
+	if !running {
+		return 0, nil, fmt.Errorf("could not exec in container '%s': it is not running", c.ID)
+	}
+
+	if c.OnExec == nil {
+		return 0, strings.NewReader(""), nil
+	}
+	exitCode, output, err := c.OnExec(cmd)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return exitCode, strings.NewReader(output), nil
+}
+
+// HTTPEndpoint gets the base URL of the HTTP server listening on the port of the mock container,
+// e.g. of an httptest server its ports are mapped to
+func (c *ContainerMock) HTTPEndpoint(ctx context.Context, port nat.Port) (*url.URL, error) {
+	return httpEndpoint(ctx, c, port, "http")
+}
+
+// HTTPClientFor creates a client of the HTTP server listening on the port of the mock container
+func (c *ContainerMock) HTTPClientFor(ctx context.Context, port nat.Port, tlsConfig *tls.Config) (*http.Client, error) {
+	return httpClientFor(ctx, c, port, tlsConfig)
+}
+
+// FileExists checks whether a file was copied to the path of the mock container, or to a directory at the path
+func (c *ContainerMock) FileExists(ctx context.Context, filePath string) (bool, error) {
+	for file := range c.Files() {
+		if file == filePath || strings.HasPrefix(file, strings.TrimSuffix(filePath, "/")+"/") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ReadFile reads the content of the file copied to the path of the mock container
+func (c *ContainerMock) ReadFile(ctx context.Context, filePath string) ([]byte, error) {
+	content, ok := c.Files()[filePath]
+	if !ok {
+		return nil, fmt.Errorf("could not read file '%s' in container '%s': not found", filePath, c.ID)
+	}
+
+	return content, nil
+}
+
+// Glob gets the paths of the files copied to the mock container matching the pattern, sorted
+func (c *ContainerMock) Glob(ctx context.Context, pattern string) ([]string, error) {
+	var matches []string
+	for file := range c.Files() {
+		ok, err := path.Match(pattern, file)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %s", pattern, err)
+		}
+		if ok {
+			matches = append(matches, file)
+		}
+	}
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+// Files gets the content of the files copied to the mock container, by path
+func (c *ContainerMock) Files() map[string][]byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	files := make(map[string][]byte, len(c.files))
+	for k, v := range c.files {
+		files[k] = v
+	}
+
+	return files
+}
+
+var (
+	_ GenericProvider = (*ProviderMock)(nil)
+	_ Container       = (*ContainerMock)(nil)
+)
@@ -0,0 +1,290 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProviderMock(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		c.LogOutput = "ready\n"
+		c.StateTransitions = []string{"running", "exited"}
+		c.ExitCode = 1
+		return nil
+	}
+	if err := RegisterProvider("mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("mock")
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:        "nginx",
+			ExposedPorts: []string{"80/tcp"},
+		},
+		Started:      true,
+		ProviderName: "mock",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint, err := c.PortEndpoint(ctx, "80/tcp", "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint != "http://localhost:32768" {
+		t.Fatalf("unexpected endpoint '%s'", endpoint)
+	}
+
+	logs, err := c.Logs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ := ioutil.ReadAll(logs)
+	if string(output) != "ready\n" {
+		t.Fatalf("unexpected logs '%s'", output)
+	}
+
+	for _, expected := range []bool{true, false, false} {
+		running, err := c.IsRunning(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if running != expected {
+			t.Fatalf("expected running to be %t", expected)
+		}
+	}
+
+	if err := c.Terminate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.State(ctx); err == nil {
+		t.Fatal("expected an error for the state of a terminated container")
+	}
+
+	mock := provider.Containers()[0]
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0] != "Start" || calls[1] != "Terminate" {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+}
+
+func TestProviderMockFailures(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+	startErr := errors.New("no space left on device")
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		if req.Image == "forbidden" {
+			return errors.New("pull access denied")
+		}
+		c.StartErr = startErr
+		return nil
+	}
+
+	if _, err := provider.CreateContainer(ctx, ContainerRequest{Image: "forbidden"}); err == nil {
+		t.Fatal("expected the creation to fail")
+	}
+
+	c, err := provider.RunContainer(ctx, ContainerRequest{Image: "nginx", Name: "web"})
+	if err == nil {
+		t.Fatal("expected the start to fail")
+	}
+	if running, _ := c.IsRunning(ctx); running {
+		t.Fatal("expected the container not to run")
+	}
+
+	if _, err := provider.CreateContainer(ctx, ContainerRequest{Image: "nginx", Name: "web"}); err == nil {
+		t.Fatal("expected a conflict on the container name")
+	}
+}
+
+func TestContainerMockFiles(t *testing.T) {
+	ctx := context.Background()
+	c := &ContainerMock{ID: "files"}
+	for _, file := range []string{"/var/log/app.log", "/var/log/app.err", "/etc/app.conf"} {
+		if err := c.CopyToContainer(ctx, []byte(file), file, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if exists, _ := c.FileExists(ctx, "/var/log"); !exists {
+		t.Error("expected the directory of a copied file to exist")
+	}
+	if exists, _ := c.FileExists(ctx, "/var/lo"); exists {
+		t.Error("expected a prefix of a directory not to exist")
+	}
+	if content, err := c.ReadFile(ctx, "/etc/app.conf"); err != nil || string(content) != "/etc/app.conf" {
+		t.Errorf("unexpected content %q, %v", content, err)
+	}
+	if matches, _ := c.Glob(ctx, "/var/log/app.*"); len(matches) != 2 || matches[0] != "/var/log/app.err" {
+		t.Errorf("unexpected matches %v", matches)
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "seed"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "seed", "data.sql"), []byte("INSERT"), 0644)
+	if err := c.CopyDirToContainer(ctx, dir, "/docker-entrypoint-initdb.d"); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := c.ReadFile(ctx, "/docker-entrypoint-initdb.d/seed/data.sql"); err != nil || string(content) != "INSERT" {
+		t.Errorf("unexpected content of the copied directory %q, %v", content, err)
+	}
+}
+
+func TestProviderMockCopiesFiles(t *testing.T) {
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "nginx.conf"), []byte("worker_processes 1;"), 0644)
+	provider := NewProviderMock()
+
+	c, err := provider.CreateContainer(context.Background(), ContainerRequest{
+		Image: "nginx",
+		Files: []ContainerFile{{HostFilePath: filepath.Join(dir, "nginx.conf"), ContainerFilePath: "/etc/nginx/nginx.conf"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err := c.ReadFile(context.Background(), "/etc/nginx/nginx.conf"); err != nil || string(content) != "worker_processes 1;" {
+		t.Errorf("expected the file to be copied on creation, got %q, %v", content, err)
+	}
+
+	_, err = provider.CreateContainer(context.Background(), ContainerRequest{
+		Image: "nginx",
+		Files: []ContainerFile{{HostFilePath: filepath.Join(dir, "missing.conf"), ContainerFilePath: "/etc/nginx/nginx.conf"}},
+	})
+	if err == nil {
+		t.Error("expected a missing file to fail the creation")
+	}
+}
+
+func TestContainerMockKill(t *testing.T) {
+	ctx := context.Background()
+	c := &ContainerMock{ID: "app", status: "created"}
+	c.Start(ctx)
+
+	if err := c.Kill(ctx, "SIGHUP"); err != nil {
+		t.Fatal(err)
+	}
+	if running, _ := c.IsRunning(ctx); !running {
+		t.Error("expected the container to keep running on SIGHUP")
+	}
+	if err := c.Kill(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := c.State(ctx); state.Running || state.ExitCode != 137 {
+		t.Errorf("expected the container to be killed, got %+v", state)
+	}
+	if signals := c.Signals(); strings.Join(signals, ",") != "SIGHUP,SIGKILL" {
+		t.Errorf("unexpected signals %v", signals)
+	}
+	if err := c.Kill(ctx, "SIGHUP"); err == nil {
+		t.Error("expected no signal to be sent to a container which is not running")
+	}
+}
+
+func TestContainerMockRestart(t *testing.T) {
+	ctx := context.Background()
+	c := &ContainerMock{ID: "db", status: "created"}
+	c.Start(ctx)
+	c.Stop(ctx)
+
+	if err := c.Restart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if running, _ := c.IsRunning(ctx); !running {
+		t.Error("expected the container to be running once restarted")
+	}
+	if calls := c.Calls(); len(calls) != 3 || calls[2] != "Restart" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+}
+
+func TestContainerMockIP(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+
+	bridged, _ := provider.CreateContainer(ctx, ContainerRequest{Image: "nginx"})
+	attached, _ := provider.CreateContainer(ctx, ContainerRequest{
+		Image:      "nginx",
+		Networks:   []string{"backend", "monitoring"},
+		NetworkIPs: map[string]string{"backend": "10.0.0.5"},
+	})
+	host, _ := provider.CreateContainer(ctx, ContainerRequest{Image: "nginx", NetworkMode: "host"})
+
+	if ip, err := bridged.ContainerIP(ctx); err != nil || !strings.HasPrefix(ip, "172.17.") {
+		t.Errorf("expected an IP address on the bridge network, got %s, %v", ip, err)
+	}
+	if ip, err := attached.ContainerIP(ctx); err != nil || ip != "10.0.0.5" {
+		t.Errorf("expected the static IP address on the first network, got %s, %v", ip, err)
+	}
+	if _, err := host.ContainerIP(ctx); err == nil {
+		t.Error("expected a container on the network of the host to have no IP address")
+	}
+
+	attached.ConnectToNetwork(ctx, "frontend")
+	attached.DisconnectFromNetwork(ctx, "monitoring")
+	ips, err := attached.ContainerIPs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ips) != 2 || ips["backend"] != "10.0.0.5" || ips["frontend"] == "" {
+		t.Errorf("unexpected IP addresses %v", ips)
+	}
+}
+
+func TestContainerMockPause(t *testing.T) {
+	ctx := context.Background()
+	c := &ContainerMock{ID: "db", status: "created"}
+
+	if err := c.Pause(ctx); err == nil {
+		t.Fatal("expected a container which is not running not to be paused")
+	}
+	c.Start(ctx)
+	if err := c.Pause(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := c.State(ctx); !state.Paused || !state.Running {
+		t.Errorf("expected the container to be paused and running, got %+v", state)
+	}
+	if err := c.Unpause(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := c.State(ctx); state.Paused || state.Status != "running" {
+		t.Errorf("expected the container to be running again, got %+v", state)
+	}
+}
+
+func TestContainerMockExec(t *testing.T) {
+	ctx := context.Background()
+	c := &ContainerMock{ID: "redis", status: "created"}
+	c.OnExec = func(cmd []string) (int, string, error) {
+		if cmd[0] != "redis-cli" {
+			return 127, "command not found", nil
+		}
+		return 0, "OK", nil
+	}
+
+	if _, _, err := c.Exec(ctx, []string{"redis-cli", "flushall"}); err == nil {
+		t.Fatal("expected no exec in a container which is not running")
+	}
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for cmd, expected := range map[string]int{"redis-cli": 0, "psql": 127} {
+		exitCode, output, err := c.Exec(ctx, []string{cmd})
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := ioutil.ReadAll(output)
+		if exitCode != expected || len(content) == 0 {
+			t.Errorf("unexpected exit code %d and output %q of %s", exitCode, content, cmd)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package gcloud runs emulators of Google Cloud services for tests
+package gcloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultGCSImage is the image run when RunGCS is given no image
+	DefaultGCSImage = "fsouza/fake-gcs-server:1.47.5"
+
+	// GCSPort is the port of the JSON API of the fake GCS server
+	GCSPort = "4443/tcp"
+
+	// the directory the fake GCS server loads the buckets and objects from when it starts
+	gcsDataDir = "/data"
+)
+
+// GCSContainer is a running fake-gcs-server container, emulating Google Cloud Storage
+type GCSContainer struct {
+	testcontainers.Container
+}
+
+// RunGCS creates and starts a fake-gcs-server container of the image, DefaultGCSImage if empty, customized by the
+// options. The server stores the objects in memory and serves them over plain HTTP.
+func RunGCS(ctx context.Context, image string, opts ...testcontainers.CustomizeRequestOption) (*GCSContainer, error) {
+	if image == "" {
+		image = DefaultGCSImage
+	}
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{GCSPort},
+			Command:      []string{"-scheme", "http", "-backend", "memory", "-data", gcsDataDir},
+			WaitingFor:   wait.ForHTTP("/storage/v1/b").WithPort(GCSPort),
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	gc := &GCSContainer{Container: c}
+	if !req.Started {
+		return gc, nil
+	}
+
+	// the URLs of the resumable uploads point to the server, which must know the port mapped on the host
+	endpoint, err := gc.EmulatorHost(ctx)
+	if err != nil {
+		return gc, err
+	}
+	config := map[string]string{"externalUrl": "http://" + endpoint}
+	if err := gc.call(ctx, http.MethodPut, "/_internal/config", nil, jsonBody(config)); err != nil {
+		return gc, errors.Wrap(err, "could not configure the external URL")
+	}
+
+	return gc, nil
+}
+
+// WithSeedDir loads the buckets and objects of the directory of the host when the server starts: each
+// subdirectory is a bucket, and the files it contains are its objects. Like all bind mounts, it requires
+// a local daemon, see CreateBucket and UploadObject otherwise.
+func WithSeedDir(dir string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		return testcontainers.WithMounts(testcontainers.BindMount(abs, gcsDataDir))(req)
+	}
+}
+
+// EmulatorHost gets the address of the server for the clients of the host, e.g. "localhost:32768", which the
+// Go client of Cloud Storage uses when it is the value of the STORAGE_EMULATOR_HOST env variable
+func (c *GCSContainer) EmulatorHost(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+	port, err := c.MappedPort(ctx, GCSPort)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, port.Port()), nil
+}
+
+// Endpoint gets the URL of the JSON API for the option.WithEndpoint of the Go client of Cloud Storage,
+// e.g. "http://localhost:32768/storage/v1/", along with option.WithoutAuthentication
+func (c *GCSContainer) Endpoint(ctx context.Context) (string, error) {
+	host, err := c.EmulatorHost(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + host + "/storage/v1/", nil
+}
+
+// CreateBucket creates an empty bucket of the given name
+func (c *GCSContainer) CreateBucket(ctx context.Context, name string) error {
+	bucket := map[string]string{"name": name}
+	if err := c.call(ctx, http.MethodPost, "/storage/v1/b", nil, jsonBody(bucket)); err != nil {
+		return fmt.Errorf("could not create bucket '%s': %s", name, err)
+	}
+
+	return nil
+}
+
+// UploadObject uploads an object of the given name and content to the bucket
+func (c *GCSContainer) UploadObject(ctx context.Context, bucket, name string, content []byte) error {
+	query := url.Values{"uploadType": {"media"}, "name": {name}}
+	path := "/upload/storage/v1/b/" + url.PathEscape(bucket) + "/o"
+	if err := c.call(ctx, http.MethodPost, path, query, bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("could not upload object '%s' to bucket '%s': %s", name, bucket, err)
+	}
+
+	return nil
+}
+
+func jsonBody(v interface{}) io.Reader {
+	b, _ := json.Marshal(v)
+	return bytes.NewReader(b)
+}
+
+// call calls the API of the server
+func (c *GCSContainer) call(ctx context.Context, method, path string, query url.Values, body io.Reader) error {
+	host, err := c.EmulatorHost(ctx)
+	if err != nil {
+		return err
+	}
+	u := url.URL{Scheme: "http", Host: host, Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
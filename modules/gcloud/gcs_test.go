@@ -0,0 +1,78 @@
+package gcloud
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestSeedBuckets(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	c := &GCSContainer{Container: &testcontainers.ContainerMock{
+		HostName: host,
+		PortMap:  nat.PortMap{GCSPort: {{HostPort: port}}},
+	}}
+	ctx := context.Background()
+
+	if err := c.CreateBucket(ctx, "invoices"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UploadObject(ctx, "invoices", "2023/10.pdf", []byte("%PDF")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`POST /storage/v1/b {"name":"invoices"}`,
+		`POST /upload/storage/v1/b/invoices/o?name=2023%2F10.pdf&uploadType=media %PDF`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected the requests %q, got %q", expected, requests)
+	}
+
+	endpoint, err := c.Endpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint != "http://"+host+":"+port+"/storage/v1/" {
+		t.Fatalf("unexpected endpoint %s", endpoint)
+	}
+}
+
+func TestRunGCSWithSeedDir(t *testing.T) {
+	ctx := context.Background()
+	c, err := RunGCS(ctx, "", WithSeedDir("testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+
+	if err := c.UploadObject(ctx, "invoices", "2023/11.pdf", []byte("%PDF")); err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := c.Endpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(endpoint + "b/invoices/o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	list, _ := ioutil.ReadAll(resp.Body)
+	if !strings.Contains(string(list), "2023/10.pdf") || !strings.Contains(string(list), "2023/11.pdf") {
+		t.Fatalf("expected the seeded and the uploaded objects, got %s", list)
+	}
+}
@@ -0,0 +1,144 @@
+// Package kafka runs single node Kafka brokers in KRaft mode, without ZooKeeper, for tests
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultImage is the image run when Run is given no image
+	DefaultImage = "confluentinc/confluent-local:7.5.0"
+
+	// Port is the port of the listener advertised to the clients running on the host
+	Port = "9093/tcp"
+
+	// BrokerPort is the port of the listener advertised to the containers on the same networks, under the
+	// hostname of the container
+	BrokerPort = "9092/tcp"
+
+	// ClusterID is the ID of the KRaft cluster of the broker
+	ClusterID = "kraftCluster"
+
+	// the container waits for the script to be copied once it is started, to know the port mapped on the host
+	startScript = "/usr/sbin/testcontainers_start.sh"
+)
+
+// KafkaContainer is a running single node Kafka broker
+type KafkaContainer struct {
+	testcontainers.Container
+}
+
+// Run creates and starts a Kafka broker of the image, DefaultImage if empty, customized by the options.
+// The broker is both the controller and the broker of its KRaft cluster, and advertises the port mapped
+// on the host, so that clients of the host reach it with Brokers. The wait strategy of the options, the
+// broker being started if none, is waited for once the broker is configured.
+func Run(ctx context.Context, image string, opts ...testcontainers.CustomizeRequestOption) (*KafkaContainer, error) {
+	if image == "" {
+		image = DefaultImage
+	}
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{Port},
+			Env: map[string]string{
+				"KAFKA_LISTENERS":                                "PLAINTEXT://0.0.0.0:9093,BROKER://0.0.0.0:9092,CONTROLLER://0.0.0.0:9094",
+				"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP":           "BROKER:PLAINTEXT,PLAINTEXT:PLAINTEXT,CONTROLLER:PLAINTEXT",
+				"KAFKA_INTER_BROKER_LISTENER_NAME":               "BROKER",
+				"KAFKA_CONTROLLER_LISTENER_NAMES":                "CONTROLLER",
+				"KAFKA_PROCESS_ROLES":                            "broker,controller",
+				"KAFKA_NODE_ID":                                  "1",
+				"KAFKA_BROKER_ID":                                "1",
+				"KAFKA_CONTROLLER_QUORUM_VOTERS":                 "1@localhost:9094",
+				"CLUSTER_ID":                                     ClusterID,
+				"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR":         "1",
+				"KAFKA_OFFSETS_TOPIC_NUM_PARTITIONS":             "1",
+				"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR":            "1",
+				"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR": "1",
+				"KAFKA_GROUP_INITIAL_REBALANCE_DELAY_MS":         "0",
+				"KAFKA_LOG_FLUSH_INTERVAL_MESSAGES":              "9223372036854775807",
+			},
+			Entrypoint: []string{"sh"},
+			Command:    []string{"-c", "while [ ! -f " + startScript + " ]; do sleep 0.1; done; bash " + startScript},
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	// the broker only starts once it is configured, after the start of the container
+	ready := req.WaitingFor
+	if ready == nil {
+		ready = wait.ForLog("Kafka Server started")
+	}
+	req.WaitingFor = nil
+
+	c, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	kc := &KafkaContainer{Container: c}
+	if !req.Started {
+		return kc, nil
+	}
+
+	if err := kc.configure(ctx); err != nil {
+		return kc, err
+	}
+	if err := ready.WaitUntilReady(ctx, c); err != nil {
+		return kc, errors.Wrap(err, "failed to wait for the broker")
+	}
+
+	return kc, nil
+}
+
+// configure starts the broker, advertising the port mapped on the host now that the container is started
+func (c *KafkaContainer) configure(ctx context.Context) error {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return err
+	}
+	port, err := c.MappedPort(ctx, Port)
+	if err != nil {
+		return err
+	}
+
+	return c.CopyToContainer(ctx, []byte(startScriptContent(net.JoinHostPort(host, port.Port()))), startScript, 0755)
+}
+
+// startScriptContent is the script configuring and launching the broker of the Confluent image in KRaft mode,
+// advertising the given address to the clients of the host
+func startScriptContent(address string) string {
+	return fmt.Sprintf(`#!/bin/bash
+source /etc/confluent/docker/bash-config
+export KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://%s,BROKER://$(hostname):9092
+echo Starting Kafka KRaft mode
+sed -i '/KAFKA_ZOOKEEPER_CONNECT/d' /etc/confluent/docker/configure
+echo 'kafka-storage format --ignore-formatted -t "$(kafka-storage random-uuid)" -c /etc/kafka/kafka.properties' >> /etc/confluent/docker/configure
+echo '' > /etc/confluent/docker/ensure
+/etc/confluent/docker/configure
+/etc/confluent/docker/launch
+`, address)
+}
+
+// Brokers gets the addresses of the broker for the clients of the host, e.g. "localhost:32768"
+func (c *KafkaContainer) Brokers(ctx context.Context) ([]string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return nil, err
+	}
+	port, err := c.MappedPort(ctx, Port)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{net.JoinHostPort(host, port.Port())}, nil
+}
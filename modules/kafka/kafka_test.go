@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestRunAdvertisesTheMappedPort(t *testing.T) {
+	provider := testcontainers.NewProviderMock()
+	if err := testcontainers.RegisterProvider("kafka-mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer testcontainers.UnregisterProvider("kafka-mock")
+	ctx := context.Background()
+
+	// the mock has no logs, the broker is not waited for
+	c, err := Run(ctx, "", testcontainers.WithProvider("kafka-mock"), testcontainers.WithWaitStrategy(noWait{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brokers, err := c.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(brokers) != 1 || brokers[0] != "localhost:32768" {
+		t.Fatalf("expected the broker on the mapped port, got %v", brokers)
+	}
+	script := string(provider.Containers()[0].Files()[startScript])
+	if !strings.Contains(script, "KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://localhost:32768,") {
+		t.Fatalf("expected the mapped port to be advertised, got %s", script)
+	}
+}
+
+func TestBrokerIsReachable(t *testing.T) {
+	ctx := context.Background()
+	c, err := Run(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+
+	brokers, err := c.Brokers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", brokers[0])
+	if err != nil {
+		t.Fatalf("expected the broker to be reachable on %s: %s", brokers[0], err)
+	}
+	conn.Close()
+}
+
+type noWait struct{}
+
+func (noWait) WaitUntilReady(context.Context, wait.StrategyTarget) error {
+	return nil
+}
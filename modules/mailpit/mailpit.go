@@ -0,0 +1,175 @@
+// Package mailpit runs Mailpit containers, capturing the emails sent by the code under test over SMTP
+// so that tests assert on them
+package mailpit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultImage is the image run when Run is given no image
+	DefaultImage = "axllent/mailpit:v1.13.0"
+
+	// SMTPPort is the port of the SMTP server capturing the emails
+	SMTPPort = "1025/tcp"
+
+	// HTTPPort is the port of the web UI and of the HTTP API
+	HTTPPort = "8025/tcp"
+)
+
+// MailpitContainer is a running Mailpit container
+type MailpitContainer struct {
+	testcontainers.Container
+}
+
+// Run creates and starts a Mailpit container of the image, DefaultImage if empty, customized by the options.
+// The SMTP server accepts any credentials.
+func Run(ctx context.Context, image string, opts ...testcontainers.CustomizeRequestOption) (*MailpitContainer, error) {
+	if image == "" {
+		image = DefaultImage
+	}
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{SMTPPort, HTTPPort},
+			Env:          map[string]string{"MP_SMTP_AUTH_ACCEPT_ANY": "1", "MP_SMTP_AUTH_ALLOW_INSECURE": "1"},
+			WaitingFor:   wait.ForHTTP("/api/v1/messages").WithPort(HTTPPort),
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MailpitContainer{Container: c}, nil
+}
+
+// Address is the name and the email address of a sender or a recipient
+type Address struct {
+	Name    string
+	Address string
+}
+
+// Message is an email captured by Mailpit. The Text and HTML bodies are only read by Message.
+type Message struct {
+	ID      string
+	From    Address
+	To      []Address
+	Cc      []Address
+	Bcc     []Address
+	Subject string
+	Created time.Time
+	Snippet string // the beginning of the text of the message
+	Text    string
+	HTML    string
+}
+
+// SMTPAddress gets the address of the SMTP server for the clients of the host, e.g. "localhost:32768"
+func (c *MailpitContainer) SMTPAddress(ctx context.Context) (string, error) {
+	return c.address(ctx, SMTPPort)
+}
+
+// Messages gets the messages captured, the latest first
+func (c *MailpitContainer) Messages(ctx context.Context) ([]Message, error) {
+	var list struct {
+		Messages []Message
+	}
+	if err := c.call(ctx, http.MethodGet, "/api/v1/messages", &list); err != nil {
+		return nil, err
+	}
+
+	return list.Messages, nil
+}
+
+// Message gets the message of the given ID, with its bodies
+func (c *MailpitContainer) Message(ctx context.Context, id string) (*Message, error) {
+	m := &Message{}
+	if err := c.call(ctx, http.MethodGet, "/api/v1/message/"+id, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// WaitForMessages waits until at least count messages are captured, e.g. for code sending emails
+// asynchronously, and gets them, the latest first
+func (c *MailpitContainer) WaitForMessages(ctx context.Context, count int) ([]Message, error) {
+	for {
+		messages, err := c.Messages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) >= count {
+			return messages, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return messages, fmt.Errorf("got %d messages rather than %d: %s", len(messages), count, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// DeleteMessages deletes all the messages captured, e.g. between tests
+func (c *MailpitContainer) DeleteMessages(ctx context.Context) error {
+	return c.call(ctx, http.MethodDelete, "/api/v1/messages", nil)
+}
+
+func (c *MailpitContainer) address(ctx context.Context, port nat.Port) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+	mapped, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mapped.Port()), nil
+}
+
+// call calls the HTTP API of the container and decodes its JSON response
+func (c *MailpitContainer) call(ctx context.Context, method, path string, response interface{}) error {
+	address, err := c.address(ctx, HTTPPort)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, "http://"+address+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s %s", method, path, resp.Status, msg)
+	}
+	if response == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,91 @@
+package mailpit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestMessagesAreDecoded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/messages":
+			fmt.Fprint(w, `{"total":1,"messages":[{"ID":"abc","From":{"Name":"Shop","Address":"shop@example.com"},`+
+				`"To":[{"Name":"","Address":"gopher@example.com"}],"Subject":"Your order","Created":"2023-10-01T12:00:00Z","Snippet":"Thanks"}]}`)
+		case "/api/v1/message/abc":
+			fmt.Fprint(w, `{"ID":"abc","Subject":"Your order","Text":"Thanks for your order"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	c := &MailpitContainer{Container: &testcontainers.ContainerMock{
+		HostName: host,
+		PortMap:  nat.PortMap{HTTPPort: {{HostPort: port}}},
+	}}
+	ctx := context.Background()
+
+	messages, err := c.WaitForMessages(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].From.Address != "shop@example.com" || messages[0].To[0].Address != "gopher@example.com" {
+		t.Fatalf("unexpected messages %+v", messages)
+	}
+	m, err := c.Message(ctx, messages[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Text != "Thanks for your order" {
+		t.Fatalf("expected the text of the message, got %+v", m)
+	}
+
+	if _, err := c.Message(ctx, "unknown"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected an unknown message not to be found, got %v", err)
+	}
+}
+
+func TestCaptureEmail(t *testing.T) {
+	ctx := context.Background()
+	c, err := Run(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+
+	address, err := c.SMTPAddress(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := "Subject: Your order\r\n\r\nThanks for your order\r\n"
+	if err := smtp.SendMail(address, nil, "shop@example.com", []string{"gopher@example.com"}, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	messages, err := c.WaitForMessages(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages[0].Subject != "Your order" {
+		t.Fatalf("unexpected message %+v", messages[0])
+	}
+
+	if err := c.DeleteMessages(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if messages, err := c.Messages(ctx); err != nil || len(messages) != 0 {
+		t.Fatalf("expected the messages to be deleted, got %v %v", messages, err)
+	}
+}
@@ -0,0 +1,171 @@
+// Package mysql runs MySQL containers for tests
+package mysql
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultImage is the image run when Run is given no image
+	DefaultImage = "mysql:8.0"
+
+	// Port is the port MySQL listens on in the container
+	Port = "3306/tcp"
+
+	rootUser        = "root"
+	defaultDatabase = "test"
+	defaultUser     = "test"
+	defaultPassword = "test"
+)
+
+// MySQLContainer is a running MySQL container
+type MySQLContainer struct {
+	testcontainers.Container
+
+	database string
+	user     string
+	password string
+}
+
+var _ testcontainers.ConnectableContainer = (*MySQLContainer)(nil)
+
+// Run creates and starts a MySQL container of the image, DefaultImage if empty, customized by the options.
+// The database, user and password are "test" unless set with WithDatabase, WithUsername and WithPassword.
+// The container is ready once the server is started for good, after it ran the init scripts.
+func Run(ctx context.Context, image string, opts ...testcontainers.CustomizeRequestOption) (*MySQLContainer, error) {
+	if image == "" {
+		image = DefaultImage
+	}
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image: image,
+			Env: map[string]string{
+				"MYSQL_DATABASE":      defaultDatabase,
+				"MYSQL_USER":          defaultUser,
+				"MYSQL_PASSWORD":      defaultPassword,
+				"MYSQL_ROOT_PASSWORD": defaultPassword,
+			},
+			ExposedPorts: []string{Port},
+			// the server is started once to run the init scripts, without networking, then for good
+			WaitingFor: wait.ForLog("mysqld: ready for connections").WithOccurrence(2),
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	user := req.Env["MYSQL_USER"]
+	password := req.Env["MYSQL_PASSWORD"]
+	if strings.EqualFold(user, rootUser) {
+		// the image creates the user, which cannot be root
+		delete(req.Env, "MYSQL_USER")
+		delete(req.Env, "MYSQL_PASSWORD")
+		user = rootUser
+		password = req.Env["MYSQL_ROOT_PASSWORD"]
+		if password == "" {
+			delete(req.Env, "MYSQL_ROOT_PASSWORD")
+			req.Env["MYSQL_ALLOW_EMPTY_PASSWORD"] = "yes"
+		}
+	} else if password == "" {
+		return nil, errors.New("an empty password can only be used with the root user")
+	}
+
+	c, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MySQLContainer{
+		Container: c,
+		database:  req.Env["MYSQL_DATABASE"],
+		user:      user,
+		password:  password,
+	}, nil
+}
+
+// WithDatabase creates the database of the given name, "test" by default
+func WithDatabase(name string) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithEnv(map[string]string{"MYSQL_DATABASE": name})
+}
+
+// WithUsername creates the user of the given name, "test" by default, with all the privileges on the
+// database. The root user is not created but given the password.
+func WithUsername(user string) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithEnv(map[string]string{"MYSQL_USER": user})
+}
+
+// WithPassword sets the password of the user, and of the root user
+func WithPassword(password string) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithEnv(map[string]string{"MYSQL_PASSWORD": password, "MYSQL_ROOT_PASSWORD": password})
+}
+
+// WithConfigFile configures the server with the my.cnf file at the given path of the host
+func WithConfigFile(path string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		return testcontainers.WithMounts(testcontainers.BindMount(abs, "/etc/mysql/conf.d/my.cnf"))(req)
+	}
+}
+
+// WithScripts runs the .sql, .sql.gz and .sh scripts at the given paths of the host once the database is
+// created, in the alphabetical order of their names
+func WithScripts(paths ...string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		for _, path := range paths {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			mount := testcontainers.BindMount(abs, "/docker-entrypoint-initdb.d/"+filepath.Base(abs))
+			if err := testcontainers.WithMounts(mount)(req); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ConnectionString gets the DSN of the database for the github.com/go-sql-driver/mysql driver,
+// e.g. "test:test@tcp(localhost:32768)/test", customized by the options
+func (c *MySQLContainer) ConnectionString(ctx context.Context, opts ...testcontainers.ConnectionOption) (string, error) {
+	options := testcontainers.NewConnectionOptions(testcontainers.ConnectionOptions{
+		Database: c.database,
+		User:     c.user,
+		Password: c.password,
+	}, opts...)
+
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+	port, err := c.MappedPort(ctx, Port)
+	if err != nil {
+		return "", err
+	}
+
+	config := mysql.NewConfig()
+	config.User = options.User
+	config.Passwd = options.Password
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(host, port.Port())
+	config.DBName = options.Database
+	if len(options.Params) > 0 {
+		config.Params = options.Params
+	}
+
+	return config.FormatDSN(), nil
+}
@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestRunConfiguresTheContainer(t *testing.T) {
+	provider := testcontainers.NewProviderMock()
+	if err := testcontainers.RegisterProvider("mysql-mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer testcontainers.UnregisterProvider("mysql-mock")
+	ctx := context.Background()
+
+	c, err := Run(ctx, "", testcontainers.WithProvider("mysql-mock"),
+		WithDatabase("app"), WithUsername("root"), WithPassword("secret"), WithScripts("testdata/schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := provider.Containers()[0].Request
+	if _, ok := req.Env["MYSQL_USER"]; ok || req.Env["MYSQL_ROOT_PASSWORD"] != "secret" {
+		t.Fatalf("expected only the password of root to be set, got %v", req.Env)
+	}
+	for host, target := range req.BindMounts {
+		if target != "/docker-entrypoint-initdb.d/schema.sql" || host[0] != '/' {
+			t.Fatalf("expected the script to be mounted by its absolute path, got %s:%s", host, target)
+		}
+	}
+
+	dsn, err := c.ConnectionString(ctx, testcontainers.WithConnectionParam("parseTime", "true"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsn != "root:secret@tcp(localhost:32768)/app?parseTime=true" {
+		t.Fatalf("unexpected connection string %s", dsn)
+	}
+
+	if _, err := Run(ctx, "", testcontainers.WithProvider("mysql-mock"), WithPassword("")); err == nil {
+		t.Fatal("expected an empty password to be refused for a user other than root")
+	}
+}
+
+func TestConnectToMySQL(t *testing.T) {
+	ctx := context.Background()
+	c, err := Run(ctx, "", WithScripts("testdata/schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+
+	dsn, err := c.ConnectionString(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the user inserted by the init script, got %d", count)
+	}
+}
@@ -0,0 +1,2 @@
+CREATE TABLE users (name VARCHAR(64) NOT NULL PRIMARY KEY);
+INSERT INTO users VALUES ('gopher');
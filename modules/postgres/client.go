@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// runClient runs the PostgreSQL client command in the target, where it is shipped with the server, with the
+// given environment, and reports its output if it fails
+func runClient(ctx context.Context, target testcontainers.Container, env map[string]string, cmd ...string) error {
+	// Exec does not take an environment, it is set by env(1)
+	command := []string{"env"}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		command = append(command, k+"="+env[k])
+	}
+	command = append(command, cmd...)
+
+	exitCode, output, err := target.Exec(ctx, command)
+	if err != nil {
+		return errors.Wrapf(err, "could not run %s", cmd[0])
+	}
+	if exitCode != 0 {
+		out, _ := ioutil.ReadAll(output)
+		return fmt.Errorf("%s exited with code %d: %s", cmd[0], exitCode, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultSnapshotName is the name of the database a snapshot is kept in, unless WithSnapshotName is given
+const DefaultSnapshotName = "migrated_template"
+
+// SnapshotOption customizes a snapshot and its restoration
+type SnapshotOption func(*snapshotConfig)
+
+type snapshotConfig struct {
+	name string
+}
+
+// WithSnapshotName keeps the snapshot in the database of the given name, to keep several snapshots
+func WithSnapshotName(name string) SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.name = name
+	}
+}
+
+func newSnapshotConfig(opts []SnapshotOption) snapshotConfig {
+	config := snapshotConfig{name: DefaultSnapshotName}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// Snapshot copies the database into a template database, so that Restore resets the database to its current
+// state, e.g. once the migrations are run, in a fraction of the time it takes to recreate the container.
+// The database must not be in use: the connections to it are terminated.
+func (c *PostgresContainer) Snapshot(ctx context.Context, opts ...SnapshotOption) error {
+	config := newSnapshotConfig(opts)
+	if config.name == c.database {
+		return fmt.Errorf("the snapshot cannot be named after the database '%s'", c.database)
+	}
+
+	return c.psql(ctx, c.maintenanceDatabase(), copyDatabase(c.database, config.name, c.user)...)
+}
+
+// Restore resets the database to the state of the last Snapshot, by recreating it from the snapshot.
+// The connections to the database are terminated, the clients holding them need to reconnect.
+func (c *PostgresContainer) Restore(ctx context.Context, opts ...SnapshotOption) error {
+	config := newSnapshotConfig(opts)
+
+	return c.psql(ctx, c.maintenanceDatabase(), copyDatabase(config.name, c.database, c.user)...)
+}
+
+// copyDatabase returns the statements replacing the target database with a copy of the source one.
+// A database can only be copied, or dropped, while nobody is connected to it.
+func copyDatabase(source, target, owner string) []string {
+	return []string{
+		terminateConnections(source),
+		terminateConnections(target),
+		"DROP DATABASE IF EXISTS " + quoteIdentifier(target),
+		fmt.Sprintf("CREATE DATABASE %s WITH TEMPLATE %s OWNER %s",
+			quoteIdentifier(target), quoteIdentifier(source), quoteIdentifier(owner)),
+	}
+}
+
+func terminateConnections(database string) string {
+	return "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = " +
+		quoteLiteral(database) + " AND pid <> pg_backend_pid()"
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+// maintenanceDatabase gets the database to connect to while the database of the container is copied or dropped
+func (c *PostgresContainer) maintenanceDatabase() string {
+	if c.database == "postgres" {
+		return "template1"
+	}
+	return "postgres"
+}
+
+// psql runs the statements in the database one after the other, each in its own transaction as databases
+// cannot be created or dropped in a transaction block
+func (c *PostgresContainer) psql(ctx context.Context, database string, statements ...string) error {
+	cmd := []string{"psql", "-v", "ON_ERROR_STOP=1", "-h", "localhost", "-U", c.user, "-d", database}
+	for _, s := range statements {
+		cmd = append(cmd, "-c", s)
+	}
+
+	return runClient(ctx, c, map[string]string{"PGPASSWORD": c.password}, cmd...)
+}
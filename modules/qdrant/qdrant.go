@@ -0,0 +1,92 @@
+// Package qdrant runs Qdrant containers, a vector database, to test vector search integrations
+package qdrant
+
+import (
+	"context"
+	"net"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultImage is the image run when Run is given no image
+	DefaultImage = "qdrant/qdrant:v1.7.4"
+
+	// RESTPort is the port of the HTTP API
+	RESTPort = "6333/tcp"
+
+	// GRPCPort is the port of the gRPC API
+	GRPCPort = "6334/tcp"
+
+	// APIKeyHeader is the header, or the gRPC metadata, the clients send the API key in, see WithAPIKey
+	APIKeyHeader = "api-key"
+)
+
+// QdrantContainer is a running Qdrant container
+type QdrantContainer struct {
+	testcontainers.Container
+}
+
+// Run creates and starts a Qdrant container of the image, DefaultImage if empty, customized by the options.
+// It is ready once Qdrant reports it is, both APIs then accepting requests.
+func Run(ctx context.Context, image string, opts ...testcontainers.CustomizeRequestOption) (*QdrantContainer, error) {
+	if image == "" {
+		image = DefaultImage
+	}
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{RESTPort, GRPCPort},
+			// the health endpoints are not protected by the API key
+			WaitingFor: wait.ForHTTP("/readyz").WithPort(RESTPort),
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QdrantContainer{Container: c}, nil
+}
+
+// WithAPIKey requires the clients to send the key in the APIKeyHeader of their requests
+func WithAPIKey(key string) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithEnv(map[string]string{"QDRANT__SERVICE__API_KEY": key})
+}
+
+// RESTEndpoint gets the URL of the HTTP API for the clients of the host, e.g. "http://localhost:32768"
+func (c *QdrantContainer) RESTEndpoint(ctx context.Context) (string, error) {
+	address, err := c.address(ctx, RESTPort)
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + address, nil
+}
+
+// GRPCEndpoint gets the address of the gRPC API for the clients of the host, e.g. "localhost:32769"
+func (c *QdrantContainer) GRPCEndpoint(ctx context.Context) (string, error) {
+	return c.address(ctx, GRPCPort)
+}
+
+func (c *QdrantContainer) address(ctx context.Context, port nat.Port) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+	mapped, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mapped.Port()), nil
+}
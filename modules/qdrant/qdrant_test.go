@@ -0,0 +1,72 @@
+package qdrant
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestRunWithAPIKey(t *testing.T) {
+	provider := testcontainers.NewProviderMock()
+	if err := testcontainers.RegisterProvider("qdrant-mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer testcontainers.UnregisterProvider("qdrant-mock")
+	ctx := context.Background()
+
+	c, err := Run(ctx, "", testcontainers.WithProvider("qdrant-mock"), WithAPIKey("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := provider.Containers()[0].Request
+	if req.Env["QDRANT__SERVICE__API_KEY"] != "secret" {
+		t.Fatalf("expected the API key to be set, got %v", req.Env)
+	}
+	rest, err := c.RESTEndpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpc, err := c.GRPCEndpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rest, "http://localhost:") || !strings.HasPrefix(grpc, "localhost:") || rest[len("http://"):] == grpc {
+		t.Fatalf("unexpected endpoints %s and %s", rest, grpc)
+	}
+}
+
+func TestCreateCollection(t *testing.T) {
+	ctx := context.Background()
+	c, err := Run(ctx, "", WithAPIKey("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+
+	endpoint, err := c.RESTEndpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection := `{"vectors":{"size":4,"distance":"Cosine"}}`
+	for key, status := range map[string]int{"": http.StatusUnauthorized, "secret": http.StatusOK} {
+		req, _ := http.NewRequest(http.MethodPut, endpoint+"/collections/docs", strings.NewReader(collection))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Fatalf("expected %d with the key '%s', got %s: %s", status, key, resp.Status, body)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package toxiproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// the streams toxics apply to
+const (
+	Downstream = "downstream" // from the upstream to the client
+	Upstream   = "upstream"   // from the client to the upstream
+)
+
+// Toxic alters the connections of a proxy, see https://github.com/Shopify/toxiproxy#toxics for their types
+// and attributes
+type Toxic struct {
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`     // e.g. "latency", "bandwidth", "timeout"
+	Stream     string                 `json:"stream"`   // Downstream if empty
+	Toxicity   float32                `json:"toxicity"` // the probability the toxic applies to a connection, 1 if 0
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// AddToxic adds the toxic to the proxy
+func (p *Proxy) AddToxic(ctx context.Context, toxic Toxic) (*Toxic, error) {
+	if toxic.Stream == "" {
+		toxic.Stream = Downstream
+	}
+	if toxic.Toxicity == 0 {
+		toxic.Toxicity = 1
+	}
+
+	added := &Toxic{}
+	if err := p.container.call(ctx, http.MethodPost, "/proxies/"+p.Name+"/toxics", toxic, added); err != nil {
+		return nil, fmt.Errorf("could not add toxic '%s' to proxy '%s': %s", toxic.Name, p.Name, err)
+	}
+
+	return added, nil
+}
+
+// AddLatency delays the data sent back to the clients of the proxy by latency, give or take jitter
+func (p *Proxy) AddLatency(ctx context.Context, latency, jitter time.Duration) (*Toxic, error) {
+	return p.AddToxic(ctx, Toxic{
+		Name: "latency",
+		Type: "latency",
+		Attributes: map[string]interface{}{
+			"latency": latency.Nanoseconds() / int64(time.Millisecond),
+			"jitter":  jitter.Nanoseconds() / int64(time.Millisecond),
+		},
+	})
+}
+
+// AddBandwidth limits the rate of the data sent back to the clients of the proxy, in KB per second
+func (p *Proxy) AddBandwidth(ctx context.Context, rate int64) (*Toxic, error) {
+	return p.AddToxic(ctx, Toxic{
+		Name:       "bandwidth",
+		Type:       "bandwidth",
+		Attributes: map[string]interface{}{"rate": rate},
+	})
+}
+
+// RemoveToxic removes the toxic of the given name from the proxy
+func (p *Proxy) RemoveToxic(ctx context.Context, name string) error {
+	if err := p.container.call(ctx, http.MethodDelete, "/proxies/"+p.Name+"/toxics/"+name, nil, nil); err != nil {
+		return fmt.Errorf("could not remove toxic '%s' from proxy '%s': %s", name, p.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+// Package toxiproxy runs Toxiproxy containers, to inject network failures between the code under test
+// and the containers it depends on
+package toxiproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultImage is the image run when Run is given no image
+	DefaultImage = "ghcr.io/shopify/toxiproxy:2.5.0"
+
+	// ControlPort is the port of the HTTP API of Toxiproxy
+	ControlPort = "8474/tcp"
+
+	// FirstProxyPort is the port the first proxy listens on, the next proxies listen on the next ports
+	FirstProxyPort = 8666
+
+	// MaxProxies is how many proxies a container can run, as their ports are exposed when it is created
+	MaxProxies = 16
+)
+
+// ToxiproxyContainer is a running Toxiproxy container
+type ToxiproxyContainer struct {
+	testcontainers.Container
+
+	mutex   sync.Mutex
+	proxies int
+}
+
+// Run creates and starts a Toxiproxy container of the image, DefaultImage if empty, customized by the options.
+// The container must be attached to the networks of the containers it proxies, e.g. with testcontainers.WithNetwork.
+func Run(ctx context.Context, image string, opts ...testcontainers.CustomizeRequestOption) (*ToxiproxyContainer, error) {
+	if image == "" {
+		image = DefaultImage
+	}
+	ports := []string{ControlPort}
+	for i := 0; i < MaxProxies; i++ {
+		ports = append(ports, fmt.Sprintf("%d/tcp", FirstProxyPort+i))
+	}
+
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: ports,
+			WaitingFor:   wait.ForHTTP("/version").WithPort(ControlPort),
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToxiproxyContainer{Container: c}, nil
+}
+
+// Proxy forwards the connections to its port to the upstream, through the toxics added to it
+type Proxy struct {
+	Name     string `json:"name"`
+	Listen   string `json:"listen"`   // the address the proxy listens on in the container
+	Upstream string `json:"upstream"` // the address the proxy forwards to, e.g. "postgres:5432" on a shared network
+	Enabled  bool   `json:"enabled"`
+
+	container *ToxiproxyContainer
+}
+
+// CreateProxy creates a proxy of the given name forwarding the connections to the upstream address, which
+// the container must reach, e.g. "postgres:5432" for a container of alias postgres on a shared network
+func (c *ToxiproxyContainer) CreateProxy(ctx context.Context, name, upstream string) (*Proxy, error) {
+	// the port is only taken once the proxy is created, a proxy which fails to be created leaves it free
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.proxies == MaxProxies {
+		return nil, fmt.Errorf("a container runs at most %d proxies", MaxProxies)
+	}
+	port := FirstProxyPort + c.proxies
+
+	proxy := &Proxy{
+		Name:      name,
+		Listen:    fmt.Sprintf("0.0.0.0:%d", port),
+		Upstream:  upstream,
+		Enabled:   true,
+		container: c,
+	}
+	if err := c.call(ctx, http.MethodPost, "/proxies", proxy, proxy); err != nil {
+		return nil, fmt.Errorf("could not create proxy '%s': %s", name, err)
+	}
+	c.proxies++
+
+	return proxy, nil
+}
+
+// Endpoint gets the address of the proxy for the clients of the host, e.g. "localhost:32768"
+func (p *Proxy) Endpoint(ctx context.Context) (string, error) {
+	_, port, err := net.SplitHostPort(p.Listen)
+	if err != nil {
+		return "", err
+	}
+	host, err := p.container.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+	mapped, err := p.container.MappedPort(ctx, nat.Port(port+"/tcp"))
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mapped.Port()), nil
+}
+
+// Down disables the proxy: its connections are closed and new ones are refused, as if the upstream were down
+func (p *Proxy) Down(ctx context.Context) error {
+	return p.setEnabled(ctx, false)
+}
+
+// Up enables the proxy again after Down
+func (p *Proxy) Up(ctx context.Context) error {
+	return p.setEnabled(ctx, true)
+}
+
+func (p *Proxy) setEnabled(ctx context.Context, enabled bool) error {
+	update := map[string]bool{"enabled": enabled}
+	if err := p.container.call(ctx, http.MethodPost, "/proxies/"+p.Name, update, p); err != nil {
+		return fmt.Errorf("could not update proxy '%s': %s", p.Name, err)
+	}
+
+	return nil
+}
+
+// Delete deletes the proxy, its port is not reused
+func (p *Proxy) Delete(ctx context.Context) error {
+	if err := p.container.call(ctx, http.MethodDelete, "/proxies/"+p.Name, nil, nil); err != nil {
+		return fmt.Errorf("could not delete proxy '%s': %s", p.Name, err)
+	}
+
+	return nil
+}
+
+// call calls the HTTP API of the container, encoding the request and decoding the response as JSON
+func (c *ToxiproxyContainer) call(ctx context.Context, method, path string, request, response interface{}) error {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return err
+	}
+	port, err := c.MappedPort(ctx, ControlPort)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if request != nil {
+		if err := json.NewEncoder(&body).Encode(request); err != nil {
+			return err
+		}
+	}
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(host, port.Port()), path)
+	req, err := http.NewRequest(method, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if response == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,153 @@
+package toxiproxy
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// newFakeToxiproxy runs a fake HTTP API of Toxiproxy, recording the requests it gets
+func newFakeToxiproxy(t *testing.T, requests *[]string) *ToxiproxyContainer {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+string(body)))
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	return &ToxiproxyContainer{Container: &testcontainers.ContainerMock{
+		HostName: host,
+		PortMap: nat.PortMap{
+			ControlPort: {{HostPort: port}},
+			"8666/tcp":  {{HostPort: "32768"}},
+		},
+	}}
+}
+
+func TestProxyAndToxics(t *testing.T) {
+	var requests []string
+	c := newFakeToxiproxy(t, &requests)
+	ctx := context.Background()
+
+	proxy, err := c.CreateProxy(ctx, "postgres", "postgres:5432")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := proxy.Endpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint != "127.0.0.1:32768" {
+		t.Fatalf("expected the first proxy port mapped on the host, got %s", endpoint)
+	}
+
+	if _, err := proxy.AddLatency(ctx, time.Second, 100*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.RemoveToxic(ctx, "latency"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`POST /proxies {"name":"postgres","listen":"0.0.0.0:8666","upstream":"postgres:5432","enabled":true}`,
+		`POST /proxies/postgres/toxics {"name":"latency","type":"latency","stream":"downstream","toxicity":1,"attributes":{"jitter":100,"latency":1000}}`,
+		`POST /proxies/postgres {"enabled":false}`,
+		`DELETE /proxies/postgres/toxics/latency`,
+	}
+	for i := range expected {
+		if i >= len(requests) || requests[i] != expected[i] {
+			t.Fatalf("expected the requests %q, got %q", expected, requests)
+		}
+	}
+}
+
+func TestFailedProxyLeavesItsPortFree(t *testing.T) {
+	var requests []string
+	c := newFakeToxiproxy(t, &requests)
+	ctx := context.Background()
+
+	// the proxy cannot be created while the API is not reachable
+	control, _ := c.MappedPort(ctx, ControlPort)
+	c.Container.(*testcontainers.ContainerMock).PortMap[ControlPort] = []nat.PortBinding{{HostPort: "1"}}
+	if _, err := c.CreateProxy(ctx, "postgres", "postgres:5432"); err == nil {
+		t.Fatal("expected the proxy not to be created")
+	}
+	c.Container.(*testcontainers.ContainerMock).PortMap[ControlPort] = []nat.PortBinding{{HostPort: control.Port()}}
+
+	proxy, err := c.CreateProxy(ctx, "postgres", "postgres:5432")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proxy.Listen != "0.0.0.0:8666" {
+		t.Fatalf("expected the proxy to listen on the first port, got %s", proxy.Listen)
+	}
+}
+
+func TestProxyToContainer(t *testing.T) {
+	ctx := context.Background()
+	network, err := testcontainers.GenericNetwork(ctx, testcontainers.GenericNetworkRequest{
+		NetworkRequest: testcontainers.NetworkRequest{Name: testcontainers.SessionScopedName("toxiproxy")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer network.Remove(ctx)
+
+	nginx, err := testcontainers.Run(ctx, "nginx", testcontainers.WithNetwork(testcontainers.SessionScopedName("toxiproxy"), "nginx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nginx.Terminate(ctx)
+	c, err := Run(ctx, "", testcontainers.WithNetwork(testcontainers.SessionScopedName("toxiproxy")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+
+	proxy, err := c.CreateProxy(ctx, "nginx", "nginx:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := proxy.Endpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proxy.AddLatency(ctx, 500*time.Millisecond, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	begin := time.Now()
+	resp, err := http.Get("http://" + endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if time.Since(begin) < 500*time.Millisecond {
+		t.Fatalf("expected the response to be delayed, got it in %s", time.Since(begin))
+	}
+
+	if err := proxy.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := http.Get("http://" + endpoint); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected the proxy to be down")
+	}
+}
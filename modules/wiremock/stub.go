@@ -0,0 +1,55 @@
+package wiremock
+
+// Stub is a stub mapping, the response WireMock returns to the requests matching its pattern
+type Stub struct {
+	ID       string   `json:"id,omitempty"`
+	Priority int      `json:"priority,omitempty"` // 1 is the highest, for a stub to win over others matching the request
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a pattern requests are matched against. Only one of URL, URLPath and URLPattern is set,
+// URL matching the query string too.
+type Request struct {
+	Method          string             `json:"method,omitempty"` // "ANY" if empty
+	URL             string             `json:"url,omitempty"`
+	URLPath         string             `json:"urlPath,omitempty"`
+	URLPattern      string             `json:"urlPattern,omitempty"`
+	Headers         map[string]Matcher `json:"headers,omitempty"`
+	QueryParameters map[string]Matcher `json:"queryParameters,omitempty"`
+	BodyPatterns    []Matcher          `json:"bodyPatterns,omitempty"`
+}
+
+// Response is the response of a stub
+type Response struct {
+	Status   int               `json:"status,omitempty"` // 200 if zero
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	JSONBody interface{}       `json:"jsonBody,omitempty"` // encoded as the body if set
+	// FixedDelayMilliseconds delays the response, e.g. to test timeouts
+	FixedDelayMilliseconds int `json:"fixedDelayMilliseconds,omitempty"`
+}
+
+// Matcher matches a header, a query parameter or the body of requests, e.g. EqualTo("gopher").
+// It is the matcher of WireMock keyed by its name, for the ones without a function.
+type Matcher map[string]interface{}
+
+// EqualTo matches values equal to the given one
+func EqualTo(value string) Matcher {
+	return Matcher{"equalTo": value}
+}
+
+// Contains matches values containing the given one
+func Contains(value string) Matcher {
+	return Matcher{"contains": value}
+}
+
+// Matches matches values matching the regular expression
+func Matches(regexp string) Matcher {
+	return Matcher{"matches": regexp}
+}
+
+// EqualToJSON matches JSON bodies equal to the given one, whatever the order of their fields
+func EqualToJSON(value string) Matcher {
+	return Matcher{"equalToJson": value}
+}
@@ -0,0 +1,198 @@
+// Package wiremock runs WireMock containers, faking the HTTP services the code under test depends on
+// with a real network hop
+package wiremock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultImage is the image run when Run is given no image
+	DefaultImage = "wiremock/wiremock:3.3.1"
+
+	// HTTPPort is the port of the faked services and of the admin API
+	HTTPPort = "8080/tcp"
+
+	// the directory WireMock loads the stub mappings from when it starts
+	mappingsDir = "/home/wiremock/mappings"
+)
+
+// WireMockContainer is a running WireMock container
+type WireMockContainer struct {
+	testcontainers.Container
+}
+
+// Run creates and starts a WireMock container of the image, DefaultImage if empty, customized by the options
+func Run(ctx context.Context, image string, opts ...testcontainers.CustomizeRequestOption) (*WireMockContainer, error) {
+	if image == "" {
+		image = DefaultImage
+	}
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{HTTPPort},
+			WaitingFor:   wait.ForHTTP("/__admin/mappings").WithPort(HTTPPort),
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WireMockContainer{Container: c}, nil
+}
+
+// WithMappingsDir loads the stub mappings of the JSON files of the directory of the host when WireMock starts.
+// Like all bind mounts, it requires a local daemon, see LoadMappings otherwise.
+func WithMappingsDir(dir string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		return testcontainers.WithMounts(testcontainers.BindMount(abs, mappingsDir))(req)
+	}
+}
+
+// BaseURL gets the URL of the faked services for the clients of the host, e.g. "http://localhost:32768"
+func (c *WireMockContainer) BaseURL(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+	port, err := c.MappedPort(ctx, HTTPPort)
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + net.JoinHostPort(host, port.Port()), nil
+}
+
+// Stub adds the stub mapping and gets its ID, generated by WireMock if the stub has none
+func (c *WireMockContainer) Stub(ctx context.Context, stub Stub) (string, error) {
+	var created Stub
+	if err := c.call(ctx, http.MethodPost, "/__admin/mappings", stub, &created); err != nil {
+		return "", err
+	}
+
+	return created.ID, nil
+}
+
+// LoadMappings adds the stub mappings of the JSON files of the directory, either a mapping or
+// {"mappings": [...]} per file, like the ones WireMock loads when it starts
+func (c *WireMockContainer) LoadMappings(ctx context.Context, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var mappings struct {
+			Mappings json.RawMessage `json:"mappings"`
+		}
+		if err := json.Unmarshal(content, &mappings); err != nil {
+			return fmt.Errorf("could not load mappings of %s: %s", file, err)
+		}
+
+		path := "/__admin/mappings"
+		if mappings.Mappings != nil {
+			path = "/__admin/mappings/import"
+		}
+		if err := c.call(ctx, http.MethodPost, path, json.RawMessage(content), nil); err != nil {
+			return fmt.Errorf("could not load mappings of %s: %s", file, err)
+		}
+	}
+
+	return nil
+}
+
+// RemoveStub removes the stub mapping of the given ID
+func (c *WireMockContainer) RemoveStub(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, "/__admin/mappings/"+id, nil, nil)
+}
+
+// Reset removes the stub mappings added since WireMock started and forgets the requests received, e.g. between tests
+func (c *WireMockContainer) Reset(ctx context.Context) error {
+	return c.call(ctx, http.MethodPost, "/__admin/reset", nil, nil)
+}
+
+// CountRequests counts the requests received matching the pattern
+func (c *WireMockContainer) CountRequests(ctx context.Context, pattern Request) (int, error) {
+	var result struct {
+		Count int `json:"count"`
+	}
+	if err := c.call(ctx, http.MethodPost, "/__admin/requests/count", pattern, &result); err != nil {
+		return 0, err
+	}
+
+	return result.Count, nil
+}
+
+// Verify checks that exactly count requests received match the pattern
+func (c *WireMockContainer) Verify(ctx context.Context, pattern Request, count int) error {
+	actual, err := c.CountRequests(ctx, pattern)
+	if err != nil {
+		return err
+	}
+	if actual != count {
+		return fmt.Errorf("expected %d requests matching %+v, got %d", count, pattern, actual)
+	}
+
+	return nil
+}
+
+// call calls the admin API of WireMock, encoding the request and decoding the response in JSON
+func (c *WireMockContainer) call(ctx context.Context, method, path string, request, response interface{}) error {
+	baseURL, err := c.BaseURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if request != nil {
+		if err := json.NewEncoder(&body).Encode(request); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if response == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,111 @@
+package wiremock
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestAdminAPIRequests(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+strings.TrimSpace(string(body)))
+		switch r.URL.Path {
+		case "/__admin/mappings":
+			fmt.Fprint(w, `{"id":"5f0e"}`)
+		case "/__admin/requests/count":
+			fmt.Fprint(w, `{"count":1}`)
+		}
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	c := &WireMockContainer{Container: &testcontainers.ContainerMock{
+		HostName: host,
+		PortMap:  nat.PortMap{HTTPPort: {{HostPort: port}}},
+	}}
+	ctx := context.Background()
+
+	id, err := c.Stub(ctx, Stub{
+		Request:  Request{Method: http.MethodGet, URLPath: "/users", QueryParameters: map[string]Matcher{"name": EqualTo("gopher")}},
+		Response: Response{Status: http.StatusOK, JSONBody: []string{"gopher"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "5f0e" {
+		t.Fatalf("expected the ID of the stub, got %s", id)
+	}
+	if err := c.LoadMappings(ctx, "testdata/mappings"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Verify(ctx, Request{URLPath: "/users"}, 2); err == nil || !strings.Contains(err.Error(), "got 1") {
+		t.Fatalf("expected the verification to fail, got %v", err)
+	}
+
+	expected := []string{
+		`POST /__admin/mappings {"request":{"method":"GET","urlPath":"/users","queryParameters":{"name":{"equalTo":"gopher"}}},"response":{"status":200,"jsonBody":["gopher"]}}`,
+		`POST /__admin/mappings`,
+		`POST /__admin/mappings/import`,
+		`POST /__admin/requests/count {"urlPath":"/users"}`,
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("expected the requests %q, got %q", expected, requests)
+	}
+	for i := range expected {
+		if !strings.HasPrefix(requests[i], expected[i]) {
+			t.Fatalf("expected the request %q, got %q", expected[i], requests[i])
+		}
+	}
+}
+
+func TestFakeService(t *testing.T) {
+	ctx := context.Background()
+	c, err := Run(ctx, "", WithMappingsDir("testdata/mappings"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+
+	_, err = c.Stub(ctx, Stub{
+		Request:  Request{Method: http.MethodPost, URL: "/orders", BodyPatterns: []Matcher{EqualToJSON(`{"item":"book"}`)}},
+		Response: Response{Status: http.StatusCreated},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseURL, err := c.BaseURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Post(baseURL+"/orders", "application/json", strings.NewReader(`{"item": "book"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected the stubbed status, got %s", resp.Status)
+	}
+	resp, err = http.Get(baseURL + "/users/gopher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(user), "gopher") {
+		t.Fatalf("expected the user of the mappings directory, got %s", user)
+	}
+
+	if err := c.Verify(ctx, Request{Method: http.MethodPost, URL: "/orders"}, 1); err != nil {
+		t.Fatal(err)
+	}
+}
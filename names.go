@@ -0,0 +1,53 @@
+package testcontainers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// the number of names generated per base, so that generated names do not collide within the session
+var (
+	generatedNamesMutex sync.Mutex
+	generatedNames      = map[string]int{}
+)
+
+// SessionScopedName returns a container name made of base and of the session of the process, e.g.
+// "kafka-1b4e28ba", so that the names used by concurrent runs on the same daemon do not collide. The
+// characters which are not allowed in container names are replaced with "-".
+func SessionScopedName(base string) string {
+	clean := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, base)
+	clean = strings.TrimLeft(clean, "_.-")
+	if clean == "" {
+		clean = "testcontainers"
+	}
+
+	return fmt.Sprintf("%s-%s", clean, sessionID.String()[:8])
+}
+
+// generateName returns the session scoped name of the container of the image, numbered from the second
+// container of the image, e.g. "postgres-1b4e28ba" then "postgres-1b4e28ba-2"
+func generateName(image string) string {
+	base := image
+	if i := strings.LastIndex(base, "/"); i >= 0 {
+		base = base[i+1:]
+	}
+	if i := strings.IndexAny(base, ":@"); i >= 0 {
+		base = base[:i]
+	}
+	name := SessionScopedName(base)
+
+	generatedNamesMutex.Lock()
+	defer generatedNamesMutex.Unlock()
+	generatedNames[name]++
+	if n := generatedNames[name]; n > 1 {
+		return fmt.Sprintf("%s-%d", name, n)
+	}
+
+	return name
+}
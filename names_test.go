@@ -0,0 +1,29 @@
+package testcontainers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSessionScopedName(t *testing.T) {
+	suffix := "-" + sessionID.String()[:8]
+
+	if name := SessionScopedName("kafka"); name != "kafka"+suffix {
+		t.Fatalf("expected the name to end with the session, got %s", name)
+	}
+	if name := SessionScopedName("_my app/db"); name != "my-app-db"+suffix {
+		t.Fatalf("expected the invalid characters to be replaced, got %s", name)
+	}
+	if SessionScopedName("kafka") != SessionScopedName("kafka") {
+		t.Fatal("expected the name to be deterministic")
+	}
+}
+
+func TestGenerateName(t *testing.T) {
+	first := generateName("docker.io/library/mariadb:10.4")
+	second := generateName("mariadb@sha256:0123")
+
+	if !strings.HasPrefix(first, "mariadb-") || second != first+"-2" {
+		t.Fatalf("expected distinct names for the containers of the image, got %s and %s", first, second)
+	}
+}
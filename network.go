@@ -0,0 +1,36 @@
+package testcontainers
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/network"
+)
+
+// NetworkProvider allows the creation of networks on an arbitrary system
+type NetworkProvider interface {
+	CreateNetwork(context.Context, NetworkRequest) (Network, error) // create a network
+}
+
+// Network allows getting info about and controlling a single network instance
+type Network interface {
+	Remove(context.Context) error // removes the network
+}
+
+// NetworkRequest represents the parameters used to get a network
+type NetworkRequest struct {
+	Name           string
+	Driver         string            // the network driver, "bridge" if empty, e.g. "macvlan" or "overlay" in swarm mode
+	Options        map[string]string // driver specific options, e.g. "parent" for macvlan
+	CheckDuplicate bool              // fail if a network with the same name already exists
+	EnableIPv6     bool
+	Internal       bool // restrict external access to the network, i.e. containers on it have no egress
+	Attachable     bool // allow standalone containers to attach to a swarm scoped network
+	Labels         map[string]string
+	IPAM           *network.IPAM // subnets, IP ranges and gateways of the network
+
+	// Reuse gets the network with the Name of the request if it exists rather than creating it, so that the
+	// test processes of several packages share it. A reused network is not reaped, nor removed with the session.
+	Reuse bool
+
+	SkipReaper bool // indicates whether we skip setting up a reaper for this
+}
@@ -0,0 +1,250 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestContainersAttachedToGenericNetwork(t *testing.T) {
+	ctx := context.Background()
+	networkName := fmt.Sprintf("%s_%d", "test_network", time.Now().Unix())
+	net, err := GenericNetwork(ctx, GenericNetworkRequest{
+		NetworkRequest: NetworkRequest{
+			Name:           networkName,
+			CheckDuplicate: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := net.Remove(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		nginx, err := GenericContainer(ctx, GenericContainerRequest{
+			ContainerRequest: ContainerRequest{
+				Image:        "nginx",
+				ExposedPorts: []string{"80/tcp"},
+				Networks:     []string{networkName},
+			},
+			Started: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer nginx.Terminate(ctx)
+	}
+}
+
+func TestContainerWithStaticIPInNetworkWithIPAM(t *testing.T) {
+	ctx := context.Background()
+	networkName := fmt.Sprintf("%s_%d", "test_ipam_network", time.Now().Unix())
+	net, err := GenericNetwork(ctx, GenericNetworkRequest{
+		NetworkRequest: NetworkRequest{
+			Name: networkName,
+			IPAM: &network.IPAM{
+				Config: []network.IPAMConfig{
+					{Subnet: "10.77.0.0/16", Gateway: "10.77.0.1"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer net.Remove(ctx)
+
+	nginx, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:      "nginx",
+			Networks:   []string{networkName},
+			NetworkIPs: map[string]string{networkName: "10.77.0.10"},
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nginx.Terminate(ctx)
+
+	inspect, err := nginx.(*DockerContainer).inspectContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip := inspect.NetworkSettings.Networks[networkName].IPAddress; ip != "10.77.0.10" {
+		t.Fatalf("expected static IP 10.77.0.10, got '%s'", ip)
+	}
+}
+
+func TestContainerDisconnectAndReconnectToNetwork(t *testing.T) {
+	ctx := context.Background()
+	networkName := fmt.Sprintf("%s_%d", "test_partition_network", time.Now().Unix())
+	net, err := GenericNetwork(ctx, GenericNetworkRequest{
+		NetworkRequest: NetworkRequest{
+			Name: networkName,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer net.Remove(ctx)
+
+	nginx, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:    "nginx",
+			Networks: []string{networkName},
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nginx.Terminate(ctx)
+
+	if err := nginx.DisconnectFromNetwork(ctx, networkName); err != nil {
+		t.Fatal(err)
+	}
+	inspect, err := nginx.(*DockerContainer).inspectContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := inspect.NetworkSettings.Networks[networkName]; ok {
+		t.Fatalf("expected container to be detached from network '%s'", networkName)
+	}
+
+	if err := nginx.ConnectToNetwork(ctx, networkName); err != nil {
+		t.Fatal(err)
+	}
+	inspect, err = nginx.(*DockerContainer).inspectContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := inspect.NetworkSettings.Networks[networkName]; !ok {
+		t.Fatalf("expected container to be attached to network '%s'", networkName)
+	}
+}
+
+func TestContainerInInternalNetworkHasNoEgress(t *testing.T) {
+	ctx := context.Background()
+	networkName := fmt.Sprintf("%s_%d", "test_internal_network", time.Now().Unix())
+	net, err := GenericNetwork(ctx, GenericNetworkRequest{
+		NetworkRequest: NetworkRequest{
+			Name:     networkName,
+			Internal: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer net.Remove(ctx)
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:      "alpine:3.10",
+			Entrypoint: []string{"sh", "-c", "wget -q -T 2 -O - http://example.com || echo 'no egress'; sleep 60"},
+			Networks:   []string{networkName},
+			WaitingFor: wait.ForLog("no egress"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(ctx)
+}
+
+func TestContainersReachEachOtherOnSessionNetwork(t *testing.T) {
+	ctx := context.Background()
+	serverName := fmt.Sprintf("%s_%d", "session_server", time.Now().Unix())
+	server, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:          "nginx",
+			Name:           serverName,
+			SessionNetwork: true,
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Terminate(ctx)
+
+	client, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:          "alpine:3.10",
+			Entrypoint:     []string{"sh", "-c", fmt.Sprintf("wget -q -O /dev/null http://%s && echo 'reached server'; sleep 60", serverName)},
+			SessionNetwork: true,
+			WaitingFor:     wait.ForLog("reached server"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Terminate(ctx)
+}
+
+func TestReusedNetworkCreatedConcurrently(t *testing.T) {
+	var created bool
+	var labels map[string]string
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/networks/create"):
+			var req types.NetworkCreateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			labels = req.Labels
+			// another process created the network since it was looked up
+			created = true
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintln(w, `{"message":"network with name shared already exists"}`)
+		case strings.HasSuffix(r.URL.Path, "/networks/shared") && created:
+			json.NewEncoder(w).Encode(types.NetworkResource{ID: "abc", Name: "shared", Driver: "bridge"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	n, err := provider.CreateNetwork(context.Background(), NetworkRequest{Name: "shared", Reuse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.(*DockerNetwork).ID != "abc" {
+		t.Fatalf("expected the network created by the other process, got %+v", n)
+	}
+	if labels[TestcontainerLabel] != "true" || labels[TestcontainerLabelSessionID] != "" {
+		t.Fatalf("expected the network to be labelled but not with the session, got %v", labels)
+	}
+
+	if _, err := provider.CreateNetwork(context.Background(), NetworkRequest{Reuse: true}); err == nil {
+		t.Fatal("expected a network without name not to be reused")
+	}
+}
+
+func TestReusedMockNetwork(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+	first, err := provider.CreateNetwork(ctx, NetworkRequest{Name: "shared", Reuse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := provider.CreateNetwork(ctx, NetworkRequest{Name: "shared", Reuse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatal("expected the network to be reused")
+	}
+}
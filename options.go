@@ -0,0 +1,237 @@
+package testcontainers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// CustomizeRequestOption customizes the request of a container, for Run. Options can be shared
+// between tests and modules, and applied one after the other to the same request.
+type CustomizeRequestOption func(req *GenericContainerRequest) error
+
+// Run creates and starts a container of the image, customized by the options in order
+func Run(ctx context.Context, image string, opts ...CustomizeRequestOption) (Container, error) {
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image: image,
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return nil, errors.Wrap(err, "failed to customize container request")
+		}
+	}
+
+	return GenericContainer(ctx, req)
+}
+
+// WithEnv adds env variables to the container, replacing the ones with the same name
+func WithEnv(env map[string]string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if req.Env == nil {
+			req.Env = map[string]string{}
+		}
+		for k, v := range env {
+			req.Env[k] = v
+		}
+		return nil
+	}
+}
+
+// WithExposedPorts exposes ports of the container, e.g. "80/tcp"
+func WithExposedPorts(ports ...string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.ExposedPorts = append(req.ExposedPorts, ports...)
+		return nil
+	}
+}
+
+// WithWaitStrategy sets the strategy to wait for before the container is considered started
+func WithWaitStrategy(strategy wait.Strategy) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.WaitingFor = strategy
+		return nil
+	}
+}
+
+// WithNetwork attaches the container to the network, reachable there under the given aliases
+func WithNetwork(network string, aliases ...string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if network == "" {
+			return errors.New("no network name")
+		}
+		req.Networks = append(req.Networks, network)
+		if len(aliases) > 0 {
+			if req.NetworkAliases == nil {
+				req.NetworkAliases = map[string][]string{}
+			}
+			req.NetworkAliases[network] = append(req.NetworkAliases[network], aliases...)
+		}
+		return nil
+	}
+}
+
+// ContainerMount is a mount of a host path or of a named volume into a container
+type ContainerMount struct {
+	Source string // the path on the host, or the name of the volume
+	Target string // the path in the container
+	Volume bool   // whether Source is a named volume rather than a path on the host
+}
+
+// BindMount mounts a path of the host into the container
+func BindMount(hostPath string, target string) ContainerMount {
+	return ContainerMount{Source: hostPath, Target: target}
+}
+
+// VolumeMount mounts a named volume into the container
+func VolumeMount(volume string, target string) ContainerMount {
+	return ContainerMount{Source: volume, Target: target, Volume: true}
+}
+
+// WithMounts mounts host paths and named volumes into the container
+func WithMounts(mounts ...ContainerMount) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		for _, m := range mounts {
+			if m.Source == "" || m.Target == "" {
+				return errors.Errorf("invalid mount of '%s' to '%s'", m.Source, m.Target)
+			}
+			if m.Volume {
+				if req.VolumeMounts == nil {
+					req.VolumeMounts = map[string]string{}
+				}
+				req.VolumeMounts[m.Source] = m.Target
+				continue
+			}
+			if req.BindMounts == nil {
+				req.BindMounts = map[string]string{}
+			}
+			req.BindMounts[m.Source] = m.Target
+		}
+		return nil
+	}
+}
+
+// WithCmd sets the command of the container
+func WithCmd(cmd string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.Cmd = cmd
+		return nil
+	}
+}
+
+// WithCommand sets the command of the container as a list of arguments, which may contain spaces
+func WithCommand(args ...string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.Command = args
+		return nil
+	}
+}
+
+// WithEntrypoint sets the entrypoint of the container
+func WithEntrypoint(entrypoint ...string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.Entrypoint = entrypoint
+		return nil
+	}
+}
+
+// WithLabels adds labels to the container, replacing the ones with the same name
+func WithLabels(labels map[string]string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		if req.Labels == nil {
+			req.Labels = map[string]string{}
+		}
+		for k, v := range labels {
+			req.Labels[k] = v
+		}
+		return nil
+	}
+}
+
+// WithRegistryCred sets the credentials to pull the image with
+func WithRegistryCred(cred string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.RegistryCred = cred
+		return nil
+	}
+}
+
+// WithName sets the name of the container
+func WithName(name string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.Name = name
+		return nil
+	}
+}
+
+// WithReuse reuses the container defined by the request if it exists, see GenericContainerRequest.Reuse
+func WithReuse() CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.Reuse = true
+		return nil
+	}
+}
+
+// WithSessionID labels the container with the given session rather than the session of the process,
+// it is reaped with the other resources of that session
+func WithSessionID(session string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.SessionID = session
+		return nil
+	}
+}
+
+// WithRetryPolicy retries the operations which may fail transiently with the given policy rather than
+// with DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.RetryPolicy = &policy
+		return nil
+	}
+}
+
+// WithEnvExpansion replaces the ${VAR} and ${VAR:-default} references of the request with the env of the host,
+// see ContainerRequest.ExpandEnv
+func WithEnvExpansion() CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.ExpandEnv = true
+		return nil
+	}
+}
+
+// WithHostPortRetries recreates the container with the next free ports of the host, up to the given number of
+// times, when a fixed port of the host it binds is already allocated
+func WithHostPortRetries(retries int) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.HostPortRetries = retries
+		return nil
+	}
+}
+
+// WithPreTerminateHooks adds hooks called just before the container is terminated, see CollectLogs and CollectFiles
+func WithPreTerminateHooks(hooks ...ContainerHook) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.PreTerminateHooks = append(req.PreTerminateHooks, hooks...)
+		return nil
+	}
+}
+
+// WithDryRun logs the docker commands equivalent to the request rather than running them,
+// see GenericContainerRequest.DryRun
+func WithDryRun() CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.DryRun = true
+		return nil
+	}
+}
+
+// WithProvider runs the container with the provider registered under the name
+func WithProvider(name string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) error {
+		req.ProviderName = name
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestRunWithOptions(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+	var req ContainerRequest
+	provider.OnCreate = func(r ContainerRequest, c *ContainerMock) error {
+		req = r
+		return nil
+	}
+	if err := RegisterProvider("options", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("options")
+
+	strategy := wait.ForLog("ready")
+	c, err := Run(ctx, "postgres:12",
+		WithProvider("options"),
+		WithEnv(map[string]string{"POSTGRES_PASSWORD": "secret"}),
+		WithExposedPorts("5432/tcp"),
+		WithWaitStrategy(strategy),
+		WithNetwork("backend", "db"),
+		WithMounts(BindMount("/tmp/init", "/docker-entrypoint-initdb.d"), VolumeMount("pgdata", "/var/lib/postgresql/data")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running, _ := c.IsRunning(ctx); !running {
+		t.Fatal("expected the container to be started")
+	}
+
+	if req.Image != "postgres:12" || req.Env["POSTGRES_PASSWORD"] != "secret" {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	if len(req.ExposedPorts) != 1 || req.WaitingFor != strategy {
+		t.Fatalf("expected the port and the strategy to be set, got %+v", req)
+	}
+	if len(req.Networks) != 1 || req.NetworkAliases["backend"][0] != "db" {
+		t.Fatalf("expected the network to be set, got %v %v", req.Networks, req.NetworkAliases)
+	}
+	if req.BindMounts["/tmp/init"] != "/docker-entrypoint-initdb.d" || req.VolumeMounts["pgdata"] != "/var/lib/postgresql/data" {
+		t.Fatalf("expected the mounts to be set, got %v %v", req.BindMounts, req.VolumeMounts)
+	}
+}
+
+func TestRunWithInvalidOption(t *testing.T) {
+	_, err := Run(context.Background(), "nginx", WithMounts(BindMount("", "/data")))
+	if err == nil {
+		t.Fatal("expected an error for an invalid mount")
+	}
+}
@@ -0,0 +1,67 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// defaultParallelWorkers is the default number of containers ParallelContainers creates at once
+const defaultParallelWorkers = 8
+
+// ParallelContainerRequest is a set of requests of containers to create concurrently
+type ParallelContainerRequest []GenericContainerRequest
+
+// ParallelContainersOptions represents parameters to ParallelContainers
+type ParallelContainersOptions struct {
+	WorkersCount int // how many containers to create at once, 8 by default
+}
+
+// ParallelContainersRequestError is the failure of one of the requests of ParallelContainers
+type ParallelContainersRequestError struct {
+	Request GenericContainerRequest
+	Error   error
+}
+
+// ParallelContainersError aggregates the failures of the requests of ParallelContainers
+type ParallelContainersError struct {
+	Errors []ParallelContainersRequestError
+}
+
+func (e ParallelContainersError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, re := range e.Errors {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", re.Request.Image, re.Error))
+	}
+
+	return fmt.Sprintf("%d of the containers failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// ParallelContainers creates the containers of the requests concurrently, with a bounded number of workers,
+// and returns them in the order of the requests. All the requests are attempted: the error is
+// a ParallelContainersError reporting the ones which failed. The container of a failed request is nil,
+// unless it was created but could not be started, in which case it is returned to be terminated.
+func ParallelContainers(ctx context.Context, reqs ParallelContainerRequest, opt ParallelContainersOptions) ([]Container, error) {
+	workers := opt.WorkersCount
+	if workers <= 0 {
+		workers = defaultParallelWorkers
+	}
+
+	containers := make([]Container, len(reqs))
+	errs := make([]error, len(reqs))
+	runConcurrently(len(reqs), workers, func(i int) {
+		containers[i], errs[i] = GenericContainer(ctx, reqs[i])
+	})
+
+	failures := ParallelContainersError{}
+	for i, err := range errs {
+		if err != nil {
+			failures.Errors = append(failures.Errors, ParallelContainersRequestError{Request: reqs[i], Error: err})
+		}
+	}
+	if len(failures.Errors) > 0 {
+		return containers, failures
+	}
+
+	return containers, nil
+}
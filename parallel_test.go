@@ -0,0 +1,63 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParallelContainers(t *testing.T) {
+	provider := NewProviderMock()
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		switch req.Image {
+		case "missing":
+			return errors.New("image not found")
+		case "broken":
+			c.StartErr = errors.New("exec format error")
+		}
+		return nil
+	}
+	if err := RegisterProvider("mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("mock")
+
+	images := []string{"nginx", "missing", "redis", "broken", "postgres"}
+	reqs := ParallelContainerRequest{}
+	for _, image := range images {
+		reqs = append(reqs, GenericContainerRequest{
+			ContainerRequest: ContainerRequest{Image: image},
+			Started:          true,
+			ProviderName:     "mock",
+		})
+	}
+
+	containers, err := ParallelContainers(context.Background(), reqs, ParallelContainersOptions{WorkersCount: 2})
+	var failures ParallelContainersError
+	if !errors.As(err, &failures) {
+		t.Fatalf("expected a ParallelContainersError, got %v", err)
+	}
+	if len(failures.Errors) != 2 {
+		t.Fatalf("expected 2 failures, got %s", err)
+	}
+	if !strings.Contains(err.Error(), "missing: ") || !strings.Contains(err.Error(), "broken: ") {
+		t.Fatalf("unexpected error '%s'", err)
+	}
+
+	if len(containers) != len(images) {
+		t.Fatalf("expected %d containers, got %d", len(images), len(containers))
+	}
+	for i, image := range images {
+		c := containers[i]
+		if image == "missing" {
+			if c != nil {
+				t.Fatalf("expected no container for %s", image)
+			}
+			continue
+		}
+		if c.(*ContainerMock).Request.Image != image {
+			t.Fatalf("expected container %d to be %s", i, image)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+// dryRunProvider creates the containers of the requests run dry, so that the setup of the test goes on
+var dryRunProvider = NewProviderMock()
+
+// DockerCommands returns a shell script of the docker commands equivalent to the creation of the container
+// of the request, to review what a test setup does. The image built from a Dockerfile and the container are
+// referred to as $IMAGE and $CONTAINER. The labels of the session are left out, as they depend on the run.
+func (c *ContainerRequest) DockerCommands() ([]string, error) {
+	if _, _, err := nat.ParsePortSpecs(c.ExposedPorts); err != nil {
+		return nil, err
+	}
+
+	commands := []string{}
+	image := shellQuote(c.Image)
+	if c.ShouldBuildImage() {
+		build := []string{"docker", "build", "-q", "-f", shellQuote(c.GetDockerfile())}
+		for _, k := range sortedKeys(buildArgs(c.BuildArgs)) {
+			build = append(build, "--build-arg", shellQuote(k+"="+buildArgs(c.BuildArgs)[k]))
+		}
+		build = append(build, shellQuote(c.FromDockerfile.Context))
+		commands = append(commands, "IMAGE=$("+strings.Join(build, " ")+")")
+		image = "$IMAGE"
+	}
+
+	create := []string{"docker", "create"}
+	add := func(flag, value string) {
+		create = append(create, flag, shellQuote(value))
+	}
+	if c.Name != "" {
+		add("--name", c.Name)
+	}
+	if !c.DontRemove {
+		create = append(create, "--rm")
+	}
+	for _, k := range sortedKeys(c.Labels) {
+		add("--label", k+"="+c.Labels[k])
+	}
+	for _, k := range sortedKeys(c.Env) {
+		add("-e", k+"="+c.Env[k])
+	}
+	for _, port := range c.ExposedPorts {
+		add("-p", port)
+	}
+	for _, k := range sortedKeys(c.BindMounts) {
+		add("-v", k+":"+c.BindMounts[k])
+	}
+	for _, k := range sortedKeys(c.VolumeMounts) {
+		add("-v", k+":"+c.VolumeMounts[k])
+	}
+	if c.Privileged {
+		create = append(create, "--privileged")
+	}
+	for _, capability := range c.CapAdd {
+		add("--cap-add", capability)
+	}
+	if len(c.HostAccessPorts) > 0 {
+		add("--add-host", HostInternal+":host-gateway")
+	}
+	if c.HealthCheck != nil && len(c.HealthCheck.Test) > 0 {
+		switch c.HealthCheck.Test[0] {
+		case "NONE":
+			create = append(create, "--no-healthcheck")
+		case "CMD-SHELL":
+			add("--health-cmd", strings.Join(c.HealthCheck.Test[1:], " "))
+		case "CMD":
+			args := []string{}
+			for _, arg := range c.HealthCheck.Test[1:] {
+				args = append(args, shellQuote(arg))
+			}
+			add("--health-cmd", strings.Join(args, " "))
+		}
+		if c.HealthCheck.Interval > 0 {
+			add("--health-interval", c.HealthCheck.Interval.String())
+		}
+		if c.HealthCheck.Timeout > 0 {
+			add("--health-timeout", c.HealthCheck.Timeout.String())
+		}
+		if c.HealthCheck.StartPeriod > 0 {
+			add("--health-start-period", c.HealthCheck.StartPeriod.String())
+		}
+		if c.HealthCheck.Retries > 0 {
+			add("--health-retries", fmt.Sprintf("%d", c.HealthCheck.Retries))
+		}
+	}
+
+	networks := append([]string{}, c.Networks...)
+	if c.SessionNetwork {
+		networks = append(networks, "$SESSION_NETWORK")
+	}
+	if len(networks) > 0 {
+		create = append(create, networkFlags(c, networks[0])...)
+	} else if c.NetworkMode != "" {
+		add("--network", string(c.NetworkMode))
+	}
+	if len(c.Entrypoint) > 0 {
+		// the CLI only takes the executable of the entrypoint, its arguments come first in the command
+		add("--entrypoint", c.Entrypoint[0])
+	}
+	create = append(create, image)
+	if len(c.Entrypoint) > 1 {
+		for _, arg := range c.Entrypoint[1:] {
+			create = append(create, shellQuote(arg))
+		}
+	}
+	command := c.Command
+	if len(command) == 0 && c.Cmd != "" {
+		command = strings.Split(c.Cmd, " ")
+	}
+	for _, arg := range command {
+		create = append(create, shellQuote(arg))
+	}
+	commands = append(commands, "CONTAINER=$("+strings.Join(create, " ")+")")
+
+	for i, n := range networks {
+		if i == 0 {
+			continue
+		}
+		connect := append([]string{"docker", "network", "connect"}, networkFlags(c, n)[2:]...)
+		connect = append(connect, networkName(n), "$CONTAINER")
+		commands = append(commands, strings.Join(connect, " "))
+	}
+
+	for _, f := range c.Files {
+		commands = append(commands, fmt.Sprintf("docker cp %s %s", shellQuote(f.HostFilePath), "$CONTAINER:"+shellQuote(f.ContainerFilePath)))
+	}
+
+	return commands, nil
+}
+
+// networkFlags returns the flags attaching the container of the request to the network on creation
+func networkFlags(c *ContainerRequest, n string) []string {
+	flags := []string{"--network", networkName(n)}
+	for _, alias := range c.NetworkAliases[n] {
+		flags = append(flags, "--network-alias", shellQuote(alias))
+	}
+	if c.SessionNetwork && n == "$SESSION_NETWORK" && c.Name != "" {
+		flags = append(flags, "--network-alias", shellQuote(c.Name))
+	}
+	if ip, ok := c.NetworkIPs[n]; ok {
+		if strings.Contains(ip, ":") {
+			flags = append(flags, "--ip6", shellQuote(ip))
+		} else {
+			flags = append(flags, "--ip", shellQuote(ip))
+		}
+	}
+
+	return flags
+}
+
+// networkName quotes the name of the network, unless it is the variable of the session network
+func networkName(n string) string {
+	if n == "$SESSION_NETWORK" {
+		return n
+	}
+
+	return shellQuote(n)
+}
+
+// dryRun reports the docker commands of the request through the Logger and creates a mock container for it,
+// so that the rest of the setup of the test is run dry as well
+func dryRun(ctx context.Context, req GenericContainerRequest) (Container, error) {
+	commands, err := req.DockerCommands()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid container request")
+	}
+	if req.Started {
+		commands = append(commands, "docker start $CONTAINER")
+	}
+	Logger.Printf("Dry run of container %s:\n%s", req.Image, strings.Join(commands, "\n"))
+
+	// the mock does not run the wait strategies, there is nothing to wait for
+	var c Container
+	if req.Reuse {
+		c, err = dryRunProvider.ReuseOrCreateContainer(ctx, req.ContainerRequest)
+	} else {
+		c, err = dryRunProvider.CreateContainer(ctx, req.ContainerRequest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Started {
+		if err := c.Start(ctx); err != nil {
+			return c, err
+		}
+	}
+
+	return c, nil
+}
+
+// buildArgs gets the values of the build args, empty for the ones without value
+func buildArgs(args map[string]*string) map[string]string {
+	values := make(map[string]string, len(args))
+	for k, v := range args {
+		if v != nil {
+			values[k] = *v
+		} else {
+			values[k] = ""
+		}
+	}
+
+	return values
+}
+
+// sortedKeys gets the keys of the map in order, for a stable output
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// shellQuote quotes the word for a POSIX shell, if it has characters the shell would interpret
+func shellQuote(word string) string {
+	if word != "" && strings.IndexFunc(word, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+%", r))
+	}) < 0 {
+		return word
+	}
+
+	return "'" + strings.Replace(word, "'", `'\''`, -1) + "'"
+}
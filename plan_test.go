@@ -0,0 +1,63 @@
+package testcontainers
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDockerCommands(t *testing.T) {
+	req := ContainerRequest{
+		Image:          "postgres:11",
+		Name:           "db",
+		Env:            map[string]string{"POSTGRES_PASSWORD": "s3cret pass", "POSTGRES_DB": "app"},
+		ExposedPorts:   []string{"5432/tcp"},
+		BindMounts:     map[string]string{"/tmp/init": "/docker-entrypoint-initdb.d"},
+		Networks:       []string{"backend", "monitoring"},
+		NetworkAliases: map[string][]string{"backend": {"postgres"}},
+		Command:        []string{"postgres", "-c", "log_statement=all"},
+		Files:          []ContainerFile{{HostFilePath: "testdata/my init.sql", ContainerFilePath: "/docker-entrypoint-initdb.d/init.sql"}},
+	}
+
+	commands, err := req.DockerCommands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"CONTAINER=$(docker create --name db --rm -e POSTGRES_DB=app -e 'POSTGRES_PASSWORD=s3cret pass' -p 5432/tcp " +
+			"-v /tmp/init:/docker-entrypoint-initdb.d --network backend --network-alias postgres " +
+			"postgres:11 postgres -c log_statement=all)",
+		"docker network connect monitoring $CONTAINER",
+		"docker cp 'testdata/my init.sql' $CONTAINER:/docker-entrypoint-initdb.d/init.sql",
+	}
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected the commands\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(commands, "\n"))
+	}
+
+	req.ExposedPorts = []string{"not a port"}
+	if _, err := req.DockerCommands(); err == nil {
+		t.Fatal("expected an invalid port to be reported")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	output := &bytes.Buffer{}
+	Logger = log.New(output, "", 0)
+	defer func() { Logger = log.New(os.Stderr, "", log.LstdFlags) }()
+
+	c, err := Run(context.Background(), "redis", WithExposedPorts("6379/tcp"), WithDryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Terminate(context.Background())
+
+	if _, ok := c.(*ContainerMock); !ok {
+		t.Fatalf("expected a mock container, got %T", c)
+	}
+	if !strings.Contains(output.String(), "CONTAINER=$(docker create --rm -p 6379/tcp redis)\ndocker start $CONTAINER") {
+		t.Fatalf("expected the commands to be logged, got %q", output.String())
+	}
+}
@@ -0,0 +1,136 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// PruneOptions selects the resources left behind by testcontainers that Prune removes,
+// e.g. after crashed runs on a CI agent where the reaper could not run
+type PruneOptions struct {
+	Containers bool          // prune containers, all the kinds of resources are pruned if none is selected
+	Networks   bool          // prune networks
+	Volumes    bool          // prune volumes
+	Images     bool          // prune built images
+	OlderThan  time.Duration // only prune the resources created longer ago, whatever their age if 0
+	SessionID  string        // only prune the resources of the session, of all the sessions if empty
+	DryRun     bool          // only report the resources which would be pruned
+}
+
+// PruneReport lists the resources pruned, or which would be pruned in a dry run
+type PruneReport struct {
+	Containers []string // IDs of the containers
+	Networks   []string // names of the networks
+	Volumes    []string // names of the volumes
+	Images     []string // IDs of the images
+}
+
+// Prune removes the containers, networks, volumes and images labelled by testcontainers. Containers are
+// removed first, as networks and volumes cannot be removed while they are in use. All the resources are
+// attempted, the returned error reports the ones that could not be removed.
+func (p *DockerProvider) Prune(ctx context.Context, opts PruneOptions) (PruneReport, error) {
+	all := !opts.Containers && !opts.Networks && !opts.Volumes && !opts.Images
+	f := filters.NewArgs(filters.Arg("label", TestcontainerLabel+"=true"))
+	if opts.SessionID != "" {
+		f.Add("label", TestcontainerLabelSessionID+"="+opts.SessionID)
+	}
+	old := func(created time.Time) bool {
+		return opts.OlderThan <= 0 || time.Since(created) > opts.OlderThan
+	}
+
+	report := PruneReport{}
+	failures := []string{}
+	fail := func(format string, args ...interface{}) {
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+
+	if all || opts.Containers {
+		containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: f})
+		if err != nil {
+			fail("list containers: %s", err)
+		}
+		for _, c := range containers {
+			if !old(time.Unix(c.Created, 0)) {
+				continue
+			}
+			if !opts.DryRun {
+				err := p.client.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+				if err != nil {
+					fail("remove container %s: %s", c.ID, err)
+					continue
+				}
+			}
+			report.Containers = append(report.Containers, c.ID)
+		}
+	}
+
+	if all || opts.Networks {
+		networks, err := p.client.NetworkList(ctx, types.NetworkListOptions{Filters: f})
+		if err != nil {
+			fail("list networks: %s", err)
+		}
+		for _, n := range networks {
+			if !old(n.Created) {
+				continue
+			}
+			if !opts.DryRun {
+				if err := p.client.NetworkRemove(ctx, n.ID); err != nil {
+					fail("remove network %s: %s", n.Name, err)
+					continue
+				}
+			}
+			report.Networks = append(report.Networks, n.Name)
+		}
+	}
+
+	if all || opts.Volumes {
+		volumes, err := p.client.VolumeList(ctx, f)
+		if err != nil {
+			fail("list volumes: %s", err)
+		}
+		for _, v := range volumes.Volumes {
+			// volumes of daemons which do not report their creation are considered old
+			if created, err := time.Parse(time.RFC3339, v.CreatedAt); err == nil && !old(created) {
+				continue
+			}
+			if !opts.DryRun {
+				if err := p.client.VolumeRemove(ctx, v.Name, true); err != nil {
+					fail("remove volume %s: %s", v.Name, err)
+					continue
+				}
+			}
+			report.Volumes = append(report.Volumes, v.Name)
+		}
+	}
+
+	if all || opts.Images {
+		images, err := p.client.ImageList(ctx, types.ImageListOptions{All: true, Filters: f})
+		if err != nil {
+			fail("list images: %s", err)
+		}
+		for _, img := range images {
+			if !old(time.Unix(img.Created, 0)) {
+				continue
+			}
+			if !opts.DryRun {
+				_, err := p.client.ImageRemove(ctx, img.ID, types.ImageRemoveOptions{Force: true, PruneChildren: true})
+				if err != nil {
+					fail("remove image %s: %s", img.ID, err)
+					continue
+				}
+			}
+			report.Images = append(report.Images, img.ID)
+		}
+	}
+
+	if len(failures) > 0 {
+		return report, fmt.Errorf("could not prune all the resources: %s", strings.Join(failures, "; "))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,55 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestPruneContainers(t *testing.T) {
+	var filters string
+	removed := []string{}
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/json"):
+			filters = r.URL.Query().Get("filters")
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode([]types.Container{
+				{ID: "leaked", Created: time.Now().Add(-2 * time.Hour).Unix()},
+				{ID: "running", Created: time.Now().Add(-time.Minute).Unix()},
+			})
+		case r.Method == http.MethodDelete && strings.Contains(r.URL.Path, "/containers/"):
+			removed = append(removed, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	opts := PruneOptions{Containers: true, OlderThan: time.Hour, SessionID: "crashed", DryRun: true}
+	report, err := provider.Prune(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(report.Containers, ",") != "leaked" || len(removed) != 0 {
+		t.Fatalf("expected the old container to be reported but not removed, got %v and removed %v", report.Containers, removed)
+	}
+	for _, label := range []string{TestcontainerLabel + "=true", TestcontainerLabelSessionID + "=crashed"} {
+		if !strings.Contains(filters, label) {
+			t.Fatalf("expected the containers to be filtered by %s, got %s", label, filters)
+		}
+	}
+
+	opts.DryRun = false
+	if _, err := provider.Prune(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(removed, ",") != "leaked" {
+		t.Fatalf("expected the old container to be removed, got %v", removed)
+	}
+}
@@ -4,11 +4,19 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/cenkalti/backoff"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // TestcontainerLabel is used as a base for docker labels
@@ -16,9 +24,67 @@ const (
 	TestcontainerLabel          = "org.testcontainers.golang"
 	TestcontainerLabelSessionID = TestcontainerLabel + ".sessionId"
 	TestcontainerLabelIsReaper  = TestcontainerLabel + ".reaper"
+	TestcontainerLabelHash      = TestcontainerLabel + ".hash"
 	ReaperDefaultImage          = "quay.io/testcontainers/ryuk:0.2.2"
 )
 
+// Timeouts used to talk to the reaper, they can be overridden with the TC_REAPER_CONNECTION_TIMEOUT
+// and TC_REAPER_RECONNECTION_TIMEOUT env variables, using Go duration notation, e.g. "1m30s"
+var (
+	// ReaperConnectionTimeout is how long to keep trying to connect to the reaper once it is started
+	ReaperConnectionTimeout = 30 * time.Second
+	// ReaperReconnectionTimeout is how long to keep trying to reconnect to the reaper when the connection drops.
+	// Ryuk reaps the resources shortly after its last connection is closed, so it must be short.
+	ReaperReconnectionTimeout = 5 * time.Second
+)
+
+// ReaperVerbose enables verbose logging of the reaper, including the logs of the reaper container itself,
+// streamed through the Logger. It can also be enabled by setting the TC_REAPER_VERBOSE env variable to "true"
+var ReaperVerbose = false
+
+func reaperVerbose() bool {
+	if ReaperVerbose {
+		return true
+	}
+	verbose, _ := strconv.ParseBool(os.Getenv("TC_REAPER_VERBOSE"))
+	return verbose
+}
+
+// sessionID identifies the resources created by this process, so that they are reaped together
+var sessionID = uuid.NewV4()
+
+// there is a single reaper per session and daemon, shared by all the resources of the session on the daemon
+var (
+	reapers     = make(map[string]*Reaper)
+	reaperMutex sync.Mutex
+	// reaperStarts serializes the starts of the reaper of each session and daemon, without making the
+	// starts on other daemons wait for the image to be pulled
+	reaperStarts = make(map[string]*sync.Mutex)
+)
+
+// lockReaperStart locks the start of the reaper with the given key, and returns the function unlocking it
+func lockReaperStart(key string) func() {
+	reaperMutex.Lock()
+	m, ok := reaperStarts[key]
+	if !ok {
+		m = &sync.Mutex{}
+		reaperStarts[key] = m
+	}
+	reaperMutex.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// reaperKey identifies the reaper of the session in the daemon of the provider,
+// the reaper can only reap the resources of its own daemon
+func reaperKey(sessionID string, provider ReaperProvider) string {
+	if p, ok := provider.(*DockerProvider); ok && p.dockerHost != "" {
+		return sessionID + "@" + p.dockerHost
+	}
+	return sessionID
+}
+
 // ReaperProvider represents a provider for the reaper to run itself with
 // The ContainerProvider interface should usually satisfy this as well, so it is pluggable
 type ReaperProvider interface {
@@ -30,83 +96,413 @@ type Reaper struct {
 	Provider  ReaperProvider
 	SessionID string
 	Endpoint  string
+
+	key          string
+	container    Container
+	connectMutex sync.Mutex
+	connected    bool
+	conn         net.Conn
+	terminated   bool
+
+	// the labels registered with RegisterLabels, registered again when reconnecting
+	labelsMutex sync.Mutex
+	labels      []map[string]string
 }
 
-// NewReaper creates a Reaper with a sessionID to identify containers and a provider to use
+// NewReaper creates a Reaper with a sessionID to identify containers and a provider to use.
+// The reaper is started once per session and daemon: later calls for the same session return the same
+// reaper, even when they happen concurrently.
 func NewReaper(ctx context.Context, sessionID string, provider ReaperProvider) (*Reaper, error) {
-	r := &Reaper{
+	key := reaperKey(sessionID, provider)
+	unlock := lockReaperStart(key)
+	defer unlock()
+
+	reaperMutex.Lock()
+	r, ok := reapers[key]
+	reaperMutex.Unlock()
+	if ok {
+		return r, nil
+	}
+
+	r = &Reaper{
 		Provider:  provider,
 		SessionID: sessionID,
+		key:       key,
 	}
 
-	// TODO: reuse reaper if there already is one
-
 	req := ContainerRequest{
-		Image:        ReaperDefaultImage,
+		Image:        reaperImage(),
 		ExposedPorts: []string{"8080"},
 		Labels: map[string]string{
 			TestcontainerLabel:         "true",
 			TestcontainerLabelIsReaper: "true",
 		},
 		SkipReaper: true,
-		BindMounts: map[string]string{
-			"/var/run/docker.sock": "/var/run/docker.sock",
-		},
+		Env:        map[string]string{},
+	}
+	configureReaperDockerAccess(ctx, provider, &req)
+
+	verbose := reaperVerbose()
+	if verbose {
+		req.Env["RYUK_VERBOSE"] = "true"
 	}
 
 	c, err := provider.RunContainer(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	// the reaper removes itself once it has reaped the session, removing it earlier would leak the session
+	untrackForCleanup(c.GetContainerID())
 
 	endpoint, err := c.PortEndpoint(ctx, "8080", "")
 	if err != nil {
+		// the reaper is not tracked for cleanup, it would be left running
+		c.Terminate(ctx)
 		return nil, err
 	}
 	r.Endpoint = endpoint
+	r.container = c
+	reaperMutex.Lock()
+	reapers[key] = r
+	reaperMutex.Unlock()
+
+	if verbose {
+		Logger.Printf("Ryuk for session %s started on %s", sessionID, endpoint)
+		go streamReaperLogs(c)
+	}
 
 	return r, nil
 }
 
-// Connect runs a goroutine which can be terminated by sending true into the returned channel
+// reaperImage is the image of the reaper, the default one unless configured with ryuk.container.image
+func reaperImage() string {
+	if image := ReadConfig().RyukImage; image != "" {
+		return image
+	}
+	return ReaperDefaultImage
+}
+
+// reaperCertPath is where the TLS certificates of the Docker daemon are mounted in the reaper container
+const reaperCertPath = "/certs"
+
+// Overrides of the way the reaper container accesses the Docker socket, for setups where the detection
+// of rootless daemons does not work. They can also be set with the TC_REAPER_DOCKER_SOCKET and
+// TC_REAPER_PRIVILEGED env variables.
+var (
+	// ReaperDockerSocket is the path of the Docker socket on the host of the daemon, mounted into the reaper
+	ReaperDockerSocket = ""
+	// ReaperPrivileged runs the reaper container as privileged, needed e.g. with SELinux enforcing
+	ReaperPrivileged = false
+)
+
+// configureReaperDockerAccess gives the reaper access to the Docker daemon. When the daemon is protected
+// with TLS, as configured by DOCKER_TLS_VERIFY and DOCKER_CERT_PATH, the configuration is propagated
+// and the certificates are mounted, otherwise the Docker socket is mounted.
+// The certificates are mounted from the host of the daemon, so DOCKER_CERT_PATH must exist there too,
+// which is the case for Docker in Docker setups sharing the certificates through a volume.
+func configureReaperDockerAccess(ctx context.Context, provider ReaperProvider, req *ContainerRequest) {
+	privileged, _ := strconv.ParseBool(os.Getenv("TC_REAPER_PRIVILEGED"))
+	req.Privileged = ReaperPrivileged || privileged || ReadConfig().RyukPrivileged
+
+	dockerHost := os.Getenv("DOCKER_HOST")
+	certPath := os.Getenv("DOCKER_CERT_PATH")
+	if os.Getenv("DOCKER_TLS_VERIFY") == "" || certPath == "" || dockerHost == "" {
+		// the host may come from the docker context rather than from the env
+		if p, ok := provider.(*DockerProvider); ok && p.dockerHost != "" {
+			dockerHost = p.dockerHost
+		}
+		socket, rootless := reaperDockerSocket(ctx, provider, dockerHost)
+		req.BindMounts = map[string]string{
+			socket: "/var/run/docker.sock",
+		}
+		// rootless Podman labels its socket so that only privileged containers can use it
+		if rootless && strings.Contains(socket, "podman") {
+			req.Privileged = true
+		}
+		return
+	}
+
+	req.Env["DOCKER_HOST"] = dockerHost
+	req.Env["DOCKER_TLS_VERIFY"] = "1"
+	req.Env["DOCKER_CERT_PATH"] = reaperCertPath
+	req.BindMounts = map[string]string{
+		certPath: reaperCertPath,
+	}
+}
+
+// reaperDockerSocket gets the path of the Docker socket to mount into the reaper, and whether the daemon
+// is rootless. Rootless daemons, Docker or Podman, listen on a socket of the user rather than on
+// /var/run/docker.sock, the one the client connects to through DOCKER_HOST is used then.
+func reaperDockerSocket(ctx context.Context, provider ReaperProvider, dockerHost string) (string, bool) {
+	if ReaperDockerSocket != "" {
+		return ReaperDockerSocket, false
+	}
+	if socket, exists := os.LookupEnv("TC_REAPER_DOCKER_SOCKET"); exists {
+		return socket, false
+	}
+
+	const defaultSocket = "/var/run/docker.sock"
+	if !strings.HasPrefix(dockerHost, "unix://") || !isRootless(ctx, provider) {
+		return defaultSocket, false
+	}
+
+	return strings.TrimPrefix(dockerHost, "unix://"), true
+}
+
+// isRootless reports whether the daemon of the provider runs rootless
+func isRootless(ctx context.Context, provider ReaperProvider) bool {
+	p, ok := provider.(*DockerProvider)
+	if !ok {
+		return false
+	}
+
+	info, err := p.client.Info(ctx)
+	if err != nil {
+		return false
+	}
+	for _, opt := range info.SecurityOptions {
+		if strings.Contains(opt, "name=rootless") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Connect registers the labels of the session with the reaper. The connection is established
+// on the first call only and kept open for the rest of the process, so that the resources of the
+// session are reaped once the process ends. If the connection drops, it is re-established and the
+// labels are registered again. The returned channel is kept for compatibility: the connection is
+// shared by the session, so signalling termination on it has no effect.
 func (r *Reaper) Connect() (chan bool, error) {
+	r.connectMutex.Lock()
+	defer r.connectMutex.Unlock()
+
+	if r.terminated {
+		return nil, errors.New("the reaper of session " + r.SessionID + " is terminated")
+	}
+
+	if !r.connected {
+		conn, err := r.connect(reaperConnectBackOff())
+		if err != nil {
+			return nil, err
+		}
+
+		go r.keepAlive(conn)
+		r.conn = conn
+		r.connected = true
+	}
+
+	return make(chan bool, 1), nil
+}
+
+// reaperConnectBackOff is the policy used to connect to the reaper when it starts,
+// waiting longer by default on CI where the reaper takes longer to start
+func reaperConnectBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	def := ReaperConnectionTimeout * time.Duration(RuntimeEnvironment().TimeoutMultiplier)
+	b.MaxElapsedTime = reaperTimeout("TC_REAPER_CONNECTION_TIMEOUT", def)
+	return b
+}
+
+// reaperReconnectBackOff is the policy used to reconnect to the reaper when the connection drops
+func reaperReconnectBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 100 * time.Millisecond
+	b.MaxElapsedTime = reaperTimeout("TC_REAPER_RECONNECTION_TIMEOUT", ReaperReconnectionTimeout)
+	return b
+}
+
+// reaperTimeout reads a timeout from the given env variable, falling back to def if it is not set or invalid
+func reaperTimeout(env string, def time.Duration) time.Duration {
+	value, exists := os.LookupEnv(env)
+	if !exists {
+		return def
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		Logger.Printf("Invalid %s %q, using the default of %s", env, value, def)
+		return def
+	}
+
+	return timeout
+}
+
+// connect dials the reaper and registers the labels of the session, retrying as long as b allows
+func (r *Reaper) connect(b backoff.BackOff) (net.Conn, error) {
+	var conn net.Conn
+	err := backoff.Retry(func() error {
+		var err error
+		conn, err = net.DialTimeout("tcp", r.Endpoint, 10*time.Second)
+		if err != nil {
+			return errors.Wrap(err, "Connecting to Ryuk on "+r.Endpoint+" failed")
+		}
+
+		if err := r.register(conn); err != nil {
+			conn.Close()
+			return err
+		}
+		if reaperVerbose() {
+			Logger.Printf("Registered the labels of session %s with Ryuk on %s", r.SessionID, r.Endpoint)
+		}
+		return nil
+	}, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// keepAlive keeps the connection open until the process ends, reconnecting whenever it drops
+func (r *Reaper) keepAlive(conn net.Conn) {
+	for {
+		// Ryuk never writes after the ACK, reading only returns once the connection is closed
+		io.Copy(ioutil.Discard, conn)
+		conn.Close()
+
+		r.connectMutex.Lock()
+		terminated := r.terminated
+		r.connectMutex.Unlock()
+		if terminated {
+			return
+		}
+
+		Logger.Printf("Connection to Ryuk on %s lost, reconnecting", r.Endpoint)
+		var err error
+		conn, err = r.connect(reaperReconnectBackOff())
+
+		r.connectMutex.Lock()
+		if err != nil {
+			Logger.Printf("Reconnecting to Ryuk on %s failed, resources of session %s will not be reaped: %s", r.Endpoint, r.SessionID, err)
+			r.connected = false
+			r.connectMutex.Unlock()
+			return
+		}
+		r.conn = conn
+		r.connectMutex.Unlock()
+	}
+}
+
+// Terminate closes the connection to the reaper and removes the reaper container, without waiting for
+// it to reap the resources of the session. The reaper of the session cannot be used afterwards,
+// a new one is started by NewReaper.
+func (r *Reaper) Terminate(ctx context.Context) error {
+	reaperMutex.Lock()
+	if reapers[r.key] == r {
+		delete(reapers, r.key)
+	}
+	reaperMutex.Unlock()
+
+	r.connectMutex.Lock()
+	r.terminated = true
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	r.connected = false
+	r.connectMutex.Unlock()
+
+	if r.container == nil {
+		return nil
+	}
+	return r.container.Terminate(ctx)
+}
+
+// streamReaperLogs writes the logs of the reaper container to the Logger until the container stops
+func streamReaperLogs(c Container) {
+	if err := FollowLogs(context.Background(), c, loggerLogConsumer{prefix: "ryuk"}); err != nil {
+		Logger.Printf("Could not stream the logs of Ryuk: %s", err)
+	}
+}
+
+// loggerLogConsumer writes the logs of a container to the Logger, with a prefix
+type loggerLogConsumer struct {
+	prefix string
+}
+
+func (c loggerLogConsumer) Accept(log Log) {
+	Logger.Printf("%s: %s", c.prefix, log.Content)
+}
+
+// register sends the label filters of the session, and the ones registered with RegisterLabels, to the reaper
+// and waits for it to acknowledge them, so that a reaper which lost them on restart reaps the same resources
+func (r *Reaper) register(conn net.Conn) error {
+	if err := r.registerLabels(conn, r.Labels()); err != nil {
+		return err
+	}
+
+	r.labelsMutex.Lock()
+	registered := append([]map[string]string{}, r.labels...)
+	r.labelsMutex.Unlock()
+	for _, labels := range registered {
+		if err := r.registerLabels(conn, labels); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterLabels registers further labels with the reaper, which then reaps the resources labelled with
+// all of them as well as the ones of the session, e.g. resources created by tools which cannot label them
+// with the session. The reaper must be connected, the labels are registered again whenever the connection
+// of the session is re-established.
+func (r *Reaper) RegisterLabels(labels map[string]string) error {
 	conn, err := net.DialTimeout("tcp", r.Endpoint, 10*time.Second)
 	if err != nil {
-		return nil, errors.Wrap(err, "Connecting to Ryuk on "+r.Endpoint+" failed")
+		return errors.Wrap(err, "Connecting to Ryuk on "+r.Endpoint+" failed")
 	}
+	defer conn.Close()
 
-	terminationSignal := make(chan bool)
-	go func(conn net.Conn) {
-		sock := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
-		defer conn.Close()
+	if err := r.registerLabels(conn, labels); err != nil {
+		return err
+	}
+
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	r.labelsMutex.Lock()
+	r.labels = append(r.labels, copied)
+	r.labelsMutex.Unlock()
 
-		labelFilters := []string{}
-		for l, v := range r.Labels() {
-			labelFilters = append(labelFilters, fmt.Sprintf("label=%s=%s", l, v))
+	return nil
+}
+
+// registerLabels sends the filter of the labels to Ryuk, and waits for its acknowledgement
+func (r *Reaper) registerLabels(conn net.Conn, labels map[string]string) error {
+	sock := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	labelFilters := []string{}
+	for l, v := range labels {
+		labelFilters = append(labelFilters, fmt.Sprintf("label=%s=%s", l, v))
+	}
+	sort.Strings(labelFilters)
+
+	var err error
+	retryLimit := 3
+	for retryLimit > 0 {
+		retryLimit--
+
+		sock.WriteString(strings.Join(labelFilters, "&"))
+		sock.WriteString("\n")
+		if err = sock.Flush(); err != nil {
+			continue
 		}
 
-		retryLimit := 3
-		for retryLimit > 0 {
-			retryLimit--
-
-			sock.WriteString(strings.Join(labelFilters, "&"))
-			sock.WriteString("\n")
-			if err := sock.Flush(); err != nil {
-				continue
-			}
-
-			resp, err := sock.ReadString('\n')
-			if err != nil {
-				continue
-			}
-			if resp == "ACK\n" {
-				break
-			}
+		var resp string
+		resp, err = sock.ReadString('\n')
+		if err != nil {
+			continue
+		}
+		if resp == "ACK\n" {
+			return nil
 		}
+		err = fmt.Errorf("unexpected response from Ryuk: %q", resp)
+	}
 
-		<-terminationSignal
-	}(conn)
-	return terminationSignal, nil
+	return errors.Wrap(err, "registering with Ryuk on "+r.Endpoint+" failed")
 }
 
 // Labels returns the container labels to use so that this Reaper cleans them up
@@ -0,0 +1,321 @@
+package testcontainers
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+func TestReaperIsSharedBySession(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewDockerProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const parallelism = 4
+	reapers := make([]*Reaper, parallelism)
+	errs := make([]error, parallelism)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reapers[i], errs[i] = NewReaper(ctx, sessionID.String(), provider)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < parallelism; i++ {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if reapers[i] != reapers[0] {
+			t.Fatal("expected a single reaper for the session")
+		}
+		if _, err := reapers[i].Connect(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReaperIsTerminatedWithoutEndpoint(t *testing.T) {
+	provider := NewProviderMock()
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		c.PortMap = nat.PortMap{}
+		return nil
+	}
+
+	if _, err := NewReaper(context.Background(), "no-endpoint", provider); err == nil {
+		t.Fatal("expected the reaper not to be started without an endpoint")
+	}
+	c := provider.Containers()[0]
+	if calls := c.Calls(); calls[len(calls)-1] != "Terminate" {
+		t.Fatalf("expected the reaper container to be terminated, got calls %v", calls)
+	}
+}
+
+func TestReaperStartsOnDaemonsConcurrently(t *testing.T) {
+	pulling, release := make(chan struct{}), make(chan struct{})
+	blocked := NewProviderMock()
+	blocked.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		close(pulling)
+		<-release
+		return nil
+	}
+	ctx := context.Background()
+	done := make(chan error, 1)
+	go func() {
+		r, err := NewReaper(ctx, "blocked", blocked)
+		if err == nil {
+			r.Terminate(ctx)
+		}
+		done <- err
+	}()
+	defer func() {
+		close(release)
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}()
+	<-pulling
+
+	started := make(chan error, 1)
+	go func() {
+		r, err := NewReaper(ctx, "other", NewProviderMock())
+		if err == nil {
+			r.Terminate(ctx)
+		}
+		started <- err
+	}()
+	select {
+	case err := <-started:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the reaper of another session not to wait for the one being started")
+	}
+}
+
+func TestReaperTimeoutFromEnv(t *testing.T) {
+	const env = "TC_REAPER_TEST_TIMEOUT"
+	defer os.Unsetenv(env)
+
+	if got := reaperTimeout(env, time.Second); got != time.Second {
+		t.Fatalf("expected the default when unset, got %s", got)
+	}
+
+	os.Setenv(env, "1m30s")
+	if got := reaperTimeout(env, time.Second); got != 90*time.Second {
+		t.Fatalf("expected 1m30s, got %s", got)
+	}
+
+	os.Setenv(env, "soon")
+	if got := reaperTimeout(env, time.Second); got != time.Second {
+		t.Fatalf("expected the default when invalid, got %s", got)
+	}
+}
+
+func TestReaperDockerAccessWithTLS(t *testing.T) {
+	for _, env := range []string{"DOCKER_HOST", "DOCKER_TLS_VERIFY", "DOCKER_CERT_PATH"} {
+		if value, exists := os.LookupEnv(env); exists {
+			defer os.Setenv(env, value)
+		} else {
+			defer os.Unsetenv(env)
+		}
+	}
+
+	os.Setenv("DOCKER_HOST", "tcp://docker:2376")
+	os.Setenv("DOCKER_TLS_VERIFY", "1")
+	os.Setenv("DOCKER_CERT_PATH", "/certs/client")
+
+	req := ContainerRequest{Env: map[string]string{}}
+	configureReaperDockerAccess(context.Background(), nil, &req)
+
+	if req.Env["DOCKER_HOST"] != "tcp://docker:2376" || req.Env["DOCKER_CERT_PATH"] != reaperCertPath {
+		t.Fatalf("expected the TLS configuration to be propagated, got %v", req.Env)
+	}
+	if req.BindMounts["/certs/client"] != reaperCertPath {
+		t.Fatalf("expected the certificates to be mounted, got %v", req.BindMounts)
+	}
+	if _, ok := req.BindMounts["/var/run/docker.sock"]; ok {
+		t.Fatal("expected the docker socket not to be mounted")
+	}
+}
+
+func TestReaperRegisterLabels(t *testing.T) {
+	// a fake Ryuk acknowledging the filters it receives
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		filter, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- filter
+		conn.Write([]byte("ACK\n"))
+	}()
+
+	r := &Reaper{SessionID: "session", Endpoint: listener.Addr().String()}
+	if err := r.RegisterLabels(map[string]string{ComposeProjectLabel: "stack", "role": "db"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "label=com.docker.compose.project=stack&label=role=db\n"
+	if filter := <-received; filter != expected {
+		t.Fatalf("expected the filter %q, got %q", expected, filter)
+	}
+}
+
+// fakeRyuk acknowledges the filters it receives on each connection, and sends them to filters.
+// The connections listed in drop are closed once their first filter is acknowledged, counting from 0.
+func fakeRyuk(listener net.Listener, filters chan<- string, drop ...int) {
+	for i := 0; ; i++ {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		dropped := false
+		for _, d := range drop {
+			dropped = dropped || d == i
+		}
+
+		go func() {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for {
+				filter, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				conn.Write([]byte("ACK\n"))
+				filters <- filter
+				if dropped {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func TestReaperReconnectsAndRegistersAgain(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	filters := make(chan string, 2)
+	go fakeRyuk(listener, filters, 0)
+
+	r := &Reaper{SessionID: "session", Endpoint: listener.Addr().String()}
+	if _, err := r.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Terminate(context.Background())
+
+	expected := "label=org.testcontainers.golang.sessionId=session&label=org.testcontainers.golang=true\n"
+	for _, registration := range []string{"first", "second"} {
+		select {
+		case filter := <-filters:
+			if filter != expected {
+				t.Fatalf("expected the %s registration to be %q, got %q", registration, expected, filter)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("the %s registration was not received", registration)
+		}
+	}
+}
+
+func TestReaperRegistersLabelsAgainOnReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	filters := make(chan string, 4)
+	go fakeRyuk(listener, filters)
+
+	r := &Reaper{SessionID: "session", Endpoint: listener.Addr().String()}
+	if _, err := r.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Terminate(context.Background())
+	<-filters
+	if err := r.RegisterLabels(map[string]string{ComposeProjectLabel: "stack"}); err != nil {
+		t.Fatal(err)
+	}
+	<-filters
+
+	// the connection drops, e.g. as the reaper restarted and lost the filters
+	r.connectMutex.Lock()
+	r.conn.Close()
+	r.connectMutex.Unlock()
+
+	expected := []string{
+		"label=org.testcontainers.golang.sessionId=session&label=org.testcontainers.golang=true\n",
+		"label=com.docker.compose.project=stack\n",
+	}
+	for _, e := range expected {
+		select {
+		case filter := <-filters:
+			if filter != e {
+				t.Fatalf("expected %q to be registered again, got %q", e, filter)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%q was not registered again", e)
+		}
+	}
+}
+
+func TestReaperReconnectionFailure(t *testing.T) {
+	const env = "TC_REAPER_RECONNECTION_TIMEOUT"
+	defer os.Unsetenv(env)
+	os.Setenv(env, "300ms")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filters := make(chan string, 1)
+	go fakeRyuk(listener, filters)
+
+	r := &Reaper{SessionID: "session", Endpoint: listener.Addr().String()}
+	if _, err := r.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Terminate(context.Background())
+	<-filters
+
+	// the reaper is gone: the connection drops and nothing listens anymore
+	listener.Close()
+	r.connectMutex.Lock()
+	r.conn.Close()
+	r.connectMutex.Unlock()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		r.connectMutex.Lock()
+		connected := r.connected
+		r.connectMutex.Unlock()
+		if !connected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the reaper to be disconnected once reconnecting failed")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,65 @@
+package testcontainers
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// registered providers, selected by name in the generic requests, so that a test can run resources
+// on several daemons at once, e.g. one per datacenter
+var (
+	providers      = make(map[string]GenericProvider)
+	providersMutex sync.RWMutex
+)
+
+// RegisterProvider registers a provider under a name, to be selected with the ProviderName
+// of the generic requests. Registering a name again replaces the previous provider.
+func RegisterProvider(name string, provider GenericProvider) error {
+	if name == "" {
+		return errors.New("a provider cannot be registered without a name")
+	}
+	if provider == nil {
+		return errors.Errorf("cannot register a nil provider as '%s'", name)
+	}
+
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	providers[name] = provider
+
+	return nil
+}
+
+// RegisterDockerProvider registers a provider for the Docker daemon at the given host,
+// in the DOCKER_HOST notation, e.g. "tcp://dc1.example.com:2376", configured by the options
+func RegisterDockerProvider(name string, host string, opts ...DockerProviderOption) error {
+	provider, err := NewDockerProviderWithHost(host, opts...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create Docker provider '%s'", name)
+	}
+
+	return RegisterProvider(name, provider)
+}
+
+// UnregisterProvider removes the provider registered under the name, if any
+func UnregisterProvider(name string) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	delete(providers, name)
+}
+
+// getProvider gets the provider registered under the name if there is one, otherwise the provider of the type
+func getProvider(t ProviderType, name string) (GenericProvider, error) {
+	if name == "" {
+		return t.GetProvider()
+	}
+
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+	provider, ok := providers[name]
+	if !ok {
+		return nil, errors.Errorf("no provider registered as '%s'", name)
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,85 @@
+package testcontainers
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	dc1, err := NewDockerProviderWithHost("tcp://dc1.example.com:2376")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterProvider("dc1", dc1); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("dc1")
+
+	provider, err := getProvider(ProviderDocker, "dc1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider != dc1 {
+		t.Fatalf("expected the provider registered as dc1")
+	}
+
+	if _, err := getProvider(ProviderDocker, "dc2"); err == nil {
+		t.Fatal("expected an error for an unregistered provider")
+	}
+
+	if err := RegisterProvider("", dc1); err == nil {
+		t.Fatal("expected an error for a provider without a name")
+	}
+}
+
+func TestReaperKeyDependsOnDaemon(t *testing.T) {
+	dc1, err := NewDockerProviderWithHost("tcp://dc1.example.com:2376")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc2, err := NewDockerProviderWithHost("tcp://dc2.example.com:2376")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reaperKey("session", dc1) == reaperKey("session", dc2) {
+		t.Fatal("expected distinct reapers for distinct daemons")
+	}
+}
+
+func TestDaemonHostIsScopedByProvider(t *testing.T) {
+	if value, exists := os.LookupEnv("TC_HOST"); exists {
+		defer os.Setenv("TC_HOST", value)
+		os.Unsetenv("TC_HOST")
+	} else {
+		defer os.Unsetenv("TC_HOST")
+	}
+	dc1, err := NewDockerProviderWithHost("tcp://dc1.example.com:2376", WithExternalHost("10.0.1.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc2, err := NewDockerProviderWithHost("tcp://dc2.example.com:2376")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if host, err := dc1.daemonHost(); err != nil || host != "10.0.1.1" {
+		t.Errorf("expected the host set for the provider, got %s, %v", host, err)
+	}
+	if host, err := dc2.daemonHost(); err != nil || host != "dc2.example.com" {
+		t.Errorf("expected the host of the daemon, got %s, %v", host, err)
+	}
+
+	os.Setenv("TC_HOST", "10.0.2.1")
+	if host, _ := dc2.daemonHost(); host != "dc2.example.com" {
+		t.Errorf("expected the host to be cached, got %s", host)
+	}
+	dc2.InvalidateHostCache()
+	if host, _ := dc2.daemonHost(); host != "10.0.2.1" {
+		t.Errorf("expected the host to be looked up again, got %s", host)
+	}
+	dc1.InvalidateHostCache()
+	if host, _ := dc1.daemonHost(); host != "10.0.1.1" {
+		t.Errorf("expected the host set for the provider to be kept, got %s", host)
+	}
+}
@@ -0,0 +1,80 @@
+package testcontainers
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// RetryPolicy is how the operations on the daemon which may fail transiently are retried: pulling
+// the image, creating and starting the container and attaching it to its networks. The interval
+// between the attempts grows exponentially.
+type RetryPolicy struct {
+	MaxAttempts     int              // how many times the operation is attempted, unlimited if 0
+	MaxElapsedTime  time.Duration    // how long to keep retrying the operation, unlimited if 0
+	InitialInterval time.Duration    // the interval before the first retry, 500ms if 0
+	Retryable       func(error) bool // whether an error may be transient, DefaultRetryable if nil
+}
+
+// DefaultRetryPolicy is the retry policy of the requests without their own
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	MaxElapsedTime: 2 * time.Minute,
+}
+
+// DefaultRetryable retries the errors of the daemon, except those which cannot go away by themselves:
+// missing resources, invalid or conflicting requests, denied access and the end of the context
+func DefaultRetryable(err error) bool {
+	switch {
+	case err == context.Canceled, err == context.DeadlineExceeded:
+		return false
+	case client.IsErrNotFound(err), errdefs.IsInvalidParameter(err), errdefs.IsConflict(err),
+		errdefs.IsUnauthorized(err), errdefs.IsForbidden(err), errdefs.IsNotImplemented(err):
+		return false
+	}
+
+	return true
+}
+
+// retryPolicy gets the given policy, or the default one if it is nil
+func retryPolicy(policy *RetryPolicy) RetryPolicy {
+	if policy == nil {
+		return DefaultRetryPolicy
+	}
+
+	return *policy
+}
+
+// retry runs the operation until it succeeds, fails with an error which is not retryable,
+// or the policy gives up, in which case the last error is returned
+func (p RetryPolicy) retry(ctx context.Context, operation func() error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+
+	exponential := backoff.NewExponentialBackOff()
+	if p.InitialInterval > 0 {
+		exponential.InitialInterval = p.InitialInterval
+	}
+	exponential.MaxElapsedTime = p.MaxElapsedTime
+	var b backoff.BackOff = exponential
+	switch {
+	case p.MaxAttempts == 1:
+		// no retries at all, which WithMaxRetries takes as unlimited retries
+		return operation()
+	case p.MaxAttempts > 1:
+		b = backoff.WithMaxRetries(b, uint64(p.MaxAttempts-1))
+	}
+
+	return backoff.Retry(func() error {
+		err := operation()
+		if err != nil && !retryable(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.WithContext(b, ctx))
+}
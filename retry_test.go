@@ -0,0 +1,79 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/errdefs"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	transient := errors.New("connection reset by peer")
+	policy := RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}
+
+	attempts := 0
+	err := policy.retry(context.Background(), func() error {
+		attempts++
+		return transient
+	})
+	if err != transient || attempts != 3 {
+		t.Fatalf("expected 3 attempts failing with the last error, got %d attempts and %v", attempts, err)
+	}
+
+	attempts = 0
+	err = policy.retry(context.Background(), func() error {
+		attempts++
+		return errdefs.NotFound(errors.New("no such image"))
+	})
+	if err == nil || attempts != 1 {
+		t.Fatalf("expected a missing resource not to be retried, got %d attempts and %v", attempts, err)
+	}
+
+	attempts = 0
+	once := RetryPolicy{MaxAttempts: 1}
+	once.retry(context.Background(), func() error {
+		attempts++
+		return transient
+	})
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+
+	attempts = 0
+	policy.Retryable = func(err error) bool { return false }
+	policy.retry(context.Background(), func() error {
+		attempts++
+		return transient
+	})
+	if attempts != 1 {
+		t.Fatalf("expected the classifier of the policy to be used, got %d attempts", attempts)
+	}
+}
+
+func TestStartIsRetried(t *testing.T) {
+	attempts := 0
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/containers/flaky/start") {
+			http.NotFound(w, r)
+			return
+		}
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	c := &DockerContainer{ID: "flaky", provider: provider, retryPolicy: &RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
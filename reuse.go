@@ -0,0 +1,64 @@
+package testcontainers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Hash computes a hash of the definition of the container of the request, labelled on reused containers
+// so that a container is only reused by a request which defines the same container. How the container
+// is waited for, retried, reaped, removed and pulled does not change its definition, so it is not part of the hash.
+func (c *ContainerRequest) Hash() string {
+	def := *c
+	def.WaitingFor = nil
+	def.SkipReaper = false
+	def.DontRemove = false
+	def.SessionID = ""
+	def.RegistryCred = ""
+	def.RetryPolicy = nil
+	def.HostPortRetries = 0
+	def.ExpandEnv = false
+	def.LogArchiveDir = ""
+	def.LogConsumers = nil
+	def.PreTerminateHooks = nil
+	def.Labels = withLabel(c.Labels, TestcontainerLabelHash, "")
+	delete(def.Labels, TestcontainerLabelHash)
+
+	// an empty map defines the same container as a nil one
+	if len(def.Labels) == 0 {
+		def.Labels = nil
+	}
+	if len(def.Env) == 0 {
+		def.Env = nil
+	}
+	if len(def.BindMounts) == 0 {
+		def.BindMounts = nil
+	}
+	if len(def.VolumeMounts) == 0 {
+		def.VolumeMounts = nil
+	}
+	if len(def.NetworkIPs) == 0 {
+		def.NetworkIPs = nil
+	}
+	if len(def.NetworkAliases) == 0 {
+		def.NetworkAliases = nil
+	}
+
+	// maps are encoded with sorted keys, so that the encoding is stable
+	b, _ := json.Marshal(def)
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// withLabel returns a copy of the labels with the given label set
+func withLabel(labels map[string]string, key, value string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[key] = value
+
+	return result
+}
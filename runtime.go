@@ -0,0 +1,96 @@
+package testcontainers
+
+import (
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Names of the CI services detected by RuntimeEnvironment
+const (
+	CIGitHubActions      = "github-actions"
+	CIGitLab             = "gitlab-ci"
+	CIBitbucketPipelines = "bitbucket-pipelines"
+	CIJenkins            = "jenkins"
+	CICircleCI           = "circleci"
+	CIGeneric            = "ci" // any CI setting the conventional CI env variable
+)
+
+// RuntimeEnv describes the environment the tests run in, and the defaults adapted to it
+type RuntimeEnv struct {
+	// CI is the name of the CI service running the tests, empty when not running on CI
+	CI string
+	// InContainer is true when the tests run inside a container, e.g. a CI job container or Docker-in-Docker.
+	// The ports of sibling containers are then reached through the gateway of the container rather than localhost.
+	InContainer bool
+	// ReaperDisabled is true where the reaper cannot run, because mounting the Docker socket is forbidden,
+	// or when disabled with ryuk.disabled in the configuration or with the TC_REAPER_DISABLED env variable.
+	// Resources are still labelled with the session, so that TerminateSession cleans them up.
+	ReaperDisabled bool
+	// TimeoutMultiplier scales the default timeouts of the reaper and of the wait strategies,
+	// as shared CI runners are slower than workstations
+	TimeoutMultiplier int
+}
+
+var (
+	runtimeEnv     RuntimeEnv
+	runtimeEnvOnce sync.Once
+)
+
+func init() {
+	wait.StartupTimeoutMultiplier = func() int {
+		return RuntimeEnvironment().TimeoutMultiplier
+	}
+}
+
+// RuntimeEnvironment detects the environment the tests run in, once per process
+func RuntimeEnvironment() RuntimeEnv {
+	runtimeEnvOnce.Do(func() {
+		runtimeEnv = detectRuntimeEnvironment()
+	})
+
+	return runtimeEnv
+}
+
+func detectRuntimeEnvironment() RuntimeEnv {
+	env := RuntimeEnv{
+		CI:                detectCI(),
+		InContainer:       inAContainer(),
+		TimeoutMultiplier: 1,
+	}
+
+	if env.CI != "" {
+		env.TimeoutMultiplier = 2
+	}
+
+	// Bitbucket Pipelines forbid mounting the Docker socket into containers
+	env.ReaperDisabled = env.CI == CIBitbucketPipelines || ReadConfig().RyukDisabled
+	if disabled, err := strconv.ParseBool(os.Getenv("TC_REAPER_DISABLED")); err == nil {
+		env.ReaperDisabled = disabled
+	}
+
+	return env
+}
+
+func detectCI() string {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return CIGitHubActions
+	case os.Getenv("GITLAB_CI") != "":
+		return CIGitLab
+	case os.Getenv("BITBUCKET_BUILD_NUMBER") != "":
+		return CIBitbucketPipelines
+	case os.Getenv("JENKINS_URL") != "":
+		return CIJenkins
+	case os.Getenv("CIRCLECI") == "true":
+		return CICircleCI
+	}
+
+	if ci, err := strconv.ParseBool(os.Getenv("CI")); err == nil && ci {
+		return CIGeneric
+	}
+
+	return ""
+}
@@ -0,0 +1,62 @@
+package testcontainers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestDetectRuntimeEnvironment(t *testing.T) {
+	ciEnv := []string{"GITHUB_ACTIONS", "GITLAB_CI", "BITBUCKET_BUILD_NUMBER", "JENKINS_URL", "CIRCLECI", "CI", "TC_REAPER_DISABLED"}
+	for _, name := range ciEnv {
+		if value, exists := os.LookupEnv(name); exists {
+			defer os.Setenv(name, value)
+		} else {
+			defer os.Unsetenv(name)
+		}
+		os.Unsetenv(name)
+	}
+
+	tests := []struct {
+		name           string
+		env            map[string]string
+		ci             string
+		reaperDisabled bool
+		multiplier     int
+	}{
+		{"workstation", map[string]string{}, "", false, 1},
+		{"github actions", map[string]string{"GITHUB_ACTIONS": "true", "CI": "true"}, CIGitHubActions, false, 2},
+		{"gitlab", map[string]string{"GITLAB_CI": "true"}, CIGitLab, false, 2},
+		{"bitbucket", map[string]string{"BITBUCKET_BUILD_NUMBER": "42"}, CIBitbucketPipelines, true, 2},
+		{"bitbucket with the reaper enabled", map[string]string{"BITBUCKET_BUILD_NUMBER": "42", "TC_REAPER_DISABLED": "false"}, CIBitbucketPipelines, false, 2},
+		{"generic ci", map[string]string{"CI": "1"}, CIGeneric, false, 2},
+		{"reaper disabled", map[string]string{"TC_REAPER_DISABLED": "true"}, "", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			env := detectRuntimeEnvironment()
+			if env.CI != tt.ci {
+				t.Errorf("expected CI '%s', got '%s'", tt.ci, env.CI)
+			}
+			if env.ReaperDisabled != tt.reaperDisabled {
+				t.Errorf("expected reaper disabled to be %t", tt.reaperDisabled)
+			}
+			if env.TimeoutMultiplier != tt.multiplier {
+				t.Errorf("expected a timeout multiplier of %d, got %d", tt.multiplier, env.TimeoutMultiplier)
+			}
+		})
+	}
+}
+
+func TestWaitStrategiesUseTheTimeoutMultiplier(t *testing.T) {
+	if got, expected := wait.StartupTimeoutMultiplier(), RuntimeEnvironment().TimeoutMultiplier; got != expected {
+		t.Fatalf("expected the wait strategies to use a timeout multiplier of %d, got %d", expected, got)
+	}
+}
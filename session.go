@@ -0,0 +1,137 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+// SessionID gets the ID of the session of the process. All the containers, networks, volumes and images
+// created by the process are labelled with it, unless their request sets another session.
+func SessionID() string {
+	return sessionID.String()
+}
+
+// ListSessionContainers lists the containers of the session of the process, including the stopped ones
+func ListSessionContainers(ctx context.Context) ([]Container, error) {
+	provider, err := NewDockerProvider()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Docker provider")
+	}
+
+	return provider.ListSessionContainers(ctx, sessionID.String())
+}
+
+// ListSessionContainers lists the containers labelled with the given session, including the stopped ones
+func (p *DockerProvider) ListSessionContainers(ctx context.Context, session string) ([]Container, error) {
+	f := filters.NewArgs(filters.Arg("label", TestcontainerLabelSessionID+"="+session))
+	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("could not list containers of session '%s': %s", session, err)
+	}
+
+	result := make([]Container, 0, len(containers))
+	for _, c := range containers {
+		result = append(result, &DockerContainer{
+			ID:        c.ID,
+			sessionID: session,
+			provider:  p,
+		})
+	}
+
+	return result, nil
+}
+
+// TerminateSession removes all the containers, networks, volumes and images labelled with the session
+// of the process, then shuts down its reaper. It is meant to be called at the end of TestMain,
+// to clean up immediately rather than relying on the reaper, which only reaps once the process ends.
+func TerminateSession(ctx context.Context) error {
+	provider, err := NewDockerProvider()
+	if err != nil {
+		return errors.Wrap(err, "failed to create Docker provider")
+	}
+
+	return provider.TerminateSession(ctx, sessionID.String())
+}
+
+// TerminateSession removes all the resources labelled with the given session, then shuts down its reaper.
+// All the resources are attempted, the returned error reports the ones that could not be removed.
+func (p *DockerProvider) TerminateSession(ctx context.Context, session string) error {
+	if session == sessionID.String() && startupSummaryEnabled() {
+		Logger.Printf("Startup of the containers of the session:\n%s", StartupSummary())
+	}
+
+	f := filters.NewArgs(filters.Arg("label", TestcontainerLabelSessionID+"="+session))
+	failures := []string{}
+	fail := func(format string, args ...interface{}) {
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+
+	// containers first, networks and volumes cannot be removed while they are in use
+	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: f})
+	if err != nil {
+		fail("list containers: %s", err)
+	}
+	for _, c := range containers {
+		err := p.client.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		if err != nil {
+			fail("remove container %s: %s", c.ID, err)
+		}
+	}
+
+	networks, err := p.client.NetworkList(ctx, types.NetworkListOptions{Filters: f})
+	if err != nil {
+		fail("list networks: %s", err)
+	}
+	for _, n := range networks {
+		if err := p.client.NetworkRemove(ctx, n.ID); err != nil {
+			fail("remove network %s: %s", n.Name, err)
+		}
+	}
+
+	volumes, err := p.client.VolumeList(ctx, f)
+	if err != nil {
+		fail("list volumes: %s", err)
+	}
+	for _, v := range volumes.Volumes {
+		if err := p.client.VolumeRemove(ctx, v.Name, true); err != nil {
+			fail("remove volume %s: %s", v.Name, err)
+		}
+	}
+
+	images, err := p.client.ImageList(ctx, types.ImageListOptions{All: true, Filters: f})
+	if err != nil {
+		fail("list images: %s", err)
+	}
+	for _, img := range images {
+		_, err := p.client.ImageRemove(ctx, img.ID, types.ImageRemoveOptions{Force: true, PruneChildren: true})
+		if err != nil {
+			fail("remove image %s: %s", img.ID, err)
+		}
+	}
+
+	reaperMutex.Lock()
+	r, ok := reapers[reaperKey(session, p)]
+	reaperMutex.Unlock()
+	if ok {
+		if err := r.Terminate(ctx); err != nil {
+			fail("terminate reaper: %s", err)
+		}
+	}
+
+	if session == sessionID.String() {
+		sessionNetworkMutex.Lock()
+		delete(sessionNetworkNames, p.dockerHost)
+		sessionNetworkMutex.Unlock()
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("could not terminate session '%s': %s", session, strings.Join(failures, "; "))
+	}
+
+	return nil
+}
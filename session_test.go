@@ -0,0 +1,87 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTerminateSessionRemovesSessionResources(t *testing.T) {
+	ctx := context.Background()
+	networkName := fmt.Sprintf("%s_%d", "test_session_network", time.Now().Unix())
+	_, err := GenericNetwork(ctx, GenericNetworkRequest{
+		NetworkRequest: NetworkRequest{Name: networkName},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	containerName := fmt.Sprintf("%s_%d", "test_session_container", time.Now().Unix())
+	_, err = GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:    "nginx",
+			Name:     containerName,
+			Networks: []string{networkName},
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TerminateSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewDockerProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exists, err := provider.ContainerExists(ctx, containerName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatalf("expected container '%s' to be removed", containerName)
+	}
+}
+
+func TestListSessionContainers(t *testing.T) {
+	ctx := context.Background()
+	nginxC, err := Run(ctx, "nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nginxC.Terminate(ctx)
+
+	otherSession := "other-session"
+	otherC, err := Run(ctx, "nginx", WithSessionID(otherSession))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherC.Terminate(ctx)
+	if otherC.SessionID() != otherSession {
+		t.Fatalf("expected session '%s', got '%s'", otherSession, otherC.SessionID())
+	}
+
+	containers, err := ListSessionContainers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, c := range containers {
+		if c.GetContainerID() == otherC.GetContainerID() {
+			t.Fatal("expected the container of the other session not to be listed")
+		}
+		if c.GetContainerID() == nginxC.GetContainerID() {
+			found = true
+		}
+		if c.SessionID() != SessionID() {
+			t.Fatalf("unexpected session '%s'", c.SessionID())
+		}
+	}
+	if !found {
+		t.Fatal("expected the container of the session to be listed")
+	}
+}
@@ -0,0 +1,56 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockPollInterval is how often a busy lock file is tried again
+const lockPollInterval = 100 * time.Millisecond
+
+// SharedContainer gets the container of the request shared by the test processes of the machine, e.g. of
+// the packages run in parallel by go test: the first process to get it creates and starts it, the others
+// reuse it. The processes are coordinated with a lock file in the temporary directory, named after the
+// Name of the request, or its Hash if it has no name. The container is reused, see GenericContainerRequest.Reuse:
+// it outlives the processes and it is up to you to remove it.
+func SharedContainer(ctx context.Context, req GenericContainerRequest) (Container, error) {
+	key := req.Name
+	if key == "" {
+		key = req.Hash()
+	}
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("testcontainers-%s.lock", key))
+
+	unlock, err := lockFile(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("could not lock shared container '%s': %s", key, err)
+	}
+	defer unlock()
+
+	req.Reuse = true
+	req.Started = true
+
+	return GenericContainer(ctx, req)
+}
+
+// lockFile waits until it holds the lock of the file at path, or until the context is done.
+// The returned function releases the lock.
+func lockFile(ctx context.Context, path string) (func(), error) {
+	for {
+		unlock, err := tryLockFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if unlock != nil {
+			return unlock, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
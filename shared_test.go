@@ -0,0 +1,75 @@
+package testcontainers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "shared.lock")
+
+	unlock, err := lockFile(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := lockFile(ctx, path); err != context.DeadlineExceeded {
+		t.Fatalf("expected the lock to be held, got %v", err)
+	}
+
+	unlock()
+	unlock, err = lockFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("expected the released lock to be taken, got %v", err)
+	}
+	unlock()
+}
+
+func TestSharedContainer(t *testing.T) {
+	provider := NewProviderMock()
+	if err := RegisterProvider("mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("mock")
+
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{Image: "postgres", Name: "shared-postgres-lock"},
+		ProviderName:     "mock",
+	}
+
+	const processes = 4
+	containers := make(chan Container, processes)
+	for i := 0; i < processes; i++ {
+		go func() {
+			c, err := SharedContainer(context.Background(), req)
+			if err != nil {
+				t.Error(err)
+			}
+			containers <- c
+		}()
+	}
+
+	first := <-containers
+	for i := 1; i < processes; i++ {
+		if c := <-containers; c == nil || first == nil || c.GetContainerID() != first.GetContainerID() {
+			t.Fatal("expected a single shared container")
+		}
+	}
+	if running, _ := first.IsRunning(context.Background()); !running {
+		t.Fatal("expected the shared container to be started")
+	}
+	if n := len(provider.Containers()); n != 1 {
+		t.Fatalf("expected a single container, got %d", n)
+	}
+}
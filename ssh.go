@@ -0,0 +1,126 @@
+package testcontainers
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sshDialer returns a dialer connecting to the Docker daemon of the remote host of an ssh:// DOCKER_HOST.
+// Like the docker CLI, it relies on the ssh command, so the keys, agent and config of the user apply,
+// and on `docker system dial-stdio` on the remote host, which requires docker 18.09 there.
+func sshDialer(dockerHost string) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	args, err := sshArgs(dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return newCommandConn("ssh", args...)
+	}, nil
+}
+
+// sshArgs returns the arguments of the ssh command dialing the Docker daemon of the given ssh:// host
+func sshArgs(dockerHost string) ([]string, error) {
+	args, err := sshDestinationArgs(dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(args, "docker", "system", "dial-stdio"), nil
+}
+
+// sshDestinationArgs returns the arguments of the ssh command connecting to the given ssh:// host,
+// the command to run there, if any, to be appended
+func sshDestinationArgs(dockerHost string) ([]string, error) {
+	u, err := url.Parse(dockerHost)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ssh" {
+		return nil, errors.New("not an ssh host: " + dockerHost)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("no host in " + dockerHost)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, errors.New("paths are not supported in ssh hosts: " + dockerHost)
+	}
+
+	args := []string{}
+	if u.User != nil {
+		args = append(args, "-l", u.User.Username())
+	}
+	if u.Port() != "" {
+		args = append(args, "-p", u.Port())
+	}
+
+	return append(args, "--", u.Hostname()), nil
+}
+
+// commandConn is a net.Conn over the stdin and stdout of a command
+type commandConn struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	stdout    io.ReadCloser
+	closeOnce sync.Once
+}
+
+func newCommandConn(name string, args ...string) (net.Conn, error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "could not start %s", name)
+	}
+
+	return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+}
+
+func (c *commandConn) Read(p []byte) (int, error) {
+	return c.stdout.Read(p)
+}
+
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// Close stops the command, the connection cannot be half-closed
+func (c *commandConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.stdin.Close()
+		c.cmd.Process.Kill()
+		c.cmd.Wait()
+	})
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr {
+	return dummyAddr{}
+}
+
+func (c *commandConn) RemoteAddr() net.Addr {
+	return dummyAddr{}
+}
+
+// deadlines are not supported by pipes, the http transport copes with that
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type dummyAddr struct{}
+
+func (dummyAddr) Network() string { return "dummy" }
+func (dummyAddr) String() string  { return "dummy" }
@@ -0,0 +1,48 @@
+package testcontainers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSSHArgs(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			host: "ssh://docker-host",
+			want: []string{"--", "docker-host", "docker", "system", "dial-stdio"},
+		},
+		{
+			host: "ssh://ci@docker-host:2222",
+			want: []string{"-l", "ci", "-p", "2222", "--", "docker-host", "docker", "system", "dial-stdio"},
+		},
+		{
+			host:    "ssh://docker-host/var/run/docker.sock",
+			wantErr: true,
+		},
+		{
+			host:    "tcp://docker-host:2375",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := sshArgs(tt.host)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", tt.host, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.host, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.host, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// StartupLogLines is how many of the last lines of the logs of a container are reported when it fails to start
+var StartupLogLines = 50
+
+// StartError is the failure of a container to start or to get ready, reported with the state of the
+// container and the last lines of its logs, which usually tell why it failed
+type StartError struct {
+	ContainerID string
+	State       *types.ContainerState // nil if the container could not be inspected
+	Logs        string                // the last StartupLogLines lines of the logs
+	LogFile     string                // the file where the full logs are archived, see LogArchiveDir
+	Err         error
+}
+
+func (e *StartError) Error() string {
+	msg := fmt.Sprintf("container %s failed to start: %s", e.ContainerID, e.Err)
+	if e.State != nil {
+		msg += fmt.Sprintf("\nstate: %s, exit code %d", e.State.Status, e.State.ExitCode)
+		if e.State.Error != "" {
+			msg += ", error: " + e.State.Error
+		}
+	}
+	if e.Logs != "" {
+		msg += fmt.Sprintf("\nlast lines of the logs:\n%s", strings.TrimRight(e.Logs, "\n"))
+	}
+	if e.LogFile != "" {
+		msg += "\nfull logs in " + e.LogFile
+	}
+
+	return msg
+}
+
+// Cause gets the error which made the container fail to start
+func (e *StartError) Cause() error {
+	return e.Err
+}
+
+// Unwrap gets the error which made the container fail to start
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// startError reports the failure of the container to start with its state and the last lines of its logs.
+// They are gathered with a context of their own, as the failure may be the deadline of the context of Start.
+func (c *DockerContainer) startError(err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	startErr := &StartError{ContainerID: c.ID, Err: err}
+	if inspect, inspectErr := c.provider.client.ContainerInspect(ctx, c.ID); inspectErr == nil {
+		startErr.State = inspect.State
+	}
+
+	logs, logsErr := c.provider.client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       strconv.Itoa(StartupLogLines),
+	})
+	if logsErr == nil {
+		defer logs.Close()
+		if raw, readErr := ioutil.ReadAll(logs); readErr == nil {
+			startErr.Logs = demuxLogs(raw)
+		}
+	}
+
+	if dir := logArchiveDir(c); dir != "" {
+		if path, err := archiveLogs(ctx, c, dir, c.ID); err == nil {
+			startErr.LogFile = path
+		} else {
+			Logger.Printf("Could not archive the logs of container %s: %s", c.ID, err)
+		}
+	}
+
+	return startErr
+}
@@ -0,0 +1,56 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+func TestStartErrorReportsStateAndLogs(t *testing.T) {
+	var tail string
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/broken/start"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"message": "OCI runtime create failed"})
+		case strings.HasSuffix(r.URL.Path, "/containers/broken/json"):
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{
+					ID:    "broken",
+					State: &types.ContainerState{Status: "exited", ExitCode: 127},
+				},
+			})
+		case strings.HasSuffix(r.URL.Path, "/containers/broken/logs"):
+			tail = r.URL.Query().Get("tail")
+			stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte("exec: \"postgres\": executable file not found\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	c := &DockerContainer{ID: "broken", provider: provider, retryPolicy: &RetryPolicy{MaxAttempts: 1}}
+	err := c.Start(context.Background())
+
+	startErr, ok := err.(*StartError)
+	if !ok {
+		t.Fatalf("expected a StartError, got %v", err)
+	}
+	if startErr.State == nil || startErr.State.ExitCode != 127 {
+		t.Fatalf("expected the state of the container, got %+v", startErr.State)
+	}
+	if tail != "50" {
+		t.Fatalf("expected the last 50 lines of the logs to be requested, got %q", tail)
+	}
+	for _, expected := range []string{"OCI runtime create failed", "exit code 127", "executable file not found"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected the error to contain %q, got %q", expected, err)
+		}
+	}
+}
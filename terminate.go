@@ -0,0 +1,102 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// TerminateAllError aggregates the failures of TerminateAll, keyed by container ID
+type TerminateAllError struct {
+	Errors map[string]error
+}
+
+func (e TerminateAllError) Error() string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", id, e.Errors[id]))
+	}
+
+	return fmt.Sprintf("could not terminate %d of the containers: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// TerminateAll terminates the containers concurrently, with a bounded number of workers, rather than one after
+// the other as deferred Terminates do. All the containers are attempted, nil ones being skipped, e.g. those of
+// the failed requests of ParallelContainers. The error is a TerminateAllError reporting the ones which failed.
+func TerminateAll(ctx context.Context, containers ...Container) error {
+	var mutex sync.Mutex
+	failures := TerminateAllError{Errors: map[string]error{}}
+
+	runConcurrently(len(containers), defaultParallelWorkers, func(i int) {
+		c := containers[i]
+		if c == nil {
+			return
+		}
+		if err := c.Terminate(ctx); err != nil {
+			mutex.Lock()
+			failures.Errors[c.GetContainerID()] = err
+			mutex.Unlock()
+		}
+	})
+
+	if len(failures.Errors) > 0 {
+		return failures
+	}
+
+	return nil
+}
+
+// TerminateByLabels terminates concurrently all the containers labelled with all the given labels,
+// including the stopped ones, e.g. all the containers of a compose project
+func (p *DockerProvider) TerminateByLabels(ctx context.Context, labels map[string]string) error {
+	f := filters.NewArgs()
+	for k, v := range labels {
+		f.Add("label", k+"="+v)
+	}
+	list, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: f})
+	if err != nil {
+		return fmt.Errorf("could not list the containers to terminate: %s", err)
+	}
+
+	containers := make([]Container, 0, len(list))
+	for _, c := range list {
+		containers = append(containers, &DockerContainer{ID: c.ID, provider: p})
+	}
+
+	return TerminateAll(ctx, containers...)
+}
+
+// runConcurrently calls do with the indexes from 0 to n, with at most the given number of concurrent calls
+func runConcurrently(n, workers int, do func(i int)) {
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				do(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,41 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTerminateAll(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProviderMock()
+	containers := []Container{}
+	for _, image := range []string{"nginx", "redis", "postgres"} {
+		c, err := provider.RunContainer(ctx, ContainerRequest{Image: image})
+		if err != nil {
+			t.Fatal(err)
+		}
+		containers = append(containers, c)
+	}
+	stuck := containers[1].(*ContainerMock)
+	stuck.TerminateErr = errors.New("device or resource busy")
+
+	err := TerminateAll(ctx, append(containers, nil)...)
+	var failures TerminateAllError
+	if !errors.As(err, &failures) {
+		t.Fatalf("expected a TerminateAllError, got %v", err)
+	}
+	if len(failures.Errors) != 1 || !strings.Contains(failures.Errors[stuck.ID].Error(), "busy") {
+		t.Fatalf("expected the failure of the stuck container, got %v", failures.Errors)
+	}
+	for _, c := range []Container{containers[0], containers[2]} {
+		if calls := strings.Join(c.(*ContainerMock).Calls(), ","); calls != "Start,Terminate" {
+			t.Fatalf("expected the container to be terminated, got calls %s", calls)
+		}
+	}
+
+	if err := TerminateAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,104 @@
+package testcontainers
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// RunForTest creates and starts a container for the test, whatever the Started field of the request.
+// The container is terminated once the test and its subtests complete, and its logs are logged
+// if the test failed. The test fails immediately if the container cannot be started.
+func RunForTest(t testing.TB, req GenericContainerRequest) Container {
+	t.Helper()
+
+	req.Started = true
+	c, err := GenericContainer(context.Background(), req)
+	if c != nil {
+		CleanupContainer(t, c)
+	}
+	if err != nil {
+		t.Fatalf("could not run container of image '%s': %s", req.Image, err)
+	}
+
+	return c
+}
+
+// CleanupContainer terminates the container once the test and its subtests complete,
+// logging the logs of the container first if the test failed, and archiving them if LogArchiveDir is set
+func CleanupContainer(t testing.TB, c Container) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		if t.Failed() {
+			t.Logf("logs of container %s:\n%s", c.GetContainerID(), containerOutput(ctx, c))
+			if dir := logArchiveDir(c); dir != "" {
+				path, err := archiveLogs(ctx, c, filepath.Join(dir, archiveFileName(t.Name())), c.GetContainerID())
+				if err != nil {
+					t.Logf("could not archive the logs of container %s: %s", c.GetContainerID(), err)
+				} else {
+					t.Logf("logs of container %s archived to %s", c.GetContainerID(), path)
+				}
+			}
+		}
+		if err := c.Terminate(ctx); err != nil {
+			t.Errorf("could not terminate container %s: %s", c.GetContainerID(), err)
+		}
+	})
+}
+
+// containerOutput gets the logs of the container, demultiplexed if the provider multiplexes stdout and stderr
+func containerOutput(ctx context.Context, c Container) string {
+	logs, err := c.Logs(ctx)
+	if err != nil {
+		return "could not get logs: " + err.Error()
+	}
+	defer logs.Close()
+
+	raw, err := ioutil.ReadAll(logs)
+	if err != nil {
+		return "could not read logs: " + err.Error()
+	}
+
+	return demuxLogs(raw)
+}
+
+// demuxLogs demultiplexes the stdout and stderr of the logs of a container, if they are multiplexed
+func demuxLogs(raw []byte) string {
+	// logs which are not multiplexed, e.g. of a TTY, are either rejected or dropped by StdCopy
+	output := &bytes.Buffer{}
+	if _, err := stdcopy.StdCopy(output, output, bytes.NewReader(raw)); err != nil || output.Len() == 0 {
+		return string(raw)
+	}
+
+	return output.String()
+}
+
+// ReportStartupMetrics reports, along with the ns/op of the benchmark, how long each step of the startup of the
+// containers started by the benchmark took per op, e.g. "pull-ns/op" and "wait-ns/op", so that the speed of
+// the setup of an environment can be tracked with the usual benchmark tooling
+func ReportStartupMetrics(b *testing.B) {
+	var mutex sync.Mutex
+	durations := map[StartupPhase]time.Duration{}
+	remove := OnStartupPhase(func(timing PhaseTiming) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		durations[timing.Phase] += timing.Duration
+	})
+
+	b.Cleanup(func() {
+		remove()
+		mutex.Lock()
+		defer mutex.Unlock()
+		for phase, d := range durations {
+			b.ReportMetric(float64(d.Nanoseconds())/float64(b.N), string(phase)+"-ns/op")
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeT records what RunForTest does with the test, testing.TB cannot be implemented otherwise
+type fakeT struct {
+	testing.TB
+	failed   bool
+	fatal    bool
+	logs     []string
+	cleanups []func()
+}
+
+func (t *fakeT) Name() string           { return "TestFake/subtest" }
+func (t *fakeT) Helper()                {}
+func (t *fakeT) Failed() bool           { return t.failed }
+func (t *fakeT) Cleanup(cleanup func()) { t.cleanups = append(t.cleanups, cleanup) }
+
+func (t *fakeT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.failed = true
+	t.Logf(format, args...)
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.fatal = true
+	t.Errorf(format, args...)
+}
+
+func (t *fakeT) cleanup() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestRunForTest(t *testing.T) {
+	provider := NewProviderMock()
+	provider.OnCreate = func(req ContainerRequest, c *ContainerMock) error {
+		c.LogOutput = "listening\n"
+		if req.Image == "broken" {
+			c.StartErr = errors.New("exec format error")
+		}
+		return nil
+	}
+	if err := RegisterProvider("mock", provider); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProvider("mock")
+
+	t.Run("terminated on cleanup", func(t *testing.T) {
+		ft := &fakeT{}
+		c := RunForTest(ft, GenericContainerRequest{
+			ContainerRequest: ContainerRequest{Image: "nginx"},
+			ProviderName:     "mock",
+		})
+		if ft.failed {
+			t.Fatalf("unexpected failure: %v", ft.logs)
+		}
+		mock := c.(*ContainerMock)
+		if calls := strings.Join(mock.Calls(), ","); calls != "Start" {
+			t.Fatalf("unexpected calls %s", calls)
+		}
+
+		ft.cleanup()
+		if calls := strings.Join(mock.Calls(), ","); calls != "Start,Terminate" {
+			t.Fatalf("unexpected calls %s", calls)
+		}
+		if len(ft.logs) > 0 {
+			t.Fatalf("unexpected logs %v", ft.logs)
+		}
+	})
+
+	t.Run("logs on failure", func(t *testing.T) {
+		ft := &fakeT{}
+		RunForTest(ft, GenericContainerRequest{
+			ContainerRequest: ContainerRequest{Image: "broken"},
+			ProviderName:     "mock",
+		})
+		if !ft.fatal {
+			t.Fatal("expected the test to fail")
+		}
+		if !strings.Contains(ft.logs[0], "'broken'") || !strings.Contains(ft.logs[0], "exec format error") {
+			t.Fatalf("unexpected failure message '%s'", ft.logs[0])
+		}
+
+		ft.cleanup()
+		if len(ft.logs) != 2 || !strings.HasSuffix(ft.logs[1], "\nlistening\n") {
+			t.Fatalf("expected the logs of the container, got %v", ft.logs)
+		}
+		if containers := provider.Containers(); len(containers) != 2 {
+			t.Fatalf("expected 2 containers, got %d", len(containers))
+		}
+	})
+}
+
+func TestCleanupContainerArchivesLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	LogArchiveDir = dir
+	defer func() { LogArchiveDir = "" }()
+
+	ft := &fakeT{}
+	c := &ContainerMock{ID: "db", LogOutput: "FATAL: role \"app\" does not exist\n"}
+	c.Start(context.Background())
+	CleanupContainer(ft, c)
+	ft.failed = true
+	ft.cleanup()
+
+	archived, err := ioutil.ReadFile(filepath.Join(dir, "TestFake_subtest", "db.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(archived), "role \"app\" does not exist") {
+		t.Fatalf("expected the logs to be archived, got %q", archived)
+	}
+}
@@ -0,0 +1,67 @@
+package testcontainers
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// MaxConcurrentDaemonRequests bounds how many requests creating, starting, stopping, removing, inspecting
+// containers and pulling images the process sends at once to each daemon, so that many parallel tests queue
+// rather than overload it. It is read from the TC_MAX_CONCURRENT_REQUESTS env variable, 16 if unset.
+// Requests are not bounded if it is 0 or less.
+var MaxConcurrentDaemonRequests = maxConcurrentDaemonRequests()
+
+func maxConcurrentDaemonRequests() int {
+	if max, err := strconv.Atoi(os.Getenv("TC_MAX_CONCURRENT_REQUESTS")); err == nil {
+		return max
+	}
+	return 16
+}
+
+// the slots of the requests in flight, by daemon host, shared by all the providers of a daemon
+var (
+	requestSlots      = map[string]chan struct{}{}
+	requestSlotsMutex sync.Mutex
+)
+
+// throttle runs the request once there are less than MaxConcurrentDaemonRequests requests in flight to the
+// daemon, or fails if ctx is done before
+func (p *DockerProvider) throttle(ctx context.Context, request func() error) error {
+	slots := p.requestSlots()
+	if slots == nil {
+		return request()
+	}
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-slots }()
+
+	return request()
+}
+
+// requestSlots gets the slots of the requests to the daemon of the provider, nil if requests are not bounded
+func (p *DockerProvider) requestSlots() chan struct{} {
+	if MaxConcurrentDaemonRequests <= 0 {
+		return nil
+	}
+	host := p.dockerHost
+	if host == "" {
+		host = p.client.DaemonHost()
+	}
+
+	requestSlotsMutex.Lock()
+	defer requestSlotsMutex.Unlock()
+
+	slots, ok := requestSlots[host]
+	if !ok {
+		slots = make(chan struct{}, MaxConcurrentDaemonRequests)
+		requestSlots[host] = slots
+	}
+
+	return slots
+}
@@ -0,0 +1,94 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestDaemonRequestsAreBounded(t *testing.T) {
+	defer func(max int) { MaxConcurrentDaemonRequests = max }(MaxConcurrentDaemonRequests)
+	MaxConcurrentDaemonRequests = 2
+
+	var inFlight, maxInFlight int32
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/start") {
+			http.NotFound(w, r)
+			return
+		}
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	containers := make([]Container, 8)
+	for i := range containers {
+		containers[i] = &DockerContainer{ID: "bounded", provider: provider}
+	}
+	var wg sync.WaitGroup
+	for _, c := range containers {
+		wg.Add(1)
+		go func(c Container) {
+			defer wg.Done()
+			if err := c.Start(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if maxInFlight != 2 {
+		t.Fatalf("expected at most 2 requests in flight, got %d", maxInFlight)
+	}
+}
+
+func TestContainerCachesAreSafeForConcurrentUse(t *testing.T) {
+	provider := newFakeDaemonProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		inspect := types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{ID: "shared", State: &types.ContainerState{Running: true}},
+			NetworkSettings:   &types.NetworkSettings{},
+		}
+		inspect.NetworkSettings.Ports = nat.PortMap{"80/tcp": {{HostIP: "0.0.0.0", HostPort: "32768"}}}
+		json.NewEncoder(w).Encode(inspect)
+	})
+	c := &DockerContainer{ID: "shared", provider: provider}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.MappedPort(ctx, "80/tcp"); err != nil {
+				t.Error(err)
+			}
+			if err := c.RefreshPorts(ctx); err != nil {
+				t.Error(err)
+			}
+			c.ResetCache(ctx)
+			if _, err := c.State(ctx); err != nil {
+				t.Error(err)
+			}
+			if _, err := provider.daemonHost(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,132 @@
+package testcontainers
+
+import (
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+// sshTunnel forwards the ports mapped on the host of a remote daemon, which are not reachable from the
+// tests, to local ports. Each connection to a local port is forwarded by a new `ssh -W` command, so the
+// keys, agent and config of the user apply, like for ssh:// daemon hosts.
+type sshTunnel struct {
+	dial func(port string) (net.Conn, error) // connects to the port mapped on the daemon host
+
+	mutex    sync.Mutex
+	forwards map[string]net.Listener // the local listeners, keyed by the port mapped on the daemon host
+}
+
+// newSSHTunnel creates a tunnel to the host of the remote daemon, per the ssh.tunnel setting: "true" to
+// tunnel through the daemon host itself, or an ssh://[user@]host[:port] destination, e.g. a bastion
+// reaching a tcp:// daemon host. It is nil when the setting is empty or false, or the daemon is local.
+func newSSHTunnel(dockerHost, setting string) (*sshTunnel, error) {
+	enabled, err := strconv.ParseBool(setting)
+	if setting == "" || (err == nil && !enabled) {
+		return nil, nil
+	}
+
+	u, err := url.Parse(dockerHost)
+	if err != nil {
+		return nil, err
+	}
+	var target string
+	switch u.Scheme {
+	case "ssh":
+		// the ports are forwarded by the daemon host itself
+		target = "localhost"
+	case "http", "https", "tcp":
+		target = u.Hostname()
+	default:
+		Logger.Printf("The ssh tunnel is ignored for the local daemon %s", dockerHost)
+		return nil, nil
+	}
+
+	destination := setting
+	if enabled {
+		destination = "ssh://" + u.Hostname()
+		if u.Scheme == "ssh" {
+			destination = dockerHost
+		}
+	}
+	args, err := sshDestinationArgs(destination)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ssh tunnel")
+	}
+
+	return &sshTunnel{
+		dial: func(port string) (net.Conn, error) {
+			forward := []string{"-W", net.JoinHostPort(target, port)}
+			return newCommandConn("ssh", append(forward, args...)...)
+		},
+		forwards: map[string]net.Listener{},
+	}, nil
+}
+
+// forward gets the local port forwarded to the port mapped on the daemon host, listening on it if needed
+func (t *sshTunnel) forward(port string) (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if l, ok := t.forwards[port]; ok {
+		return portOf(l), nil
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", errors.Wrapf(err, "could not forward port %s", port)
+	}
+	t.forwards[port] = l
+	go t.accept(l, port)
+
+	return portOf(l), nil
+}
+
+// accept forwards the connections to the listener until it is closed
+func (t *sshTunnel) accept(l net.Listener, port string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			remote, err := t.dial(port)
+			if err != nil {
+				Logger.Printf("Could not forward a connection to port %s: %s", port, err)
+				return
+			}
+			defer remote.Close()
+
+			// either side closing ends the forwarding, the commands cannot be half-closed
+			done := make(chan struct{}, 2)
+			go func() { io.Copy(remote, conn); done <- struct{}{} }()
+			go func() { io.Copy(conn, remote); done <- struct{}{} }()
+			<-done
+		}()
+	}
+}
+
+// close stops forwarding the ports of the container, e.g. once it is terminated
+func (t *sshTunnel) close(ports nat.PortMap) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, bindings := range ports {
+		for _, binding := range bindings {
+			if l, ok := t.forwards[binding.HostPort]; ok {
+				l.Close()
+				delete(t.forwards, binding.HostPort)
+			}
+		}
+	}
+}
+
+func portOf(l net.Listener) string {
+	addr := l.Addr().String()
+	return addr[strings.LastIndex(addr, ":")+1:]
+}
@@ -0,0 +1,91 @@
+package testcontainers
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+)
+
+func TestNewSSHTunnel(t *testing.T) {
+	tests := []struct {
+		dockerHost string
+		setting    string
+		enabled    bool
+		wantErr    bool
+	}{
+		{dockerHost: "ssh://ci@docker-host", setting: ""},
+		{dockerHost: "ssh://ci@docker-host", setting: "false"},
+		{dockerHost: "ssh://ci@docker-host", setting: "true", enabled: true},
+		{dockerHost: "tcp://docker-host:2375", setting: "1", enabled: true},
+		{dockerHost: "tcp://docker-host:2375", setting: "ssh://ci@bastion:2222", enabled: true},
+		{dockerHost: "tcp://docker-host:2375", setting: "bastion", wantErr: true},
+		{dockerHost: "unix:///var/run/docker.sock", setting: "true"},
+	}
+
+	for _, tt := range tests {
+		tunnel, err := newSSHTunnel(tt.dockerHost, tt.setting)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s with %q: expected an error", tt.dockerHost, tt.setting)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s with %q: %s", tt.dockerHost, tt.setting, err)
+			continue
+		}
+		if (tunnel != nil) != tt.enabled {
+			t.Errorf("%s with %q: expected the tunnel to be enabled: %v", tt.dockerHost, tt.setting, tt.enabled)
+		}
+	}
+}
+
+func TestSSHTunnelForwardsPorts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("through the tunnel"))
+	}))
+	defer server.Close()
+	_, serverPort, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	dialed := make(chan string, 1)
+	tunnel := &sshTunnel{
+		dial: func(port string) (net.Conn, error) {
+			dialed <- port
+			return net.Dial("tcp", net.JoinHostPort("127.0.0.1", port))
+		},
+		forwards: map[string]net.Listener{},
+	}
+
+	local, err := tunnel.forward(serverPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local == serverPort {
+		t.Fatal("expected a local port other than the forwarded one")
+	}
+	if again, _ := tunnel.forward(serverPort); again != local {
+		t.Fatalf("expected the port to be forwarded once, got %s and %s", local, again)
+	}
+
+	resp, err := http.Get("http://localhost:" + local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "through the tunnel" {
+		t.Fatalf("expected the response of the server, got %q", body)
+	}
+	if port := <-dialed; port != serverPort {
+		t.Fatalf("expected the request to be forwarded to port %s, got %s", serverPort, port)
+	}
+
+	tunnel.close(nat.PortMap{"80/tcp": {{HostPort: serverPort}}})
+	if _, err := net.Dial("tcp", "127.0.0.1:"+local); err == nil {
+		t.Fatal("expected the local port to be closed with the container")
+	}
+}
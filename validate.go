@@ -0,0 +1,118 @@
+package testcontainers
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// ValidationError reports all the problems of an invalid container request at once
+type ValidationError struct {
+	Problems []string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid container request: %s", strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the request for missing, malformed and conflicting fields, which would otherwise fail
+// deep inside the Docker API or be silently ignored. The error is a ValidationError listing all the problems.
+func (c *ContainerRequest) Validate() error {
+	problems := []string{}
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case c.Image == "" && !c.ShouldBuildImage():
+		problem("no Image, nor a FromDockerfile context to build it from")
+	case c.Image != "" && c.ShouldBuildImage():
+		problem("both an Image and a FromDockerfile context, the image is built from the context and Image is ignored")
+	}
+
+	for _, port := range c.ExposedPorts {
+		if _, err := nat.ParsePortSpec(port); err != nil {
+			problem("exposed port %q: %s, expected e.g. \"80/tcp\" or \"8080:80/tcp\"", port, err)
+		}
+	}
+
+	for hostPath, target := range c.BindMounts {
+		if !filepath.IsAbs(hostPath) {
+			problem("bind mount of relative host path %q, the daemon does not know the working directory: use filepath.Abs", hostPath)
+		}
+		if !path.IsAbs(target) {
+			problem("bind mount of %q to relative path %q in the container", hostPath, target)
+		}
+	}
+	for volume, target := range c.VolumeMounts {
+		if volume == "" {
+			problem("volume mount to %q without a volume name", target)
+		}
+		if !path.IsAbs(target) {
+			problem("volume mount of %q to relative path %q in the container", volume, target)
+		}
+	}
+
+	for _, f := range c.Files {
+		if f.HostFilePath == "" {
+			problem("file to copy to %q without a host path", f.ContainerFilePath)
+		}
+		if !path.IsAbs(f.ContainerFilePath) {
+			problem("file %q to copy to relative path %q in the container", f.HostFilePath, f.ContainerFilePath)
+		}
+	}
+
+	if c.Cmd != "" && len(c.Command) > 0 {
+		problem("both Cmd and Command, Cmd is ignored")
+	}
+	if c.NetworkMode != "" && (len(c.Networks) > 0 || c.SessionNetwork) {
+		problem("both a NetworkMode and Networks, NetworkMode is ignored")
+	}
+	networks := map[string]bool{}
+	for _, n := range c.Networks {
+		networks[n] = true
+	}
+	for n := range c.NetworkAliases {
+		if !networks[n] {
+			problem("aliases on network %q, which is not in Networks", n)
+		}
+	}
+	for n := range c.NetworkIPs {
+		if !networks[n] {
+			problem("static IP on network %q, which is not in Networks", n)
+		}
+	}
+
+	for _, port := range c.HostAccessPorts {
+		if port < 1 || port > 65535 {
+			problem("invalid host access port %d", port)
+		}
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// Validate checks the container request, see ContainerRequest.Validate, and that it can be reused if Reuse is set
+func (r *GenericContainerRequest) Validate() error {
+	problems := []string{}
+	if err := r.ContainerRequest.Validate(); err != nil {
+		problems = err.(ValidationError).Problems
+	}
+
+	if r.Reuse && r.ShouldBuildImage() {
+		problems = append(problems, "Reuse of a container built from a Dockerfile, which cannot be reused")
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Problems: problems}
+	}
+
+	return nil
+}
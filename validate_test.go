@@ -0,0 +1,57 @@
+package testcontainers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:          "postgres",
+			FromDockerfile: FromDockerfile{Context: "./testresources"},
+			ExposedPorts:   []string{"5432/tcp", "port"},
+			BindMounts:     map[string]string{"testresources/init": "/docker-entrypoint-initdb.d"},
+			Networks:       []string{"backend"},
+			NetworkAliases: map[string][]string{"frontend": {"db"}},
+			Files:          []ContainerFile{{HostFilePath: "testresources/init.sql", ContainerFilePath: "init.sql"}},
+		},
+		Reuse: true,
+	}
+
+	err := req.Validate()
+	validation, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	for _, expected := range []string{
+		"both an Image and a FromDockerfile context",
+		`exposed port "port"`,
+		`relative host path "testresources/init"`,
+		`aliases on network "frontend"`,
+		`relative path "init.sql" in the container`,
+		"Reuse of a container built from a Dockerfile",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected the problem %q to be reported, got %q", expected, err)
+		}
+	}
+	if len(validation.Problems) != 6 {
+		t.Fatalf("expected 6 problems, got %v", validation.Problems)
+	}
+
+	valid := ContainerRequest{Image: "postgres", ExposedPorts: []string{"5432/tcp"}, BindMounts: map[string]string{"/tmp/init": "/docker-entrypoint-initdb.d"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenericContainerValidatesTheRequest(t *testing.T) {
+	_, err := GenericContainer(context.Background(), GenericContainerRequest{
+		ContainerRequest: ContainerRequest{ExposedPorts: []string{"80/tcp"}},
+	})
+	if _, ok := err.(ValidationError); !ok {
+		t.Fatalf("expected the request to be rejected before reaching the daemon, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package testcontainers
+
+import (
+	"context"
+)
+
+// VolumeProvider allows the creation of named volumes on an arbitrary system
+type VolumeProvider interface {
+	CreateVolume(context.Context, VolumeRequest) (Volume, error) // create a named volume
+}
+
+// Volume allows getting info about and controlling a single named volume
+type Volume interface {
+	GetName() string              // get the name of the volume, as used in ContainerRequest.VolumeMounts
+	Remove(context.Context) error // removes the volume
+}
+
+// VolumeRequest represents the parameters used to get a named volume
+type VolumeRequest struct {
+	Name       string            // the name of the volume, generated by the daemon if empty
+	Driver     string            // the volume driver, "local" if empty
+	DriverOpts map[string]string // options passed to the volume driver
+	Labels     map[string]string
+
+	SkipReaper bool // indicates whether we skip setting up a reaper for this
+}
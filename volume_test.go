@@ -0,0 +1,37 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContainerWithNamedVolume(t *testing.T) {
+	ctx := context.Background()
+	vol, err := GenericVolume(ctx, GenericVolumeRequest{
+		VolumeRequest: VolumeRequest{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol.GetName() == "" {
+		t.Fatal("expected the daemon to generate a volume name")
+	}
+
+	nginx, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:        "nginx",
+			VolumeMounts: map[string]string{vol.GetName(): "/data"},
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := nginx.Terminate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := vol.Remove(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
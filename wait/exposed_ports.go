@@ -0,0 +1,104 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// Implement interface
+var _ Strategy = (*ExposedPortsStrategy)(nil)
+
+// portsTarget is a target which provides all the ports it exposes, as the containers do
+type portsTarget interface {
+	Ports(context.Context) (nat.PortMap, error)
+}
+
+// ExposedPortsStrategy will wait until all the ports exposed by the container are mapped,
+// and the TCP ones accept connections
+type ExposedPortsStrategy struct {
+	// all Strategies should have a startupTimeout to avoid waiting infinitely
+	startupTimeout time.Duration
+
+	// additional properties
+	PollInterval time.Duration
+}
+
+// NewExposedPortsStrategy constructs a strategy waiting for all the exposed ports
+func NewExposedPortsStrategy() *ExposedPortsStrategy {
+	return &ExposedPortsStrategy{
+		startupTimeout: defaultStartupTimeout(),
+		PollInterval:   100 * time.Millisecond,
+	}
+}
+
+// ForExposedPorts waits for all the ports exposed by the container, e.g. both 3306 and 33060 of MySQL,
+// rather than for one of them with ForListeningPort
+func ForExposedPorts() *ExposedPortsStrategy {
+	return NewExposedPortsStrategy()
+}
+
+// WithStartupTimeout can be used to change the default startup timeout
+func (ep *ExposedPortsStrategy) WithStartupTimeout(startupTimeout time.Duration) *ExposedPortsStrategy {
+	ep.startupTimeout = startupTimeout
+	return ep
+}
+
+// WithPollInterval can be used to override the default polling interval of 100 milliseconds
+func (ep *ExposedPortsStrategy) WithPollInterval(pollInterval time.Duration) *ExposedPortsStrategy {
+	ep.PollInterval = pollInterval
+	return ep
+}
+
+// WaitUntilReady implements Strategy.WaitUntilReady
+func (ep *ExposedPortsStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	// limit context to startupTimeout
+	ctx, cancelContext := context.WithTimeout(ctx, ep.startupTimeout)
+	defer cancelContext()
+
+	t, ok := target.(portsTarget)
+	if !ok {
+		return errors.New("the exposed ports cannot be waited for, the target does not provide its ports")
+	}
+
+	var ports nat.PortMap
+	for {
+		var err error
+		ports, err = t.Ports(ctx)
+		if err != nil {
+			return err
+		}
+		if allMapped(ports) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(ep.PollInterval):
+		}
+	}
+
+	for port := range ports {
+		if port.Proto() != "tcp" {
+			continue
+		}
+		if err := NewHostPortStrategy(port).WaitUntilReady(ctx, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// allMapped tells whether all the ports are bound to a port of the host
+func allMapped(ports nat.PortMap) bool {
+	for _, bindings := range ports {
+		if len(bindings) == 0 || bindings[0].HostPort == "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,83 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// portsTargetMock maps its ports once it has been asked for them a number of times
+type portsTargetMock struct {
+	mutex     sync.Mutex
+	ports     nat.PortMap
+	unmapped  int
+	inspected int
+}
+
+func (t *portsTargetMock) Host(ctx context.Context) (string, error) {
+	return "127.0.0.1", nil
+}
+
+func (t *portsTargetMock) MappedPort(ctx context.Context, port nat.Port) (nat.Port, error) {
+	bindings := t.ports[port]
+	if len(bindings) == 0 {
+		return "", errors.New("port not found")
+	}
+	return nat.NewPort(port.Proto(), bindings[0].HostPort)
+}
+
+func (t *portsTargetMock) Logs(ctx context.Context) (io.ReadCloser, error) {
+	return nil, errors.New("no logs")
+}
+
+func (t *portsTargetMock) Ports(ctx context.Context) (nat.PortMap, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.inspected++
+	if t.inspected <= t.unmapped {
+		unmapped := nat.PortMap{}
+		for port := range t.ports {
+			unmapped[port] = nil
+		}
+		return unmapped, nil
+	}
+	return t.ports, nil
+}
+
+func TestForExposedPorts(t *testing.T) {
+	listeners := make([]net.Listener, 2)
+	ports := nat.PortMap{"5432/udp": {{HostPort: "1"}}}
+	for i := range listeners {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		listeners[i] = l
+		port := nat.Port(strconv.Itoa(3306+i) + "/tcp")
+		ports[port] = []nat.PortBinding{{HostPort: strconv.Itoa(l.Addr().(*net.TCPAddr).Port)}}
+	}
+	target := &portsTargetMock{ports: ports, unmapped: 2}
+
+	err := ForExposedPorts().WithPollInterval(time.Millisecond).WithStartupTimeout(5*time.Second).
+		WaitUntilReady(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.inspected != 3 {
+		t.Errorf("expected the ports to be waited for until they are mapped, inspected %d times", target.inspected)
+	}
+
+	listeners[1].Close()
+	err = ForExposedPorts().WithStartupTimeout(200*time.Millisecond).WaitUntilReady(context.Background(), target)
+	if err == nil {
+		t.Error("expected a port which does not accept connections not to be ready")
+	}
+}
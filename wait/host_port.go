@@ -68,7 +68,6 @@ func (hp *HostPortStrategy) WaitUntilReady(ctx context.Context, target StrategyT
 	address := net.JoinHostPort(ipAddress, portString)
 	for {
 		conn, err := dialer.DialContext(ctx, proto, address)
-		defer conn.Close()
 		if err != nil {
 			if v, ok := err.(*net.OpError); ok {
 				if v2, ok := (v.Err).(*os.SyscallError); ok {
@@ -80,6 +79,7 @@ func (hp *HostPortStrategy) WaitUntilReady(ctx context.Context, target StrategyT
 			}
 			return err
 		}
+		conn.Close()
 		break
 	}
 
@@ -17,6 +17,7 @@ type LogStrategy struct {
 
 	// additional properties
 	Log          string
+	Occurrence   int // how many times the log entry must show up, once if 0
 	PollInterval time.Duration
 }
 
@@ -46,6 +47,13 @@ func (ws *LogStrategy) WithPollInterval(pollInterval time.Duration) *LogStrategy
 	return ws
 }
 
+// WithOccurrence waits until the log entry shows up the given number of times, e.g. for servers which
+// log that they are ready once while they are initialized, then once they are started for good
+func (ws *LogStrategy) WithOccurrence(occurrence int) *LogStrategy {
+	ws.Occurrence = occurrence
+	return ws
+}
+
 // ForLog is the default construction for the fluid interface.
 //
 // For Example:
@@ -76,7 +84,7 @@ LOOP:
 			}
 			b, err := ioutil.ReadAll(reader)
 			logs := string(b)
-			if strings.Contains(logs, ws.Log) {
+			if strings.Count(logs, ws.Log) >= ws.occurrence() {
 				break LOOP
 			} else {
 				time.Sleep(ws.PollInterval)
@@ -87,3 +95,10 @@ LOOP:
 
 	return nil
 }
+
+func (ws *LogStrategy) occurrence() int {
+	if ws.Occurrence < 1 {
+		return 1
+	}
+	return ws.Occurrence
+}
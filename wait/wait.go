@@ -18,6 +18,10 @@ type StrategyTarget interface {
 	Logs(context.Context) (io.ReadCloser, error)
 }
 
+// StartupTimeoutMultiplier scales the default startup timeout of the strategies. testcontainers sets it
+// to the TimeoutMultiplier of its runtime environment, as shared CI runners are slower than workstations.
+var StartupTimeoutMultiplier = func() int { return 1 }
+
 func defaultStartupTimeout() time.Duration {
-	return 60 * time.Second
+	return 60 * time.Second * time.Duration(StartupTimeoutMultiplier())
 }